@@ -1,12 +1,26 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
 
+	"etf-scraper/internal/analytics"
+	"etf-scraper/internal/benchmark"
 	"etf-scraper/internal/config"
 	"etf-scraper/internal/database"
+	"etf-scraper/internal/diff"
+	"etf-scraper/internal/dump"
+	"etf-scraper/internal/events"
+	"etf-scraper/internal/export"
+	"etf-scraper/internal/fxrates"
+	"etf-scraper/internal/importer"
+	"etf-scraper/internal/models"
 	"etf-scraper/internal/scraper"
 	"etf-scraper/internal/server"
 )
@@ -24,6 +38,30 @@ func main() {
 		case "scrape":
 			runScraper(cfg)
 			return
+		case "export":
+			runExport(cfg, os.Args[2:])
+			return
+		case "dump":
+			runDump(cfg, os.Args[2:])
+			return
+		case "diff":
+			runDiff(cfg, os.Args[2:])
+			return
+		case "events":
+			runEvents(cfg, os.Args[2:])
+			return
+		case "import":
+			runImport(cfg, os.Args[2:])
+			return
+		case "benchmark":
+			runBenchmark(cfg, os.Args[2:])
+			return
+		case "fx":
+			runFX(cfg, os.Args[2:])
+			return
+		case "openapi":
+			runOpenAPI(cfg, os.Args[2:])
+			return
 		case "help":
 			printHelp()
 			return
@@ -51,6 +89,8 @@ func runServer(cfg *config.Config) {
 	// Создаем репозиторий
 	repo := database.NewRepository(db)
 
+	backfillMarkers(cfg, repo)
+
 	// Запускаем сервер
 	srv := server.NewServer(cfg, db, repo)
 	if err := srv.Start(); err != nil {
@@ -70,12 +110,15 @@ func runScraper(cfg *config.Config) {
 
 	// Создаем репозиторий
 	repo := database.NewRepository(db)
+	backfillMarkers(cfg, repo)
 
 	// Создаем скрейпер
 	s := scraper.NewScraper(cfg, repo)
 
-	// Выполняем скрейпинг
-	if err := s.Run(); err != nil {
+	// Выполняем скрейпинг и дожидаемся рассылки вебхуков перед выходом
+	err = s.Run()
+	s.Wait()
+	if err != nil {
 		log.Fatalf("Ошибка выполнения скрейпинга: %v", err)
 	}
 
@@ -92,8 +135,407 @@ func runScraper(cfg *config.Config) {
 	log.Println("\n✓ Все данные сохранены в", cfg.DBPath)
 }
 
+// backfillMarkers один раз при запуске заполняет маркеры записей, сохраненных
+// до появления колонок ter_trend и info_flags. С нечитаемой таблицей маркеров
+// историю не трогаем, чтобы не сохранить неверную классификацию.
+func backfillMarkers(cfg *config.Config, repo *database.Repository) {
+	markers, err := scraper.LoadMarkerMapping(cfg.MarkersPath)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: %v, маркеры истории не заполнены", err)
+		return
+	}
+	scraper.BackfillMarkers(repo, markers)
+}
+
+func runExport(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", export.FormatCSV, "формат: csv или xlsx")
+	output := fs.String("o", "", "файл для записи (по умолчанию stdout)")
+	ticker := fs.String("ticker", "", "выгрузить историю тикера вместо текущего списка")
+	asOf := fs.String("as-of", "", "сеанс: ID запуска, date_scraped или дата (по умолчанию последний)")
+	columns := fs.String("columns", "", "колонки через запятую (по умолчанию все)")
+	lang := fs.String("lang", "ru", "язык заголовков: ru или en")
+	sep := fs.String("sep", "comma", "разделитель CSV: comma или semicolon")
+	bom := fs.Bool("bom", true, "добавить UTF-8 BOM в CSV")
+	assetClass := fs.String("asset-class", "", "фильтр по классу активов")
+	terTrend := fs.String("ter-trend", "", "фильтр по изменению TER")
+	infoFlag := fs.String("info-flag", "", "фильтр по отметке фонда")
+	sortBy := fs.String("sort", "nav_million_rub", "поле сортировки")
+	order := fs.String("order", "DESC", "порядок сортировки: ASC или DESC")
+	fs.Parse(args)
+
+	opts, err := export.NewOptions(*columns, *lang, *sep, *bom)
+	if err != nil {
+		log.Fatalf("Ошибка параметров выгрузки: %v", err)
+	}
+
+	db, err := database.NewDatabase(cfg.DBPath)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer db.Close()
+
+	repo := database.NewRepository(db)
+
+	var data []models.ETFData
+	if *ticker != "" {
+		data, err = repo.GetTickerHistory(*ticker)
+	} else {
+		var session string
+		session, err = repo.ResolveAsOf(*asOf)
+		if err == nil {
+			data, err = repo.ListETFs(session, database.ETFFilter{
+				AssetClass: *assetClass,
+				TERTrend:   *terTrend,
+				InfoFlag:   *infoFlag,
+				SortBy:     *sortBy,
+				Order:      *order,
+			})
+		}
+	}
+	if err != nil {
+		log.Fatalf("Ошибка чтения данных: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Ошибка создания файла: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := export.Write(w, *format, data, opts); err != nil {
+		log.Fatalf("Ошибка выгрузки: %v", err)
+	}
+
+	if *output != "" {
+		log.Printf("✓ Выгружено записей: %d в %s", len(data), *output)
+	}
+}
+
+func runDump(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	dir := fs.String("dir", cfg.DumpDir, "каталог выгрузки")
+	format := fs.String("format", "", "форматы через запятую: parquet, ndjson (по умолчанию оба)")
+	incremental := fs.Bool("incremental", false, "выгрузить только сеансы, которых еще нет в манифесте в выбранных форматах")
+	fs.Parse(args)
+
+	formats, err := dump.ParseFormats(*format)
+	if err != nil {
+		log.Fatalf("Ошибка параметров выгрузки: %v", err)
+	}
+
+	db, err := database.NewDatabase(cfg.DBPath)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer db.Close()
+
+	repo := database.NewRepository(db)
+
+	manifest, err := dump.Run(repo, dump.Options{
+		Dir:         *dir,
+		Formats:     formats,
+		Incremental: *incremental,
+	})
+	if err != nil {
+		log.Fatalf("Ошибка выгрузки: %v", err)
+	}
+
+	log.Printf("✓ Выгрузка завершена: файлов %d, строк всего %d, последний сеанс %s",
+		len(manifest.Files), manifest.TotalRows, manifest.LastDateScraped)
+}
+
+func runDiff(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	from := fs.String("from", "", "исходный сеанс: ID запуска или дата (по умолчанию предпоследний)")
+	to := fs.String("to", "", "конечный сеанс: ID запуска или дата (по умолчанию последний)")
+	asJSON := fs.Bool("json", false, "вывести результат в JSON")
+	fs.Parse(args)
+
+	db, err := database.NewDatabase(cfg.DBPath)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer db.Close()
+
+	repo := database.NewRepository(db)
+
+	d, err := diff.Run(repo, *from, *to)
+	if err != nil {
+		log.Fatalf("Ошибка сравнения: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(d)
+	} else {
+		err = diff.WriteText(os.Stdout, d)
+	}
+	if err != nil {
+		log.Fatalf("Ошибка вывода: %v", err)
+	}
+}
+
+func runEvents(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("events", flag.ExitOnError)
+	backfill := fs.Bool("backfill", false, "построить события для всех сеансов в БД")
+	ticker := fs.String("ticker", "", "фильтр по тикеру")
+	company := fs.String("company", "", "фильтр по управляющей компании")
+	limit := fs.Int("limit", 20, "количество событий")
+	fs.Parse(args)
+
+	db, err := database.NewDatabase(cfg.DBPath)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer db.Close()
+
+	repo := database.NewRepository(db)
+
+	if *backfill {
+		n, err := events.Backfill(repo, cfg.NAVThresholds)
+		if err != nil {
+			log.Fatalf("Ошибка построения событий: %v", err)
+		}
+		log.Printf("✓ Новых событий: %d", n)
+	}
+
+	list, err := analytics.FilterEvents(repo, database.EventFilter{
+		Ticker: *ticker,
+		Limit:  *limit,
+	}, *company)
+	if err != nil {
+		log.Fatalf("Ошибка чтения событий: %v", err)
+	}
+
+	for _, e := range list {
+		fmt.Printf("%s  %-14s %s\n", e.DateScraped, e.Kind, e.Title)
+	}
+}
+
+func runImport(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	date := fs.String("date", "", "дата снимка (обязательно), например 2024-03-15 или 15.03.2024")
+	mapping := fs.String("map", "", "сопоставление заголовков: \"Заголовок=колонка,...\"")
+	dryRun := fs.Bool("dry-run", false, "только проверить файл, ничего не сохраняя")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *date == "" {
+		fmt.Println("Использование: etfscraper import -date ДАТА [-map ...] [-dry-run] файл.csv|файл.xlsx")
+		os.Exit(1)
+	}
+
+	columns, err := parseMapping(*mapping)
+	if err != nil {
+		log.Fatalf("Ошибка параметров импорта: %v", err)
+	}
+
+	markers, err := scraper.LoadMarkerMapping(cfg.MarkersPath)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: %v, используются маркеры по умолчанию", err)
+	}
+
+	db, err := database.NewDatabase(cfg.DBPath)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer db.Close()
+
+	repo := database.NewRepository(db)
+
+	result, err := importer.Run(repo, importer.Options{
+		Path:          fs.Arg(0),
+		Date:          *date,
+		Mapping:       columns,
+		Markers:       markers,
+		NAVThresholds: cfg.NAVThresholds,
+		DryRun:        *dryRun,
+	})
+	if err != nil {
+		log.Fatalf("Ошибка импорта: %v", err)
+	}
+
+	for _, pe := range result.Errors {
+		if pe.Kind == models.ParseErrorRow {
+			log.Printf("Строка %d отклонена: %s (%s)", pe.Row, pe.Reason, truncate(pe.RawText, 80))
+		} else {
+			log.Printf("Строка %d, колонка %s: %s", pe.Row, pe.Column, pe.Reason)
+		}
+	}
+
+	if *dryRun {
+		log.Printf("✓ Проверка завершена: строк %d, будет загружено %d, ошибок %d",
+			result.RowsTotal, result.RowsSaved, len(result.Errors))
+		return
+	}
+
+	log.Printf("✓ Импорт завершен: сеанс %s, загружено %d из %d строк, ошибок %d (запуск #%d)",
+		result.DateScraped, result.RowsSaved, result.RowsTotal, len(result.Errors), result.RunID)
+}
+
+func runBenchmark(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("benchmark", flag.ExitOnError)
+	code := fs.String("code", "", "код бенчмарка, например MCFTR (без -code выводится список)")
+	name := fs.String("name", "", "название индекса; создает бенчмарк или меняет название")
+	levels := fs.String("levels", "", "значения индекса: файл CSV/JSON или URL JSON API")
+	link := fs.String("link", "", "тикеры фондов через запятую для привязки к бенчмарку")
+	fs.Parse(args)
+
+	db, err := database.NewDatabase(cfg.DBPath)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer db.Close()
+
+	repo := database.NewRepository(db)
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	if c := benchmark.NormalizeCode(*code); c != "" {
+		if *name != "" {
+			if err := repo.SaveBenchmark(models.Benchmark{Code: c, Name: strings.TrimSpace(*name), CreatedAt: now}); err != nil {
+				log.Fatalf("Ошибка сохранения бенчмарка: %v", err)
+			}
+			log.Printf("✓ Бенчмарк %s сохранен", c)
+		}
+
+		b, err := repo.GetBenchmark(c)
+		if err != nil {
+			log.Fatalf("Ошибка чтения бенчмарка: %v", err)
+		}
+		if b == nil {
+			log.Fatalf("Бенчмарк %s не найден; создайте его с -name", c)
+		}
+
+		if *levels != "" {
+			list, err := benchmark.Load(*levels)
+			if err != nil {
+				log.Fatalf("Ошибка загрузки значений индекса: %v", err)
+			}
+			if err := repo.SaveBenchmarkLevels(c, list); err != nil {
+				log.Fatalf("Ошибка сохранения значений индекса: %v", err)
+			}
+			log.Printf("✓ Загружено значений: %d (%s - %s)", len(list), list[0].Date, list[len(list)-1].Date)
+		}
+
+		for _, ticker := range strings.Split(*link, ",") {
+			ticker = strings.ToUpper(strings.TrimSpace(ticker))
+			if ticker == "" {
+				continue
+			}
+			if err := repo.SetFundBenchmark(models.FundBenchmark{Ticker: ticker, Code: c, CreatedAt: now}); err != nil {
+				log.Fatalf("Ошибка привязки фонда: %v", err)
+			}
+			log.Printf("✓ %s привязан к %s", ticker, c)
+		}
+	}
+
+	list, err := benchmark.List(repo)
+	if err != nil {
+		log.Fatalf("Ошибка чтения бенчмарков: %v", err)
+	}
+	for _, b := range list {
+		fmt.Printf("%-10s %-40s значений: %-5d %s - %s  фонды: %s\n",
+			b.Code, truncate(b.Name, 40), b.Levels, b.FirstDate, b.LastDate, strings.Join(b.Funds, ", "))
+	}
+}
+
+func runFX(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("fx", flag.ExitOnError)
+	rates := fs.String("rates", "", "курсы валют: XML Банка России или CSV (дата, валюта, курс[, номинал]), файл или URL")
+	fs.Parse(args)
+
+	db, err := database.NewDatabase(cfg.DBPath)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer db.Close()
+
+	repo := database.NewRepository(db)
+
+	if *rates != "" {
+		list, err := fxrates.Load(*rates)
+		if err != nil {
+			log.Fatalf("Ошибка загрузки курсов: %v", err)
+		}
+		if err := repo.SaveFXRates(list); err != nil {
+			log.Fatalf("Ошибка сохранения курсов: %v", err)
+		}
+		log.Printf("✓ Загружено курсов: %d", len(list))
+	}
+
+	list, err := repo.GetFXRates("", "", "")
+	if err != nil {
+		log.Fatalf("Ошибка чтения курсов: %v", err)
+	}
+	for i := 0; i < len(list); {
+		j := i
+		for j < len(list) && list[j].Currency == list[i].Currency {
+			j++
+		}
+		last := list[j-1]
+		fmt.Printf("%-4s курсов: %-5d %s - %s  последний: %.4f ₽\n",
+			list[i].Currency, j-i, list[i].Date, last.Date, last.Rate)
+		i = j
+	}
+}
+
+func runOpenAPI(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("openapi", flag.ExitOnError)
+	check := fs.Bool("check", false, "сверить маршруты сервера с документом OpenAPI")
+	fs.Parse(args)
+
+	if *check {
+		problems := server.CheckOpenAPI(cfg)
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+		fmt.Println("✓ Документ OpenAPI описывает все маршруты")
+		return
+	}
+
+	data, err := server.MarshalOpenAPI(cfg)
+	if err != nil {
+		log.Fatalf("Ошибка сборки документа OpenAPI: %v", err)
+	}
+	fmt.Println(string(data))
+}
+
+// parseMapping разбирает строку вида "Заголовок=колонка,..."
+func parseMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if value == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		header, column, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(header) == "" || strings.TrimSpace(column) == "" {
+			return nil, fmt.Errorf("неверное сопоставление: %q", pair)
+		}
+		mapping[strings.TrimSpace(header)] = strings.TrimSpace(column)
+	}
+	return mapping, nil
+}
+
+// truncate обрезает строку до max символов
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
+
 func printHelp() {
-	fmt.Println(`
+	fmt.Print(`
 ETF Scraper - инструмент для сбора данных о ETF фондах
 
 Использование:
@@ -102,6 +544,14 @@ ETF Scraper - инструмент для сбора данных о ETF фон
 Команды:
   scrape    Запустить скрейпинг данных (по умолчанию)
   serve     Запустить API сервер с веб-интерфейсом
+  export    Выгрузить данные в CSV/XLSX (etfscraper export -h)
+  dump      Выгрузить всю историю в Parquet/NDJSON (etfscraper dump -h)
+  diff      Сравнить два сеанса скрейпинга (etfscraper diff -h)
+  events    Показать события фондов (etfscraper events -h)
+  import    Загрузить исторический снимок из CSV/XLSX (etfscraper import -h)
+  benchmark Бенчмарки: значения индексов и привязка фондов (etfscraper benchmark -h)
+  fx        Загрузить курсы валют и показать охват (etfscraper fx -h)
+  openapi   Вывести документ OpenAPI; -check сверяет его с маршрутами сервера
   help      Показать эту справку
 
 Переменные окружения:
@@ -109,12 +559,28 @@ ETF Scraper - инструмент для сбора данных о ETF фон
   SERVER_PORT   Порт сервера (по умолчанию: 8080)
   SCRAPER_URL   URL для скрейпинга (по умолчанию: https://assetallocation.ru/etf/)
   VERBOSE       Подробный вывод (true/false)
+  HTTP_CACHE    Условные запросы по ETag/Last-Modified (по умолчанию: true)
+  MARKERS_PATH  JSON с сопоставлением маркеров TER и отметок фондов
   STATIC_DIR    Путь к статическим файлам (по умолчанию: ./static)
+  DUMP_DIR      Каталог выгрузок dump (по умолчанию: ./dumps)
+  NAV_THRESHOLDS  Пороги СЧА для событий, млн ₽ (по умолчанию: 1000,10000,100000)
+  SMTP_ADDR     SMTP сервер для оповещений по email (host:port)
+  SMTP_FROM     Адрес отправителя оповещений
+  SMTP_USERNAME, SMTP_PASSWORD  Учетные данные SMTP
+  ALERT_WEBHOOK_SECRET  Секрет подписи оповещений, отправляемых на webhook
+  RISK_FREE_RATE  Безрисковая ставка для коэффициента Шарпа, % годовых (по умолчанию: 0)
 
 Примеры:
   etfscraper scrape              # Запустить скрейпинг
   etfscraper serve               # Запустить веб-сервер
   DB_PATH=data.db etfscraper     # Использовать другую БД
   SERVER_PORT=3000 etfscraper serve  # Запустить на порту 3000
+  etfscraper export -format xlsx -o etfs.xlsx    # Выгрузить текущий список
+  etfscraper export -ticker TMOS -sep semicolon  # История тикера для Excel
+  etfscraper diff -from 2024-03-01              # Что изменилось с 1 марта
+  etfscraper import -date 2024-03-15 snapshot.csv  # Загрузить старый снимок
+  etfscraper benchmark -code MCFTR -name "Индекс МосБиржи полной доходности" -levels mcftr.csv
+  etfscraper openapi > openapi.json  # Сохранить описание API
+  etfscraper fx -rates "https://www.cbr.ru/scripts/XML_daily.asp?date_req=19.10.2026"
 `)
 }
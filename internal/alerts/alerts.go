@@ -0,0 +1,224 @@
+// Package alerts проверяет правила оповещений для списков наблюдения после
+// каждого успешного скрейпинга и доставляет сработавшие оповещения через notifier'ы
+package alerts
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"etf-scraper/internal/config"
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+// Kinds перечисляет поддерживаемые виды правил
+var Kinds = []string{models.AlertTERAbove, models.AlertNAVDropPct, models.AlertStatusChanged}
+
+// NeedsThreshold сообщает, требует ли вид правила порог
+func NeedsThreshold(kind string) bool {
+	return kind == models.AlertTERAbove || kind == models.AlertNAVDropPct
+}
+
+// ValidKind проверяет вид правила
+func ValidKind(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Engine проверяет правила и рассылает оповещения
+type Engine struct {
+	repo      *database.Repository
+	notifiers map[string]Notifier
+}
+
+// NewEngine создает движок со стандартными notifier'ами: log, webhook и email
+// (email доступен, только если задан SMTP_ADDR)
+func NewEngine(repo *database.Repository, cfg *config.Config) *Engine {
+	e := &Engine{repo: repo, notifiers: make(map[string]Notifier)}
+	e.Register(LogNotifier{})
+	e.Register(NewWebhookNotifier(cfg.AlertSecret))
+	if cfg.SMTPAddr != "" {
+		e.Register(NewSMTPNotifier(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword))
+	}
+	return e
+}
+
+// Register добавляет или заменяет notifier
+func (e *Engine) Register(n Notifier) {
+	e.notifiers[n.Name()] = n
+}
+
+// Notifier возвращает notifier по имени
+func (e *Engine) Notifier(name string) (Notifier, bool) {
+	n, ok := e.notifiers[name]
+	return n, ok
+}
+
+// NotifierNames возвращает имена зарегистрированных notifier'ов
+func (e *Engine) NotifierNames() []string {
+	names := make([]string, 0, len(e.notifiers))
+	for name := range e.notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Evaluate проверяет правила всех списков для сеанса dateScraped относительно
+// предыдущего сеанса, сохраняет новые оповещения и доставляет их
+func (e *Engine) Evaluate(dateScraped string) ([]models.Alert, error) {
+	watchlists, err := e.repo.GetWatchlists()
+	if err != nil {
+		return nil, err
+	}
+	if len(watchlists) == 0 {
+		return nil, nil
+	}
+
+	curData, err := e.repo.GetETFsBySession(dateScraped)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := e.repo.GetLatestSession(dateScraped)
+	if err != nil {
+		return nil, err
+	}
+	var prevData []models.ETFData
+	if prev != "" {
+		if prevData, err = e.repo.GetETFsBySession(prev); err != nil {
+			return nil, err
+		}
+	}
+
+	before, after := byTicker(prevData), byTicker(curData)
+
+	// Оповещения группируются по правилу: одно уведомление на правило за запуск
+	var fired []models.Alert
+	for _, wl := range watchlists {
+		for _, rule := range wl.Rules {
+			var ruleAlerts []models.Alert
+			for _, ticker := range wl.Tickers {
+				cur, ok := after[ticker]
+				if !ok {
+					continue
+				}
+				old, hasOld := before[ticker]
+				message, ok := Check(rule, old, hasOld, cur)
+				if !ok {
+					continue
+				}
+
+				alert := models.Alert{
+					RuleID:        rule.ID,
+					WatchlistID:   wl.ID,
+					WatchlistName: wl.Name,
+					Ticker:        ticker,
+					DateScraped:   dateScraped,
+					Kind:          rule.Kind,
+					Message:       message,
+					CreatedAt:     time.Now().Format("2006-01-02 15:04:05"),
+				}
+				id, created, err := e.repo.SaveAlert(alert)
+				if err != nil {
+					return fired, err
+				}
+				if !created {
+					continue // уже отправлено для этого сеанса
+				}
+				alert.ID = id
+				ruleAlerts = append(ruleAlerts, alert)
+			}
+
+			if len(ruleAlerts) > 0 {
+				e.deliver(rule, ruleAlerts)
+				fired = append(fired, ruleAlerts...)
+			}
+		}
+	}
+
+	return fired, nil
+}
+
+// EvaluateLogged вызывает Evaluate и пишет результат в лог; ошибка не прерывает запуск
+func (e *Engine) EvaluateLogged(dateScraped string) {
+	fired, err := e.Evaluate(dateScraped)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Ошибка проверки оповещений: %v", err)
+		return
+	}
+	if len(fired) > 0 {
+		log.Printf("Сработало оповещений: %d", len(fired))
+	}
+}
+
+// deliver отправляет оповещения правила и отмечает результат доставки
+func (e *Engine) deliver(rule models.AlertRule, alerts []models.Alert) {
+	var deliveryErr error
+	if n, ok := e.notifiers[rule.Notifier]; ok {
+		deliveryErr = n.Notify(rule.Target, alerts)
+	} else {
+		deliveryErr = fmt.Errorf("notifier %q не настроен", rule.Notifier)
+	}
+
+	errText := ""
+	if deliveryErr != nil {
+		errText = deliveryErr.Error()
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Оповещения правила #%d не доставлены: %v", rule.ID, deliveryErr)
+	}
+	for _, a := range alerts {
+		if err := e.repo.UpdateAlertDelivery(a.ID, deliveryErr == nil, errText); err != nil {
+			log.Printf("ПРЕДУПРЕЖДЕНИЕ: Не удалось обновить оповещение #%d: %v", a.ID, err)
+		}
+	}
+}
+
+// Check проверяет правило для одного фонда и возвращает текст оповещения.
+// Правило TER срабатывает при переходе через порог, а не на каждом запуске.
+func Check(rule models.AlertRule, old models.ETFData, hasOld bool, cur models.ETFData) (string, bool) {
+	switch rule.Kind {
+	case models.AlertTERAbove:
+		if rule.Threshold == nil || cur.TERPercent == nil || *cur.TERPercent <= *rule.Threshold {
+			return "", false
+		}
+		if hasOld && old.TERPercent != nil && *old.TERPercent > *rule.Threshold {
+			return "", false
+		}
+		return fmt.Sprintf("%s: TER %.2f%% выше порога %.2f%%", cur.Ticker, *cur.TERPercent, *rule.Threshold), true
+
+	case models.AlertNAVDropPct:
+		if rule.Threshold == nil || !hasOld || old.NAVMillionRub == nil || cur.NAVMillionRub == nil || *old.NAVMillionRub <= 0 {
+			return "", false
+		}
+		drop := (*old.NAVMillionRub - *cur.NAVMillionRub) / *old.NAVMillionRub * 100
+		if drop <= *rule.Threshold {
+			return "", false
+		}
+		return fmt.Sprintf("%s: СЧА снизилась на %.2f%% (%.1f → %.1f млн ₽), порог %.2f%%",
+			cur.Ticker, drop, *old.NAVMillionRub, *cur.NAVMillionRub, *rule.Threshold), true
+
+	case models.AlertStatusChanged:
+		if !hasOld || old.TradeStatus == cur.TradeStatus {
+			return "", false
+		}
+		return fmt.Sprintf("%s: статус торгов изменился: «%s» → «%s»", cur.Ticker, old.TradeStatus, cur.TradeStatus), true
+	}
+
+	return "", false
+}
+
+// byTicker индексирует записи по тикеру
+func byTicker(data []models.ETFData) map[string]models.ETFData {
+	index := make(map[string]models.ETFData, len(data))
+	for _, etf := range data {
+		if _, ok := index[etf.Ticker]; !ok {
+			index[etf.Ticker] = etf
+		}
+	}
+	return index
+}
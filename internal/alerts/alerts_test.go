@@ -0,0 +1,241 @@
+package alerts
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"etf-scraper/internal/config"
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestCheck(t *testing.T) {
+	terRule := models.AlertRule{Kind: models.AlertTERAbove, Threshold: ptr(1)}
+	navRule := models.AlertRule{Kind: models.AlertNAVDropPct, Threshold: ptr(10)}
+	statusRule := models.AlertRule{Kind: models.AlertStatusChanged}
+
+	tests := []struct {
+		name    string
+		rule    models.AlertRule
+		old     models.ETFData
+		hasOld  bool
+		cur     models.ETFData
+		want    bool
+		message string
+	}{
+		{"ter crosses threshold", terRule,
+			models.ETFData{TERPercent: ptr(0.9)}, true,
+			models.ETFData{Ticker: "TMOS", TERPercent: ptr(1.2)}, true,
+			"TMOS: TER 1.20% выше порога 1.00%"},
+		{"ter already above", terRule,
+			models.ETFData{TERPercent: ptr(1.1)}, true,
+			models.ETFData{Ticker: "TMOS", TERPercent: ptr(1.2)}, false, ""},
+		{"ter equal to threshold", terRule,
+			models.ETFData{TERPercent: ptr(0.9)}, true,
+			models.ETFData{Ticker: "TMOS", TERPercent: ptr(1)}, false, ""},
+		{"ter falls below", terRule,
+			models.ETFData{TERPercent: ptr(1.5)}, true,
+			models.ETFData{Ticker: "TMOS", TERPercent: ptr(0.5)}, false, ""},
+		{"ter new fund above", terRule,
+			models.ETFData{}, false,
+			models.ETFData{Ticker: "NEW", TERPercent: ptr(2)}, true,
+			"NEW: TER 2.00% выше порога 1.00%"},
+		{"ter previously unknown", terRule,
+			models.ETFData{}, true,
+			models.ETFData{Ticker: "TMOS", TERPercent: ptr(2)}, true,
+			"TMOS: TER 2.00% выше порога 1.00%"},
+		{"ter unknown now", terRule,
+			models.ETFData{TERPercent: ptr(0.5)}, true,
+			models.ETFData{Ticker: "TMOS"}, false, ""},
+		{"ter without threshold", models.AlertRule{Kind: models.AlertTERAbove},
+			models.ETFData{}, false,
+			models.ETFData{Ticker: "TMOS", TERPercent: ptr(2)}, false, ""},
+
+		{"nav drop above threshold", navRule,
+			models.ETFData{NAVMillionRub: ptr(1000)}, true,
+			models.ETFData{Ticker: "SBMX", NAVMillionRub: ptr(850)}, true,
+			"SBMX: СЧА снизилась на 15.00% (1000.0 → 850.0 млн ₽), порог 10.00%"},
+		{"nav drop equal to threshold", navRule,
+			models.ETFData{NAVMillionRub: ptr(1000)}, true,
+			models.ETFData{Ticker: "SBMX", NAVMillionRub: ptr(900)}, false, ""},
+		{"nav growth", navRule,
+			models.ETFData{NAVMillionRub: ptr(1000)}, true,
+			models.ETFData{Ticker: "SBMX", NAVMillionRub: ptr(1500)}, false, ""},
+		{"nav new fund", navRule,
+			models.ETFData{}, false,
+			models.ETFData{Ticker: "SBMX", NAVMillionRub: ptr(10)}, false, ""},
+		{"nav zero before", navRule,
+			models.ETFData{NAVMillionRub: ptr(0)}, true,
+			models.ETFData{Ticker: "SBMX", NAVMillionRub: ptr(10)}, false, ""},
+		{"nav unknown now", navRule,
+			models.ETFData{NAVMillionRub: ptr(1000)}, true,
+			models.ETFData{Ticker: "SBMX"}, false, ""},
+
+		{"status changed", statusRule,
+			models.ETFData{TradeStatus: "Торгуется"}, true,
+			models.ETFData{Ticker: "AKMB", TradeStatus: "Приостановлен"}, true,
+			"AKMB: статус торгов изменился: «Торгуется» → «Приостановлен»"},
+		{"status same", statusRule,
+			models.ETFData{TradeStatus: "Торгуется"}, true,
+			models.ETFData{Ticker: "AKMB", TradeStatus: "Торгуется"}, false, ""},
+		{"status new fund", statusRule,
+			models.ETFData{}, false,
+			models.ETFData{Ticker: "AKMB", TradeStatus: "Торгуется"}, false, ""},
+
+		{"unknown kind", models.AlertRule{Kind: "price_above", Threshold: ptr(1)},
+			models.ETFData{}, false,
+			models.ETFData{Ticker: "TMOS", TERPercent: ptr(5)}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, ok := Check(tt.rule, tt.old, tt.hasOld, tt.cur)
+			if ok != tt.want {
+				t.Fatalf("Check() fired = %v, want %v (message %q)", ok, tt.want, message)
+			}
+			if message != tt.message {
+				t.Errorf("message = %q, want %q", message, tt.message)
+			}
+		})
+	}
+}
+
+// recordingNotifier запоминает доставленные оповещения
+type recordingNotifier struct {
+	calls [][]models.Alert
+	err   error
+}
+
+func (n *recordingNotifier) Name() string { return "record" }
+
+func (n *recordingNotifier) Notify(target string, alerts []models.Alert) error {
+	n.calls = append(n.calls, alerts)
+	return n.err
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	db, err := database.NewDatabase(t.TempDir() + "/alerts.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := database.NewRepository(db)
+
+	snapshot := func(session string, ter, nav float64) []models.ETFData {
+		return []models.ETFData{
+			{DateScraped: session, Ticker: "TMOS", TradeStatus: "Торгуется", TERPercent: ptr(ter), NAVMillionRub: ptr(nav)},
+			{DateScraped: session, Ticker: "SBMX", TradeStatus: "Торгуется", TERPercent: ptr(2), NAVMillionRub: ptr(nav)},
+		}
+	}
+	const prev, cur = "2024-04-01 00:00:00", "2024-05-01 00:00:00"
+	if err := repo.SaveETFs(snapshot(prev, 0.8, 1000)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SaveETFs(snapshot(cur, 1.2, 800)); err != nil {
+		t.Fatal(err)
+	}
+
+	wlID, err := repo.CreateWatchlist("Индексы", []string{"TMOS", "SBMX", "LQDT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	terRuleID, err := repo.CreateAlertRule(models.AlertRule{
+		WatchlistID: wlID, Kind: models.AlertTERAbove, Threshold: ptr(1), Notifier: "record",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateAlertRule(models.AlertRule{
+		WatchlistID: wlID, Kind: models.AlertNAVDropPct, Threshold: ptr(10), Notifier: "missing",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	notifier := &recordingNotifier{}
+	engine := NewEngine(repo, config.NewConfig())
+	engine.Register(notifier)
+
+	fired, err := engine.Evaluate(cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// TER пересек порог только у TMOS (у SBMX он выше и раньше); СЧА упала у обоих
+	if len(fired) != 3 {
+		t.Fatalf("fired = %+v, want 3 alerts", fired)
+	}
+	if len(notifier.calls) != 1 || len(notifier.calls[0]) != 1 {
+		t.Fatalf("notifier calls = %+v, want one call with one alert", notifier.calls)
+	}
+	if a := notifier.calls[0][0]; a.RuleID != terRuleID || a.Ticker != "TMOS" || a.WatchlistName != "Индексы" || a.DateScraped != cur {
+		t.Errorf("delivered alert = %+v", a)
+	}
+
+	// Повторная проверка того же сеанса ничего не отправляет: UNIQUE(rule_id, ticker, date_scraped)
+	again, err := engine.Evaluate(cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 0 || len(notifier.calls) != 1 {
+		t.Errorf("second Evaluate fired %d alerts, notifier calls %d", len(again), len(notifier.calls))
+	}
+
+	stored, err := repo.GetAlerts(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 3 {
+		t.Fatalf("stored alerts = %d, want 3", len(stored))
+	}
+	for _, a := range stored {
+		switch a.Kind {
+		case models.AlertTERAbove:
+			if !a.Delivered || a.Error != "" {
+				t.Errorf("TER alert delivery = %v %q", a.Delivered, a.Error)
+			}
+		case models.AlertNAVDropPct:
+			if a.Delivered || !strings.Contains(a.Error, `"missing"`) {
+				t.Errorf("NAV alert delivery = %v %q, want an unconfigured notifier error", a.Delivered, a.Error)
+			}
+		}
+	}
+}
+
+func TestEngineEvaluateDeliveryError(t *testing.T) {
+	db, err := database.NewDatabase(t.TempDir() + "/alerts.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := database.NewRepository(db)
+
+	const session = "2024-05-01 00:00:00"
+	if err := repo.SaveETFs([]models.ETFData{{DateScraped: session, Ticker: "TMOS", TERPercent: ptr(2)}}); err != nil {
+		t.Fatal(err)
+	}
+	wlID, err := repo.CreateWatchlist("Дорогие", []string{"TMOS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateAlertRule(models.AlertRule{
+		WatchlistID: wlID, Kind: models.AlertTERAbove, Threshold: ptr(1), Notifier: "record",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine(repo, config.NewConfig())
+	engine.Register(&recordingNotifier{err: errors.New("недоступен")})
+	if _, err := engine.Evaluate(session); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := repo.GetAlerts(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 || stored[0].Delivered || stored[0].Error != "недоступен" {
+		t.Errorf("stored = %+v", stored)
+	}
+}
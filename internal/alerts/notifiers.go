@@ -0,0 +1,157 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"etf-scraper/internal/models"
+	"etf-scraper/internal/webhook"
+)
+
+// Notifier доставляет оповещения одного правила. target — адрес доставки
+// из правила (URL, список email); его смысл определяет сам notifier.
+type Notifier interface {
+	Name() string
+	Notify(target string, alerts []models.Alert) error
+}
+
+// LogNotifier пишет оповещения в лог приложения
+type LogNotifier struct{}
+
+// Name возвращает имя notifier'а
+func (LogNotifier) Name() string { return "log" }
+
+// Notify пишет оповещения в лог
+func (LogNotifier) Notify(target string, alerts []models.Alert) error {
+	for _, a := range alerts {
+		log.Printf("🔔 [%s] %s", a.WatchlistName, a.Message)
+	}
+	return nil
+}
+
+// WebhookNotifier отправляет оповещения POST-запросом с JSON на URL из правила
+type WebhookNotifier struct {
+	secret string
+	client *http.Client
+}
+
+// NewWebhookNotifier создает notifier; при непустом секрете запрос подписывается
+// так же, как уведомления вебхуков (заголовок X-ETF-Signature)
+func NewWebhookNotifier(secret string) *WebhookNotifier {
+	return &WebhookNotifier{
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name возвращает имя notifier'а
+func (n *WebhookNotifier) Name() string { return "webhook" }
+
+// Notify отправляет оповещения одним запросом
+func (n *WebhookNotifier) Notify(target string, alerts []models.Alert) error {
+	payload := struct {
+		Type   string                 `json:"type"`
+		Alerts []models.AlertResponse `json:"alerts"`
+	}{Type: "alerts"}
+	for _, a := range alerts {
+		payload.Alerts = append(payload.Alerts, models.NewAlertResponse(a))
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.EventHeader, "alerts")
+	if n.secret != "" {
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("получатель ответил %s", resp.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier отправляет оповещения письмом; target — адреса через запятую
+type SMTPNotifier struct {
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+// NewSMTPNotifier создает notifier для SMTP сервера addr (host:port).
+// Без имени пользователя письмо отправляется без аутентификации.
+func NewSMTPNotifier(addr, from, username, password string) *SMTPNotifier {
+	return &SMTPNotifier{addr: addr, from: from, username: username, password: password}
+}
+
+// Name возвращает имя notifier'а
+func (n *SMTPNotifier) Name() string { return "email" }
+
+// Notify отправляет одно письмо со всеми оповещениями
+func (n *SMTPNotifier) Notify(target string, alerts []models.Alert) error {
+	var to []string
+	for _, addr := range strings.Split(target, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("не указаны адреса получателей")
+	}
+
+	var auth smtp.Auth
+	if n.username != "" {
+		host, _, err := net.SplitHostPort(n.addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", n.username, n.password, host)
+	}
+
+	return smtp.SendMail(n.addr, auth, n.from, to, n.message(to, alerts))
+}
+
+// message формирует письмо в UTF-8
+func (n *SMTPNotifier) message(to []string, alerts []models.Alert) []byte {
+	subject := fmt.Sprintf("ETF Scraper: %d оповещений (%s)", len(alerts), alerts[0].WatchlistName)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "Список наблюдения: %s\r\n", alerts[0].WatchlistName)
+	fmt.Fprintf(&b, "Сеанс: %s\r\n\r\n", alerts[0].DateScraped)
+	for _, a := range alerts {
+		fmt.Fprintf(&b, "- %s\r\n", a.Message)
+	}
+
+	return []byte(b.String())
+}
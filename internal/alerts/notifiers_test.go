@@ -0,0 +1,157 @@
+package alerts
+
+import (
+	"bufio"
+	"encoding/base64"
+	"mime"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"etf-scraper/internal/models"
+)
+
+// smtpMessage — письмо, принятое тестовым SMTP сервером
+type smtpMessage struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// startSMTPServer запускает минимальный SMTP сервер на локальном порту,
+// принимающий одно письмо. withAuth включает расширение AUTH PLAIN.
+func startSMTPServer(t *testing.T, withAuth bool) (string, <-chan smtpMessage) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	messages := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		var msg smtpMessage
+		tp.PrintfLine("220 localhost ESMTP test")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO":
+				if withAuth {
+					tp.PrintfLine("250-localhost")
+					tp.PrintfLine("250 AUTH PLAIN")
+				} else {
+					tp.PrintfLine("250 localhost")
+				}
+			case "AUTH":
+				_, encoded, _ := strings.Cut(arg, " ")
+				decoded, _ := base64.StdEncoding.DecodeString(encoded)
+				msg.auth = string(decoded)
+				tp.PrintfLine("235 OK")
+			case "MAIL":
+				msg.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+				tp.PrintfLine("250 OK")
+			case "RCPT":
+				msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				lines, err := tp.ReadDotLines()
+				if err != nil {
+					return
+				}
+				msg.data = strings.Join(lines, "\n")
+				tp.PrintfLine("250 OK")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				messages <- msg
+				return
+			default:
+				tp.PrintfLine("250 OK")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), messages
+}
+
+func testAlerts() []models.Alert {
+	return []models.Alert{
+		{WatchlistName: "Индексы", DateScraped: "2024-05-01 00:00:00", Message: "TMOS: TER 1.20% выше порога 1.00%"},
+		{WatchlistName: "Индексы", DateScraped: "2024-05-01 00:00:00", Message: "SBMX: СЧА снизилась на 15.00%"},
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	addr, messages := startSMTPServer(t, false)
+	n := NewSMTPNotifier(addr, "etf@example.com", "", "")
+
+	if err := n.Notify(" a@example.com, ,b@example.com ", testAlerts()); err != nil {
+		t.Fatal(err)
+	}
+	msg := <-messages
+
+	if msg.auth != "" {
+		t.Errorf("unexpected AUTH %q", msg.auth)
+	}
+	if msg.from != "etf@example.com" || strings.Join(msg.to, ",") != "a@example.com,b@example.com" {
+		t.Errorf("envelope = %q -> %q", msg.from, msg.to)
+	}
+
+	header, body, ok := strings.Cut(msg.data, "\n\n")
+	if !ok {
+		t.Fatalf("message has no header/body separator:\n%s", msg.data)
+	}
+	r := textproto.NewReader(bufio.NewReader(strings.NewReader(header + "\n\n")))
+	fields, err := r.ReadMIMEHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(fields.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "ETF Scraper: 2 оповещений (Индексы)" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if fields.Get("To") != "a@example.com, b@example.com" || fields.Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Errorf("headers = %v", fields)
+	}
+	for _, want := range []string{"Список наблюдения: Индексы", "- TMOS: TER 1.20% выше порога 1.00%", "- SBMX: СЧА снизилась на 15.00%"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body does not contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestSMTPNotifierAuth(t *testing.T) {
+	addr, messages := startSMTPServer(t, true)
+	n := NewSMTPNotifier(addr, "etf@example.com", "user", "pass")
+
+	if err := n.Notify("a@example.com", testAlerts()); err != nil {
+		t.Fatal(err)
+	}
+	if msg := <-messages; msg.auth != "\x00user\x00pass" {
+		t.Errorf("AUTH PLAIN = %q", msg.auth)
+	}
+}
+
+func TestSMTPNotifierNoRecipients(t *testing.T) {
+	n := NewSMTPNotifier("127.0.0.1:1", "etf@example.com", "", "")
+	if err := n.Notify(" , ", testAlerts()); err == nil {
+		t.Error("expected an error without recipients")
+	}
+}
@@ -0,0 +1,185 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/events"
+	"etf-scraper/internal/models"
+)
+
+// Способы подбора альтернатив
+const (
+	MatchByIndex      = "index"
+	MatchByAssetClass = "asset_class"
+)
+
+// indexNoiseWords — слова, не отличающие один индекс от другого
+var indexNoiseWords = map[string]bool{"индекс": true, "index": true}
+
+// IndexKey нормализует название индекса: регистр, «ё», знаки препинания и слово
+// «индекс» не учитываются. Результат служит ключом для сравнения и таблицы синонимов.
+func IndexKey(name string) string {
+	s := strings.ToLower(strings.TrimSpace(name))
+	s = strings.ReplaceAll(s, "ё", "е")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+
+	var words []string
+	for _, w := range strings.Fields(s) {
+		if !indexNoiseWords[w] {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// IndexNormalizer сводит написания индексов к каноническим с учетом таблицы синонимов
+type IndexNormalizer struct {
+	aliases map[string]string
+}
+
+// NewIndexNormalizer загружает таблицу синонимов индексов из БД
+func NewIndexNormalizer(repo *database.Repository) (*IndexNormalizer, error) {
+	aliases, err := repo.GetIndexAliases()
+	if err != nil {
+		return nil, err
+	}
+
+	n := &IndexNormalizer{aliases: make(map[string]string, len(aliases))}
+	for _, a := range aliases {
+		n.aliases[a.AliasKey] = IndexKey(a.Canonical)
+	}
+	return n, nil
+}
+
+// Normalize возвращает ключ канонического индекса
+func (n *IndexNormalizer) Normalize(name string) string {
+	key := IndexKey(name)
+	if canonical, ok := n.aliases[key]; ok {
+		return canonical
+	}
+	return key
+}
+
+// Alternatives подбирает фонды с той же экспозицией, что и у тикера, в последнем
+// сеансе, где он есть. Возвращает nil, если тикера нет в БД.
+func Alternatives(repo *database.Repository, ticker string) (*models.AlternativesResponse, error) {
+	latest, err := repo.GetLatestETFs(ticker)
+	if err != nil {
+		return nil, err
+	}
+	if len(latest) == 0 {
+		return nil, nil
+	}
+	base := latest[0]
+
+	normalizer, err := NewIndexNormalizer(repo)
+	if err != nil {
+		return nil, err
+	}
+	universe, err := repo.GetETFsBySession(base.DateScraped)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.AlternativesResponse{
+		Ticker:      base.Ticker,
+		TargetIndex: base.TargetIndex,
+		IndexKey:    normalizer.Normalize(base.TargetIndex),
+		AssetClass:  base.AssetClass,
+		Currency:    base.Currency,
+		MatchedBy:   MatchByIndex,
+		DateScraped: base.DateScraped,
+	}
+	if response.IndexKey == "" {
+		response.MatchedBy = MatchByAssetClass
+	}
+
+	var matches []models.ETFData
+	for _, etf := range universe {
+		if etf.Ticker == base.Ticker {
+			matches = append(matches, etf)
+			continue
+		}
+		if etf.AssetClass != base.AssetClass || !strings.EqualFold(etf.Currency, base.Currency) {
+			continue
+		}
+		if response.MatchedBy == MatchByIndex && normalizer.Normalize(etf.TargetIndex) != response.IndexKey {
+			continue
+		}
+		matches = append(matches, etf)
+	}
+
+	RankAlternatives(matches)
+	response.Alternatives = make([]models.Alternative, 0, len(matches))
+	for i, etf := range matches {
+		response.Alternatives = append(response.Alternatives, models.Alternative{
+			Rank:          i + 1,
+			Ticker:        etf.Ticker,
+			FundName:      etf.FundName,
+			ManagementCo:  etf.ManagementCo,
+			TargetIndex:   etf.TargetIndex,
+			TradeStatus:   etf.TradeStatus,
+			Trading:       events.IsTrading(etf.TradeStatus),
+			TERPercent:    etf.TERPercent,
+			NAVMillionRub: etf.NAVMillionRub,
+			TERDifference: terDifference(etf.TERPercent, base.TERPercent),
+			IsBase:        etf.Ticker == base.Ticker,
+		})
+	}
+
+	return response, nil
+}
+
+// RankAlternatives упорядочивает фонды: сначала торгуемые, затем по возрастанию TER
+// (неизвестный TER — в конце), затем по убыванию СЧА
+func RankAlternatives(funds []models.ETFData) {
+	sort.SliceStable(funds, func(i, j int) bool {
+		a, b := funds[i], funds[j]
+		if ta, tb := events.IsTrading(a.TradeStatus), events.IsTrading(b.TradeStatus); ta != tb {
+			return ta
+		}
+		if c := compareNullable(a.TERPercent, b.TERPercent); c != 0 {
+			return c < 0
+		}
+		if an, bn := a.NAVMillionRub, b.NAVMillionRub; (an == nil) != (bn == nil) {
+			return an != nil
+		} else if an != nil && *an != *bn {
+			return *an > *bn
+		}
+		return a.Ticker < b.Ticker
+	})
+}
+
+// compareNullable сравнивает значения, считая неизвестное значение наибольшим
+func compareNullable(a, b *float64) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	case *a < *b:
+		return -1
+	case *a > *b:
+		return 1
+	}
+	return 0
+}
+
+// terDifference возвращает разницу TER фонда и исходного фонда
+func terDifference(ter, base *float64) *float64 {
+	if ter == nil || base == nil {
+		return nil
+	}
+	d := round(*ter - *base)
+	return &d
+}
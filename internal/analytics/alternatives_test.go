@@ -0,0 +1,72 @@
+package analytics
+
+import (
+	"testing"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+func TestIndexKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		variants []string
+	}{
+		{"мосбиржи", []string{"Индекс МосБиржи", "индекс Мосбиржи", "МОСБИРЖИ", " Индекс «МосБиржи» "}},
+		{"мосбиржи полной доходности брутто", []string{
+			"Индекс МосБиржи полной доходности «брутто»", "Индекс МосБиржи полной доходности (брутто)",
+		}},
+		{"moex russia", []string{"MOEX Russia Index", "moex russia", "MOEX-Russia index"}},
+		{"мосбиржи государственных облигаций rgbitr", []string{
+			"Индекс МосБиржи государственных облигаций (RGBITR)", "Индекс Мосбиржи государственных облигаций RGBITR",
+		}},
+		{"s p 500", []string{"S&P 500", "S&P 500 Index", "s.p. 500"}},
+		{"золото xau rub", []string{"Золото (XAU/RUB)", "золото XAU-RUB"}},
+		{"еврооблигации", []string{"Индекс Еврооблигации", "Ёврооблигации"}},
+		// Без значимых слов ключ пуст
+		{"", []string{"", "  ", "Индекс", "index", "—"}},
+	}
+
+	for _, tt := range tests {
+		for _, v := range tt.variants {
+			if got := IndexKey(v); got != tt.key {
+				t.Errorf("IndexKey(%q) = %q, want %q", v, got, tt.key)
+			}
+		}
+	}
+
+	// Слово «индекс» внутри других слов не удаляется
+	if got := IndexKey("Индексный фонд"); got != "индексный фонд" {
+		t.Errorf("IndexKey(Индексный фонд) = %q", got)
+	}
+}
+
+func TestIndexNormalizer(t *testing.T) {
+	db, err := database.NewDatabase(t.TempDir() + "/aliases.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := database.NewRepository(db)
+
+	// Синоним хранится под IndexKey, как его сохраняет админка
+	alias := "MOEX Russia Index"
+	if err := repo.SaveIndexAlias(models.IndexAlias{
+		AliasKey: IndexKey(alias), Alias: alias, Canonical: "Индекс МосБиржи",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := NewIndexNormalizer(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"MOEX Russia", "moex-russia index", "Индекс МосБиржи", "индекс мосбиржи"} {
+		if got := n.Normalize(name); got != "мосбиржи" {
+			t.Errorf("Normalize(%q) = %q, want %q", name, got, "мосбиржи")
+		}
+	}
+	if got := n.Normalize("Индекс МосБиржи 15"); got != "мосбиржи 15" {
+		t.Errorf("Normalize(Индекс МосБиржи 15) = %q", got)
+	}
+}
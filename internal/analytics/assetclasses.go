@@ -0,0 +1,146 @@
+package analytics
+
+import (
+	"sort"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+// AssetClasses возвращает разбивку по классам активов за последний сеанс;
+// при withSeries добавляется та же разбивка по каждому сеансу в хронологическом порядке
+func AssetClasses(repo *database.Repository, withSeries bool) (*models.AssetClassSummaryResponse, error) {
+	sessions, err := repo.GetScrapeSessions("")
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.AssetClassSummaryResponse{}
+	response.Classes = []models.AssetClassSummary{}
+	if len(sessions) == 0 {
+		return response, nil
+	}
+
+	if !withSeries {
+		sessions = sessions[len(sessions)-1:]
+	}
+	for _, session := range sessions {
+		data, err := repo.GetETFsBySession(session)
+		if err != nil {
+			return nil, err
+		}
+		snapshot := AssetClassSnapshotOf(session, data)
+		if withSeries {
+			response.Series = append(response.Series, snapshot)
+		}
+		response.AssetClassSnapshot = snapshot
+	}
+
+	return response, nil
+}
+
+// AssetClassSnapshotOf считает показатели классов активов по записям одного сеанса;
+// классы упорядочены по убыванию СЧА
+func AssetClassSnapshotOf(session string, data []models.ETFData) models.AssetClassSnapshot {
+	groups := make(map[string][]models.ETFData)
+	total := 0.0
+	for _, etf := range data {
+		groups[etf.AssetClass] = append(groups[etf.AssetClass], etf)
+		if etf.NAVMillionRub != nil {
+			total += *etf.NAVMillionRub
+		}
+	}
+
+	snapshot := models.AssetClassSnapshot{
+		DateScraped: session,
+		TotalNAV:    round(total),
+		Classes:     make([]models.AssetClassSummary, 0, len(groups)),
+	}
+	for name, funds := range groups {
+		snapshot.Classes = append(snapshot.Classes, assetClassSummary(name, funds, total))
+	}
+	sort.Slice(snapshot.Classes, func(i, j int) bool {
+		a, b := snapshot.Classes[i], snapshot.Classes[j]
+		if a.TotalNAV != b.TotalNAV {
+			return a.TotalNAV > b.TotalNAV
+		}
+		return a.Name < b.Name
+	})
+
+	return snapshot
+}
+
+func assetClassSummary(name string, funds []models.ETFData, totalNAV float64) models.AssetClassSummary {
+	s := models.AssetClassSummary{Name: name, FundCount: len(funds)}
+
+	var nav float64
+	var ters []float64
+	var returns [6][]float64
+	for _, etf := range funds {
+		if etf.NAVMillionRub != nil {
+			nav += *etf.NAVMillionRub
+		}
+		if etf.TERPercent != nil {
+			ters = append(ters, *etf.TERPercent)
+		}
+		for i, v := range yearlyReturns(etf) {
+			if v != nil {
+				returns[i] = append(returns[i], *v)
+			}
+		}
+	}
+
+	s.TotalNAV = round(nav)
+	if totalNAV > 0 {
+		s.NAVShare = round(nav / totalNAV * 100)
+	}
+	s.AvgTER = mean(ters)
+	s.MedianTER = median(ters)
+	s.AvgReturns = models.ReturnsByYear{
+		PriceChange6M:   mean(returns[0]),
+		PriceChange2024: mean(returns[1]),
+		PriceChange2023: mean(returns[2]),
+		PriceChange2022: mean(returns[3]),
+		PriceChange2021: mean(returns[4]),
+		PriceChange2020: mean(returns[5]),
+	}
+	return s
+}
+
+// yearlyReturns перечисляет доходности фонда в порядке полей ReturnsByYear
+func yearlyReturns(etf models.ETFData) [6]*float64 {
+	return [6]*float64{
+		etf.PriceChange6M, etf.PriceChange2024, etf.PriceChange2023,
+		etf.PriceChange2022, etf.PriceChange2021, etf.PriceChange2020,
+	}
+}
+
+// mean возвращает среднее, округленное до сотых; nil для пустого набора
+func mean(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	m := round(sum / float64(len(values)))
+	return &m
+}
+
+// median возвращает медиану, округленную до сотых; nil для пустого набора
+func median(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	m := sorted[n/2]
+	if n%2 == 0 {
+		m = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	m = round(m)
+	return &m
+}
@@ -0,0 +1,293 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+// Способы ранжирования управляющих компаний
+const (
+	RankByNAV   = "nav"   // по суммарной СЧА (равносильно доле рынка)
+	RankByFunds = "funds" // по числу фондов
+	RankByTER   = "ter"   // по средневзвешенному TER, от дешевых к дорогим
+)
+
+// Rankings перечисляет допустимые способы ранжирования
+var Rankings = []string{RankByNAV, RankByFunds, RankByTER}
+
+// companyLegalForms — слова организационно-правовой формы, не влияющие на сопоставление
+var companyLegalForms = map[string]bool{
+	"ук": true, "ооо": true, "ао": true, "зао": true, "пао": true, "оао": true,
+	"llc": true, "jsc": true,
+}
+
+// companyAliases сводит известные переименования и полные названия к одному ключу
+var companyAliases = map[string]string{
+	"тинькофф капитал":             "т капитал",
+	"сбер управление активами":     "сбер уа",
+	"сбербанк управление активами": "сбер уа",
+}
+
+// NormalizeCompany возвращает ключ управляющей компании: регистр, кавычки, дефисы,
+// «ё» и организационно-правовая форма не учитываются, известные синонимы совпадают
+func NormalizeCompany(name string) string {
+	s := strings.ToLower(strings.TrimSpace(name))
+	s = strings.ReplaceAll(s, "ё", "е")
+	s = strings.ReplaceAll(s, "управляющая компания", " ")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+
+	var words []string
+	for _, w := range strings.Fields(s) {
+		if !companyLegalForms[w] {
+			words = append(words, w)
+		}
+	}
+
+	key := strings.Join(words, " ")
+	if alias, ok := companyAliases[key]; ok {
+		return alias
+	}
+	return key
+}
+
+// FilterEvents возвращает события по фильтру. Управляющая компания managementCo
+// сравнивается с названиями в событиях через NormalizeCompany, поэтому
+// «Тинькофф Капитал» находит и события «Т-Капитал».
+func FilterEvents(repo *database.Repository, filter database.EventFilter, managementCo string) ([]models.Event, error) {
+	if key := NormalizeCompany(managementCo); key != "" {
+		companies, err := repo.GetEventCompanies()
+		if err != nil {
+			return nil, err
+		}
+		filter.ManagementCos = nil
+		for _, c := range companies {
+			if NormalizeCompany(c) == key {
+				filter.ManagementCos = append(filter.ManagementCos, c)
+			}
+		}
+		if len(filter.ManagementCos) == 0 {
+			return nil, nil
+		}
+	}
+	return repo.GetEvents(filter)
+}
+
+// companyAgg накапливает показатели компании в одном сеансе
+type companyAgg struct {
+	variants map[string]int
+	funds    []models.ETFData
+	nav      float64
+	terNAV   float64 // сумма TER × СЧА
+	terBase  float64 // сумма СЧА фондов с известным TER
+}
+
+// aggregateCompanies группирует записи сеанса по нормализованной компании
+// и возвращает агрегаты и суммарную СЧА сеанса
+func aggregateCompanies(data []models.ETFData) (map[string]*companyAgg, float64) {
+	groups := make(map[string]*companyAgg)
+	total := 0.0
+
+	for _, etf := range data {
+		key := NormalizeCompany(etf.ManagementCo)
+		g, ok := groups[key]
+		if !ok {
+			g = &companyAgg{variants: make(map[string]int)}
+			groups[key] = g
+		}
+		g.variants[etf.ManagementCo]++
+		g.funds = append(g.funds, etf)
+
+		if etf.NAVMillionRub == nil {
+			continue
+		}
+		nav := *etf.NAVMillionRub
+		g.nav += nav
+		total += nav
+		if etf.TERPercent != nil {
+			g.terNAV += *etf.TERPercent * nav
+			g.terBase += nav
+		}
+	}
+
+	return groups, total
+}
+
+// summary переводит агрегат в ответ API; именем компании служит самое частое написание
+func (g *companyAgg) summary(totalNAV float64) models.CompanySummary {
+	s := models.CompanySummary{
+		Name:      g.displayName(),
+		Variants:  make([]string, 0, len(g.variants)),
+		FundCount: len(g.funds),
+		TotalNAV:  round(g.nav),
+	}
+	for v := range g.variants {
+		s.Variants = append(s.Variants, v)
+	}
+	sort.Strings(s.Variants)
+
+	if g.terBase > 0 {
+		ter := round(g.terNAV / g.terBase)
+		s.WeightedTER = &ter
+	}
+	if totalNAV > 0 {
+		s.MarketShare = round(g.nav / totalNAV * 100)
+	}
+	return s
+}
+
+func (g *companyAgg) displayName() string {
+	name, best := "", 0
+	for v, n := range g.variants {
+		if n > best || (n == best && v < name) {
+			name, best = v, n
+		}
+	}
+	return name
+}
+
+// Companies возвращает агрегаты по управляющим компаниям за сеанс (пустой — последний),
+// ранжированные по суммарной СЧА
+func Companies(repo *database.Repository, session string) (*models.CompaniesResponse, error) {
+	if session == "" {
+		latest, err := repo.GetLatestSession("")
+		if err != nil {
+			return nil, err
+		}
+		session = latest
+	}
+
+	data, err := repo.GetETFsBySession(session)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, total := aggregateCompanies(data)
+	response := &models.CompaniesResponse{
+		DateScraped: session,
+		Companies:   make([]models.CompanySummary, 0, len(groups)),
+	}
+	for _, g := range groups {
+		response.Companies = append(response.Companies, g.summary(total))
+	}
+	Rank(response.Companies, RankByNAV)
+
+	return response, nil
+}
+
+// Rank упорядочивает компании по критерию by и проставляет места.
+// Возвращает false для неизвестного критерия.
+func Rank(companies []models.CompanySummary, by string) bool {
+	var less func(a, b models.CompanySummary) bool
+	switch by {
+	case RankByNAV:
+		less = func(a, b models.CompanySummary) bool { return a.TotalNAV > b.TotalNAV }
+	case RankByFunds:
+		less = func(a, b models.CompanySummary) bool { return a.FundCount > b.FundCount }
+	case RankByTER:
+		less = func(a, b models.CompanySummary) bool {
+			if a.WeightedTER == nil || b.WeightedTER == nil {
+				return a.WeightedTER != nil && b.WeightedTER == nil
+			}
+			return *a.WeightedTER < *b.WeightedTER
+		}
+	default:
+		return false
+	}
+
+	sort.SliceStable(companies, func(i, j int) bool {
+		a, b := companies[i], companies[j]
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Name < b.Name
+	})
+	for i := range companies {
+		companies[i].Rank = i + 1
+	}
+	return true
+}
+
+// Company возвращает компанию по любому из написаний названия: показатели и фонды
+// за последний сеанс, где она присутствует, и динамику по всем сеансам.
+// Возвращает nil, если компания не найдена.
+func Company(repo *database.Repository, name string) (*models.CompanyDetail, error) {
+	key := NormalizeCompany(name)
+	if key == "" {
+		return nil, nil
+	}
+
+	sessions, err := repo.GetScrapeSessions("")
+	if err != nil {
+		return nil, err
+	}
+
+	var detail *models.CompanyDetail
+	history := []models.CompanyPoint{}
+	for _, session := range sessions {
+		data, err := repo.GetETFsBySession(session)
+		if err != nil {
+			return nil, err
+		}
+
+		groups, total := aggregateCompanies(data)
+		g, ok := groups[key]
+		if !ok {
+			continue
+		}
+
+		s := g.summary(total)
+		history = append(history, models.CompanyPoint{
+			DateScraped: session,
+			FundCount:   s.FundCount,
+			TotalNAV:    s.TotalNAV,
+			WeightedTER: s.WeightedTER,
+			MarketShare: s.MarketShare,
+		})
+
+		detail = &models.CompanyDetail{
+			CompanySummary: s,
+			DateScraped:    session,
+			Funds:          companyFunds(g.funds),
+		}
+	}
+
+	if detail != nil {
+		detail.History = history
+	}
+	return detail, nil
+}
+
+// companyFunds возвращает фонды компании по убыванию СЧА
+func companyFunds(data []models.ETFData) []models.CompanyFund {
+	funds := make([]models.CompanyFund, 0, len(data))
+	for _, etf := range data {
+		funds = append(funds, models.CompanyFund{
+			Ticker:        etf.Ticker,
+			FundName:      etf.FundName,
+			AssetClass:    etf.AssetClass,
+			TradeStatus:   etf.TradeStatus,
+			TERPercent:    etf.TERPercent,
+			NAVMillionRub: etf.NAVMillionRub,
+		})
+	}
+	sort.SliceStable(funds, func(i, j int) bool {
+		a, b := funds[i].NAVMillionRub, funds[j].NAVMillionRub
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a > *b
+	})
+	return funds
+}
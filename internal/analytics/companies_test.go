@@ -0,0 +1,56 @@
+package analytics
+
+import "testing"
+
+func TestNormalizeCompany(t *testing.T) {
+	tests := []struct {
+		key      string
+		variants []string
+	}{
+		{"т капитал", []string{
+			"Т-Капитал", "т капитал", "ООО «УК «Т-Капитал»", "УК Т-Капитал",
+			"Тинькофф Капитал", "АО «Тинькофф Капитал»", "ТИНЬКОФФ  КАПИТАЛ",
+		}},
+		{"сбер уа", []string{
+			"Сбер Управление Активами", "АО «Сбер Управление Активами»",
+			"Сбербанк Управление Активами", "АО \"Сбербанк Управление Активами\"",
+		}},
+		{"вим инвестиции", []string{
+			"ВИМ Инвестиции", "ООО «УК ВИМ Инвестиции»", "УК «ВИМ Инвестиции»",
+		}},
+		{"альфа капитал", []string{
+			"Альфа-Капитал", "ООО УК «Альфа-Капитал»", "УК Альфа Капитал", "Управляющая компания «Альфа-Капитал»",
+		}},
+		{"первая", []string{
+			"Первая", "АО «Управляющая компания «Первая»", "АО УК «Первая»",
+		}},
+		{"атон менеджмент", []string{
+			"Атон-менеджмент", "УК «Атон-Менеджмент»", "ООО «УК «АТОН-менеджмент»",
+		}},
+		{"райффайзен капитал", []string{
+			"Райффайзен Капитал", "ООО «УК «Райффайзен Капитал»",
+		}},
+		{"елка капитал", []string{"Ёлка Капитал", "елка-капитал"}},
+		// Только организационно-правовая форма или пустая строка — ключ пуст
+		{"", []string{"", "  ", "ООО", "АО «УК»"}},
+	}
+
+	for _, tt := range tests {
+		for _, v := range tt.variants {
+			if got := NormalizeCompany(v); got != tt.key {
+				t.Errorf("NormalizeCompany(%q) = %q, want %q", v, got, tt.key)
+			}
+		}
+	}
+
+	// Разные компании не сливаются
+	distinct := []string{"Т-Капитал", "Альфа-Капитал", "Райффайзен Капитал", "Сбер Управление Активами", "Сбербанк"}
+	seen := make(map[string]string)
+	for _, name := range distinct {
+		key := NormalizeCompany(name)
+		if other, ok := seen[key]; ok {
+			t.Errorf("%q and %q share key %q", name, other, key)
+		}
+		seen[key] = name
+	}
+}
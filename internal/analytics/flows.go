@@ -0,0 +1,264 @@
+// Package analytics строит аналитику по истории etf_data: оценку потоков средств
+// и агрегаты по группам фондов
+package analytics
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+// halfYearDays — длина периода колонки «изменение за 6 месяцев» в днях
+const halfYearDays = 182.5
+
+// Flows оценивает чистые потоки средств между сеансами from и to (ссылки как в
+// database.Repository.ResolveSession; пустой to — последний сеанс, пустой from — сеанс перед to).
+// Период разбивается на пары соседних сеансов, потоки по парам суммируются.
+func Flows(repo *database.Repository, fromRef, toRef string) (*models.FlowReport, error) {
+	from, to, err := repo.ResolveRange(fromRef, toRef)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		from, to = to, from
+	}
+
+	later, err := repo.GetScrapeSessions(from)
+	if err != nil {
+		return nil, err
+	}
+	sessions := []string{from}
+	for _, s := range later {
+		if s > to {
+			break
+		}
+		sessions = append(sessions, s)
+	}
+
+	acc := newFlowAccumulator()
+	prevData, err := repo.GetETFsBySession(from)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(sessions); i++ {
+		curData, err := repo.GetETFsBySession(sessions[i])
+		if err != nil {
+			return nil, err
+		}
+		acc.addPair(prevData, curData, sessionDays(sessions[i-1], sessions[i]))
+		prevData = curData
+	}
+
+	report := acc.report()
+	report.From = from
+	report.To = to
+	report.Periods = len(sessions) - 1
+	report.YTDUnavailableYears = ytdUnavailableYears(sessions)
+	return report, nil
+}
+
+// MarketReturn оценивает рыночную доходность фонда (в долях) между двумя снимками,
+// разделенными days днями, и возвращает метод оценки. Доходность с начала года
+// используется, только если в снимках есть колонка изменения цены за год сеанса
+// (models.ReturnYears); иначе — доходность за 6 месяцев, пересчитанная на период.
+func MarketReturn(old, cur models.ETFData, days float64) (float64, string) {
+	oldYear, okOld := sessionYear(old.DateScraped)
+	curYear, okCur := sessionYear(cur.DateScraped)
+	if okOld && okCur {
+		oldYTD, curYTD := old.PriceChangeYear(oldYear), cur.PriceChangeYear(curYear)
+		switch {
+		case curYear == oldYear && oldYTD != nil && curYTD != nil:
+			return growth(*curYTD)/growth(*oldYTD) - 1, models.FlowMethodYTD
+		case curYear == oldYear+1 && oldYTD != nil && curYTD != nil:
+			// Остаток прошлого года берется из его итоговой доходности
+			if full := cur.PriceChangeYear(oldYear); full != nil {
+				return growth(*full)/growth(*oldYTD)*growth(*curYTD) - 1, models.FlowMethodYTD
+			}
+		}
+	}
+
+	if cur.PriceChange6M != nil && days > 0 {
+		return math.Pow(growth(*cur.PriceChange6M), days/halfYearDays) - 1, models.FlowMethod6M
+	}
+
+	return 0, models.FlowMethodNAV
+}
+
+// flowAccumulator суммирует потоки фондов по парам сеансов
+type flowAccumulator struct {
+	funds map[string]*models.FundFlow
+	order []string
+}
+
+func newFlowAccumulator() *flowAccumulator {
+	return &flowAccumulator{funds: make(map[string]*models.FundFlow)}
+}
+
+// addPair добавляет потоки между двумя соседними сеансами. Учитываются только
+// фонды, присутствующие в обоих сеансах с известной СЧА.
+func (a *flowAccumulator) addPair(prev, cur []models.ETFData, days float64) {
+	before := make(map[string]models.ETFData, len(prev))
+	for _, etf := range prev {
+		if _, ok := before[etf.Ticker]; !ok {
+			before[etf.Ticker] = etf
+		}
+	}
+
+	seen := make(map[string]bool, len(cur))
+	for _, etf := range cur {
+		old, ok := before[etf.Ticker]
+		if !ok || seen[etf.Ticker] || old.NAVMillionRub == nil || etf.NAVMillionRub == nil {
+			continue
+		}
+		seen[etf.Ticker] = true
+
+		r, method := MarketReturn(old, etf, days)
+		navStart, navEnd := *old.NAVMillionRub, *etf.NAVMillionRub
+		market := navStart * r
+
+		f, ok := a.funds[etf.Ticker]
+		if !ok {
+			f = &models.FundFlow{Ticker: etf.Ticker, NAVStart: navStart, Method: method}
+			a.funds[etf.Ticker] = f
+			a.order = append(a.order, etf.Ticker)
+		} else if f.Method != method {
+			f.Method = models.FlowMethodMixed
+		}
+		f.FundName = etf.FundName
+		f.ManagementCo = etf.ManagementCo
+		f.AssetClass = etf.AssetClass
+		f.NAVEnd = navEnd
+		f.MarketEffect += market
+		f.NetFlow += navEnd - navStart - market
+	}
+}
+
+// report формирует итог: фонды и группы по убыванию чистого потока
+func (a *flowAccumulator) report() *models.FlowReport {
+	report := &models.FlowReport{
+		Total:               models.GroupFlow{Name: "Все"},
+		Funds:               []models.FundFlow{},
+		ManagementCompanies: []models.GroupFlow{},
+		AssetClasses:        []models.GroupFlow{},
+	}
+
+	companies := make(map[string]*models.GroupFlow)
+	classes := make(map[string]*models.GroupFlow)
+
+	for _, ticker := range a.order {
+		f := *a.funds[ticker]
+		f.FlowPercent = flowPercent(f.NetFlow, f.NAVStart)
+		f.NAVStart, f.NAVEnd = round(f.NAVStart), round(f.NAVEnd)
+		f.MarketEffect, f.NetFlow = round(f.MarketEffect), round(f.NetFlow)
+		report.Funds = append(report.Funds, f)
+
+		addToGroup(&report.Total, f)
+		addToGroup(group(companies, NormalizeCompany(f.ManagementCo), f.ManagementCo), f)
+		addToGroup(group(classes, f.AssetClass, f.AssetClass), f)
+	}
+
+	sort.SliceStable(report.Funds, func(i, j int) bool { return report.Funds[i].NetFlow > report.Funds[j].NetFlow })
+	report.ManagementCompanies = sortedGroups(companies)
+	report.AssetClasses = sortedGroups(classes)
+	finishGroup(&report.Total)
+
+	return report
+}
+
+// group возвращает группу по ключу; name — отображаемое имя новой группы
+func group(groups map[string]*models.GroupFlow, key, name string) *models.GroupFlow {
+	g, ok := groups[key]
+	if !ok {
+		g = &models.GroupFlow{Name: name}
+		groups[key] = g
+	}
+	return g
+}
+
+func addToGroup(g *models.GroupFlow, f models.FundFlow) {
+	g.Funds++
+	g.NAVStart += f.NAVStart
+	g.NAVEnd += f.NAVEnd
+	g.MarketEffect += f.MarketEffect
+	g.NetFlow += f.NetFlow
+}
+
+func finishGroup(g *models.GroupFlow) {
+	g.FlowPercent = flowPercent(g.NetFlow, g.NAVStart)
+	g.NAVStart, g.NAVEnd = round(g.NAVStart), round(g.NAVEnd)
+	g.MarketEffect, g.NetFlow = round(g.MarketEffect), round(g.NetFlow)
+}
+
+func sortedGroups(groups map[string]*models.GroupFlow) []models.GroupFlow {
+	result := make([]models.GroupFlow, 0, len(groups))
+	for _, g := range groups {
+		finishGroup(g)
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].NetFlow != result[j].NetFlow {
+			return result[i].NetFlow > result[j].NetFlow
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// flowPercent выражает поток в процентах от СЧА на начало периода
+func flowPercent(flow, navStart float64) *float64 {
+	if navStart <= 0 {
+		return nil
+	}
+	p := round(flow / navStart * 100)
+	return &p
+}
+
+// ytdUnavailableYears возвращает годы сеансов, для которых нет колонки доходности
+// с начала года
+func ytdUnavailableYears(sessions []string) []int {
+	var years []int
+	for _, s := range sessions {
+		year, ok := sessionYear(s)
+		if ok && !slices.Contains(models.ReturnYears, year) && !slices.Contains(years, year) {
+			years = append(years, year)
+		}
+	}
+	return years
+}
+
+// growth переводит доходность в процентах в множитель
+func growth(percent float64) float64 {
+	return 1 + percent/100
+}
+
+// sessionYear возвращает год сеанса скрейпинга
+func sessionYear(dateScraped string) (int, bool) {
+	t, err := parseSession(dateScraped)
+	if err != nil {
+		return 0, false
+	}
+	return t.Year(), true
+}
+
+// sessionDays возвращает число дней между сеансами
+func sessionDays(from, to string) float64 {
+	a, errA := parseSession(from)
+	b, errB := parseSession(to)
+	if errA != nil || errB != nil {
+		return 0
+	}
+	return b.Sub(a).Hours() / 24
+}
+
+func parseSession(dateScraped string) (time.Time, error) {
+	return time.Parse("2006-01-02 15:04:05", dateScraped)
+}
+
+// round округляет суммы до сотых
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
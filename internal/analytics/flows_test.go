@@ -0,0 +1,107 @@
+package analytics
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestMarketReturnWithoutYTDColumn(t *testing.T) {
+	// За 2026 год колонки доходности с начала года нет: используется 6M
+	old := models.ETFData{DateScraped: "2026-03-01 10:00:00", PriceChange6M: ptr(4), PriceChange2024: ptr(20)}
+	cur := models.ETFData{DateScraped: "2026-05-01 10:00:00", PriceChange6M: ptr(10), PriceChange2024: ptr(20)}
+	days := sessionDays(old.DateScraped, cur.DateScraped)
+
+	r, method := MarketReturn(old, cur, days)
+	if method != models.FlowMethod6M {
+		t.Fatalf("method = %q, want %q", method, models.FlowMethod6M)
+	}
+	want := math.Pow(1.10, 61/halfYearDays) - 1
+	if math.Abs(r-want) > 1e-12 {
+		t.Errorf("return = %v, want %v", r, want)
+	}
+}
+
+func TestMarketReturnYTD(t *testing.T) {
+	old := models.ETFData{DateScraped: "2024-03-01 10:00:00", PriceChange2024: ptr(5), PriceChange6M: ptr(1)}
+	cur := models.ETFData{DateScraped: "2024-05-01 10:00:00", PriceChange2024: ptr(10), PriceChange6M: ptr(1)}
+
+	r, method := MarketReturn(old, cur, 61)
+	if method != models.FlowMethodYTD {
+		t.Fatalf("method = %q, want %q", method, models.FlowMethodYTD)
+	}
+	if want := 1.10/1.05 - 1; math.Abs(r-want) > 1e-12 {
+		t.Errorf("return = %v, want %v", r, want)
+	}
+}
+
+func TestMarketReturnNoPriceData(t *testing.T) {
+	old := models.ETFData{DateScraped: "2026-03-01 10:00:00"}
+	cur := models.ETFData{DateScraped: "2026-05-01 10:00:00"}
+	if r, method := MarketReturn(old, cur, 61); method != models.FlowMethodNAV || r != 0 {
+		t.Errorf("MarketReturn = %v, %q, want 0, %q", r, method, models.FlowMethodNAV)
+	}
+}
+
+func TestFlowsTwoSessions2026(t *testing.T) {
+	db, err := database.NewDatabase(t.TempDir() + "/flows.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := database.NewRepository(db)
+
+	snapshot := func(session string, nav, change6M float64) []models.ETFData {
+		return []models.ETFData{{
+			DateScraped: session, Ticker: "TMOS", FundName: "Тинькофф iMOEX",
+			ManagementCo: "Т-Капитал", AssetClass: "Акции",
+			NAVMillionRub: ptr(nav), PriceChange6M: ptr(change6M),
+		}}
+	}
+	if err := repo.SaveETFs(snapshot("2026-03-01 10:00:00", 1000, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SaveETFs(snapshot("2026-05-01 10:00:00", 1200, 10)); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Flows(repo, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Periods != 1 {
+		t.Errorf("periods = %d, want 1", report.Periods)
+	}
+	if !slices.Equal(report.YTDUnavailableYears, []int{2026}) {
+		t.Errorf("ytdUnavailableYears = %v, want [2026]", report.YTDUnavailableYears)
+	}
+	if len(report.Funds) != 1 {
+		t.Fatalf("funds = %d, want 1", len(report.Funds))
+	}
+
+	f := report.Funds[0]
+	if f.Method != models.FlowMethod6M {
+		t.Errorf("method = %q, want %q", f.Method, models.FlowMethod6M)
+	}
+	market := 1000 * (math.Pow(1.10, 61/halfYearDays) - 1)
+	if want := round(200 - market); f.NetFlow != want {
+		t.Errorf("net flow = %v, want %v", f.NetFlow, want)
+	}
+	if want := round(market); f.MarketEffect != want {
+		t.Errorf("market effect = %v, want %v", f.MarketEffect, want)
+	}
+}
+
+func TestFlowsYTDAvailable(t *testing.T) {
+	if years := ytdUnavailableYears([]string{"2024-03-01 10:00:00", "2024-05-01 10:00:00"}); len(years) != 0 {
+		t.Errorf("ytdUnavailableYears = %v, want none", years)
+	}
+	if years := ytdUnavailableYears([]string{"2024-12-01 10:00:00", "2025-01-15 10:00:00", "2026-01-15 10:00:00"}); !slices.Equal(years, []int{2025, 2026}) {
+		t.Errorf("ytdUnavailableYears = %v, want [2025 2026]", years)
+	}
+}
@@ -0,0 +1,281 @@
+package analytics
+
+import (
+	"log"
+	"math"
+	"sort"
+
+	"etf-scraper/internal/backtest"
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+// minCorrelationYears — наименьшее число общих лет для расчета корреляции
+const minCorrelationYears = 3
+
+// CompletedReturns возвращает доходности фонда в процентах за завершенные
+// календарные годы; доходность года сеанса (с начала года) не учитывается
+func CompletedReturns(etf models.ETFData) map[int]float64 {
+	returns := make(map[int]float64)
+	current, ok := sessionYear(etf.DateScraped)
+	for _, year := range models.ReturnYears {
+		if ok && year >= current {
+			continue
+		}
+		if v := etf.PriceChangeYear(year); v != nil {
+			returns[year] = *v
+		}
+	}
+	return returns
+}
+
+// FundRiskMetrics рассчитывает показатели риска фонда по доходностям за завершенные годы
+func FundRiskMetrics(etf models.ETFData) models.RiskMetrics {
+	m := models.RiskMetrics{
+		DateScraped: etf.DateScraped,
+		Ticker:      etf.Ticker,
+		Returns:     CompletedReturns(etf),
+	}
+	m.Observations = len(m.Returns)
+	if len(m.Returns) == 0 {
+		return m
+	}
+
+	result, err := backtest.Run(
+		[]backtest.Asset{{Ticker: etf.Ticker, Weight: 1, Returns: m.Returns}},
+		backtest.Options{Years: models.ReturnYears},
+	)
+	if err != nil {
+		return m
+	}
+	m.AnnualizedReturn = roundPtr(result.AnnualizedReturn)
+	m.Volatility = roundPtr(result.Volatility)
+	drawdown := round(result.MaxDrawdown)
+	m.MaxDrawdown = &drawdown
+	return m
+}
+
+// UpdateRisk рассчитывает показатели риска фондов сеанса и сохраняет их в кэш.
+// После скрейпинга пересчитывается только новый сеанс.
+func UpdateRisk(repo *database.Repository, dateScraped string) ([]models.RiskMetrics, error) {
+	data, err := repo.GetETFsBySession(dateScraped)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(data))
+	metrics := make([]models.RiskMetrics, 0, len(data))
+	for _, etf := range data {
+		if seen[etf.Ticker] {
+			continue
+		}
+		seen[etf.Ticker] = true
+		metrics = append(metrics, FundRiskMetrics(etf))
+	}
+
+	if err := repo.SaveRiskMetrics(metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// UpdateRiskLogged вызывает UpdateRisk и пишет результат в лог; ошибка не прерывает запуск
+func UpdateRiskLogged(repo *database.Repository, dateScraped string) {
+	metrics, err := UpdateRisk(repo, dateScraped)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Не удалось рассчитать показатели риска: %v", err)
+		return
+	}
+	log.Printf("Показатели риска рассчитаны для фондов: %d", len(metrics))
+}
+
+// riskMetrics возвращает кэш показателей риска за последний сеанс; сеанс,
+// сохраненный до появления кэша, рассчитывается при первом обращении
+func riskMetrics(repo *database.Repository) (string, map[string]models.RiskMetrics, error) {
+	session, err := repo.GetLatestSession("")
+	if err != nil || session == "" {
+		return session, nil, err
+	}
+
+	metrics, err := repo.GetRiskMetrics(session)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(metrics) == 0 {
+		if metrics, err = UpdateRisk(repo, session); err != nil {
+			return "", nil, err
+		}
+	}
+
+	byTicker := make(map[string]models.RiskMetrics, len(metrics))
+	for _, m := range metrics {
+		byTicker[m.Ticker] = m
+	}
+	return session, byTicker, nil
+}
+
+// Risk возвращает показатели риска фондов последнего сеанса (tickers пуст — все фонды)
+// с коэффициентом Шарпа относительно безрисковой ставки riskFree, % годовых
+func Risk(repo *database.Repository, tickers []string, riskFree float64) (*models.RiskResponse, error) {
+	response := &models.RiskResponse{
+		RiskFreeRate: riskFree,
+		Funds:        []models.FundRisk{},
+		Missing:      []models.MissingTicker{},
+	}
+
+	session, metrics, err := riskMetrics(repo)
+	if err != nil || session == "" {
+		return response, err
+	}
+	response.DateScraped = session
+
+	data, err := repo.GetETFsBySession(session)
+	if err != nil {
+		return nil, err
+	}
+	funds := make(map[string]models.ETFData, len(data))
+	for _, etf := range data {
+		if _, ok := funds[etf.Ticker]; !ok {
+			funds[etf.Ticker] = etf
+		}
+	}
+
+	if len(tickers) == 0 {
+		for ticker := range metrics {
+			tickers = append(tickers, ticker)
+		}
+		sort.Strings(tickers)
+	}
+
+	seen := make(map[string]bool, len(tickers))
+	for _, ticker := range tickers {
+		if seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+
+		m, ok := metrics[ticker]
+		if !ok {
+			response.Missing = append(response.Missing, models.MissingTicker{Ticker: ticker, Reason: "not found"})
+			continue
+		}
+
+		fund := models.FundRisk{
+			Ticker:           ticker,
+			FundName:         funds[ticker].FundName,
+			AssetClass:       funds[ticker].AssetClass,
+			Years:            returnYears(m.Returns),
+			Observations:     m.Observations,
+			AnnualizedReturn: m.AnnualizedReturn,
+			Volatility:       m.Volatility,
+			MaxDrawdown:      m.MaxDrawdown,
+		}
+		if m.AnnualizedReturn != nil && m.Volatility != nil && *m.Volatility > 0 {
+			sharpe := round((*m.AnnualizedReturn - riskFree) / *m.Volatility)
+			fund.Sharpe = &sharpe
+		}
+		response.Funds = append(response.Funds, fund)
+	}
+
+	return response, nil
+}
+
+// Correlation возвращает матрицу корреляций Пирсона годовых доходностей фондов
+// последнего сеанса по общим для каждой пары годам
+func Correlation(repo *database.Repository, tickers []string) (*models.CorrelationResponse, error) {
+	response := &models.CorrelationResponse{
+		Tickers:      []string{},
+		Matrix:       [][]*float64{},
+		Observations: [][]int{},
+		Missing:      []models.MissingTicker{},
+	}
+
+	session, metrics, err := riskMetrics(repo)
+	if err != nil {
+		return nil, err
+	}
+	response.DateScraped = session
+
+	var series []map[int]float64
+	seen := make(map[string]bool, len(tickers))
+	for _, ticker := range tickers {
+		if seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+
+		m, ok := metrics[ticker]
+		if !ok {
+			response.Missing = append(response.Missing, models.MissingTicker{Ticker: ticker, Reason: "not found"})
+			continue
+		}
+		response.Tickers = append(response.Tickers, ticker)
+		series = append(series, m.Returns)
+	}
+
+	for i := range series {
+		row := make([]*float64, len(series))
+		counts := make([]int, len(series))
+		for j := range series {
+			row[j], counts[j] = correlation(series[i], series[j])
+		}
+		response.Matrix = append(response.Matrix, row)
+		response.Observations = append(response.Observations, counts)
+	}
+
+	return response, nil
+}
+
+// correlation считает корреляцию двух рядов по общим годам и возвращает число
+// общих лет; nil, если их меньше minCorrelationYears или ряд постоянен
+func correlation(a, b map[int]float64) (*float64, int) {
+	var xs, ys []float64
+	for year, x := range a {
+		if y, ok := b[year]; ok {
+			xs = append(xs, x)
+			ys = append(ys, y)
+		}
+	}
+	n := len(xs)
+	if n < minCorrelationYears {
+		return nil, n
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i] / float64(n)
+		meanY += ys[i] / float64(n)
+	}
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil, n
+	}
+
+	c := round(cov / math.Sqrt(varX*varY))
+	return &c, n
+}
+
+// returnYears возвращает годы ряда доходностей по возрастанию
+func returnYears(returns map[int]float64) []int {
+	years := make([]int, 0, len(returns))
+	for year := range returns {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	return years
+}
+
+func roundPtr(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	r := round(*v)
+	return &r
+}
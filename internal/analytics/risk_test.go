@@ -0,0 +1,188 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+const riskSession = "2025-05-01 00:00:00"
+
+// newRiskRepo сохраняет сеанс с фондами, доходности которых заданы по годам
+func newRiskRepo(t *testing.T, returns map[string]map[int]float64) *database.Repository {
+	t.Helper()
+	db, err := database.NewDatabase(t.TempDir() + "/risk.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := database.NewRepository(db)
+
+	var data []models.ETFData
+	for ticker, byYear := range returns {
+		etf := models.ETFData{DateScraped: riskSession, Ticker: ticker}
+		for year, r := range byYear {
+			v := r
+			switch year {
+			case 2020:
+				etf.PriceChange2020 = &v
+			case 2021:
+				etf.PriceChange2021 = &v
+			case 2022:
+				etf.PriceChange2022 = &v
+			case 2023:
+				etf.PriceChange2023 = &v
+			case 2024:
+				etf.PriceChange2024 = &v
+			}
+		}
+		data = append(data, etf)
+	}
+	if err := repo.SaveETFs(data); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestCompletedReturns(t *testing.T) {
+	etf := models.ETFData{DateScraped: "2024-05-01 00:00:00", PriceChange2023: ptr(10), PriceChange2024: ptr(3)}
+	// Доходность 2024 года в сеансе 2024 года — с начала года, она не учитывается
+	got := CompletedReturns(etf)
+	if len(got) != 1 || got[2023] != 10 {
+		t.Errorf("CompletedReturns() = %v, want map[2023:10]", got)
+	}
+}
+
+func TestUpdateRisk(t *testing.T) {
+	repo := newRiskRepo(t, map[string]map[int]float64{
+		// 1,1 → 0,88 → 1,1: как в тесте backtest
+		"AAA": {2020: 10, 2021: -20, 2022: 25},
+		"ONE": {2024: 7},
+		"NON": {},
+	})
+
+	metrics, err := UpdateRisk(repo, riskSession)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached, err := repo.GetRiskMetrics(riskSession)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 3 || len(cached) != 3 {
+		t.Fatalf("metrics = %d, cached = %d, want 3", len(metrics), len(cached))
+	}
+
+	want := map[string]struct {
+		observations int
+		annualized   *float64
+		volatility   *float64
+		drawdown     *float64
+	}{
+		"AAA": {3, ptr(3.23), ptr(22.91), ptr(-20)},
+		"ONE": {1, ptr(7), nil, ptr(0)},
+		"NON": {0, nil, nil, nil},
+	}
+	for _, m := range cached {
+		w := want[m.Ticker]
+		if m.Observations != w.observations || len(m.Returns) != w.observations {
+			t.Errorf("%s: observations = %d (%d returns), want %d", m.Ticker, m.Observations, len(m.Returns), w.observations)
+		}
+		if !equalPtr(m.AnnualizedReturn, w.annualized) || !equalPtr(m.Volatility, w.volatility) || !equalPtr(m.MaxDrawdown, w.drawdown) {
+			t.Errorf("%s: annualized %s, volatility %s, drawdown %s; want %s, %s, %s", m.Ticker,
+				fmtPtr(m.AnnualizedReturn), fmtPtr(m.Volatility), fmtPtr(m.MaxDrawdown),
+				fmtPtr(w.annualized), fmtPtr(w.volatility), fmtPtr(w.drawdown))
+		}
+	}
+
+	risk, err := Risk(repo, []string{"AAA", "ONE", "XXX"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(risk.Funds) != 2 || len(risk.Missing) != 1 || risk.Missing[0].Ticker != "XXX" {
+		t.Fatalf("Risk() = %+v", risk)
+	}
+	aaa, one := risk.Funds[0], risk.Funds[1]
+	// (3,23 − 1) / 22,91 = 0,097
+	if aaa.Observations != 3 || !slices.Equal(aaa.Years, []int{2020, 2021, 2022}) || !equalPtr(aaa.Sharpe, ptr(0.1)) {
+		t.Errorf("AAA = %+v, sharpe %s", aaa, fmtPtr(aaa.Sharpe))
+	}
+	// Без волатильности коэффициент Шарпа не считается
+	if one.Observations != 1 || one.Sharpe != nil {
+		t.Errorf("ONE = %+v, sharpe %s", one, fmtPtr(one.Sharpe))
+	}
+}
+
+func TestCorrelation(t *testing.T) {
+	repo := newRiskRepo(t, map[string]map[int]float64{
+		"AAA": {2020: 10, 2021: -20, 2022: 25},
+		"BBB": {2020: 20, 2021: -40, 2022: 50},
+		"CCC": {2020: -10, 2021: 20, 2022: -25},
+		"TWO": {2021: 5, 2022: 6, 2023: 7},
+		"FLT": {2020: 5, 2021: 5, 2022: 5},
+		"NON": {},
+	})
+
+	tickers := []string{"AAA", "BBB", "CCC", "TWO", "FLT", "NON", "AAA", "XXX"}
+	response, err := Correlation(repo, tickers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(response.Tickers, []string{"AAA", "BBB", "CCC", "TWO", "FLT", "NON"}) {
+		t.Fatalf("tickers = %v", response.Tickers)
+	}
+	if len(response.Missing) != 1 || response.Missing[0].Ticker != "XXX" {
+		t.Errorf("missing = %+v", response.Missing)
+	}
+
+	// nil — корреляция не определена: меньше трех общих лет или постоянный ряд
+	none := (*float64)(nil)
+	wantMatrix := [][]*float64{
+		{ptr(1), ptr(1), ptr(-1), none, none, none},
+		{ptr(1), ptr(1), ptr(-1), none, none, none},
+		{ptr(-1), ptr(-1), ptr(1), none, none, none},
+		{none, none, none, ptr(1), none, none},
+		{none, none, none, none, none, none},
+		{none, none, none, none, none, none},
+	}
+	wantObservations := [][]int{
+		{3, 3, 3, 2, 3, 0},
+		{3, 3, 3, 2, 3, 0},
+		{3, 3, 3, 2, 3, 0},
+		{2, 2, 2, 3, 2, 0},
+		{3, 3, 3, 2, 3, 0},
+		{0, 0, 0, 0, 0, 0},
+	}
+	for i := range wantMatrix {
+		for j := range wantMatrix[i] {
+			if !equalPtr(response.Matrix[i][j], wantMatrix[i][j]) {
+				t.Errorf("matrix[%s][%s] = %s, want %s", response.Tickers[i], response.Tickers[j],
+					fmtPtr(response.Matrix[i][j]), fmtPtr(wantMatrix[i][j]))
+			}
+			if response.Observations[i][j] != wantObservations[i][j] {
+				t.Errorf("observations[%s][%s] = %d, want %d", response.Tickers[i], response.Tickers[j],
+					response.Observations[i][j], wantObservations[i][j])
+			}
+		}
+	}
+
+	// NaN не сериализуется в JSON: ответ должен кодироваться без ошибок
+	if _, err := json.Marshal(response); err != nil {
+		t.Errorf("json.Marshal() error = %v", err)
+	}
+}
+
+func equalPtr(a, b *float64) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
+
+func fmtPtr(v *float64) string {
+	if v == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*v)
+}
@@ -0,0 +1,256 @@
+// Package backtest рассчитывает историческую доходность модельного портфеля
+// по годовым доходностям фондов: годовые результаты, накопленный рост,
+// волатильность и максимальную просадку
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Rebalance — политика ребалансировки
+type Rebalance string
+
+const (
+	// RebalanceAnnual восстанавливает целевые веса в начале каждого года
+	RebalanceAnnual Rebalance = "annual"
+	// RebalanceNone — купить и держать: доли фондов дрейфуют вместе с их доходностью
+	RebalanceNone Rebalance = "none"
+)
+
+// Missing — обработка лет, за которые у части фондов нет данных
+type Missing string
+
+const (
+	// MissingReweight распределяет долю фондов без данных между остальными
+	// пропорционально их весам (фонд без данных «повторяет» остальной портфель)
+	MissingReweight Missing = "reweight"
+	// MissingSkip исключает такой год из расчета
+	MissingSkip Missing = "skip"
+)
+
+// Asset — фонд портфеля
+type Asset struct {
+	Ticker string
+	Weight float64
+	// Returns — доходность по годам в процентах; года без данных отсутствуют
+	Returns map[int]float64
+}
+
+// Options — параметры расчета
+type Options struct {
+	Years     []int
+	Rebalance Rebalance
+	Missing   Missing
+}
+
+// Year — результат портфеля за год
+type Year struct {
+	Year int
+	// Return — доходность портфеля в процентах; nil, если год пропущен
+	Return *float64
+	// Value — стоимость портфеля на конец года при начальной стоимости 1
+	Value float64
+	// Drawdown — просадка от предыдущего максимума в процентах (0 или отрицательная)
+	Drawdown float64
+	// MissingTickers — фонды без данных за год
+	MissingTickers []string
+	Skipped        bool
+}
+
+// Result — итог расчета
+type Result struct {
+	Years []Year
+	// Weights — нормированные целевые веса (сумма 1)
+	Weights map[string]float64
+	// CumulativeReturn — накопленная доходность за все рассчитанные годы, %
+	CumulativeReturn float64
+	// AnnualizedReturn — среднегодовая доходность, %; nil, если рассчитанных лет нет
+	AnnualizedReturn *float64
+	// Volatility — выборочное стандартное отклонение годовых доходностей, %;
+	// nil, если рассчитано меньше двух лет
+	Volatility *float64
+	// MaxDrawdown — максимальная просадка по годовым значениям, % (0 или отрицательная)
+	MaxDrawdown float64
+	// YearsCalculated — число рассчитанных лет
+	YearsCalculated int
+}
+
+// Run рассчитывает портфель по годам opts.Years в хронологическом порядке
+func Run(assets []Asset, opts Options) (*Result, error) {
+	if len(assets) == 0 {
+		return nil, errors.New("портфель пуст")
+	}
+	if opts.Rebalance == "" {
+		opts.Rebalance = RebalanceAnnual
+	}
+	if opts.Missing == "" {
+		opts.Missing = MissingReweight
+	}
+	if opts.Rebalance != RebalanceAnnual && opts.Rebalance != RebalanceNone {
+		return nil, fmt.Errorf("неизвестная политика ребалансировки %q", opts.Rebalance)
+	}
+	if opts.Missing != MissingReweight && opts.Missing != MissingSkip {
+		return nil, fmt.Errorf("неизвестный режим пропусков %q", opts.Missing)
+	}
+
+	weights, err := normalizeWeights(assets)
+	if err != nil {
+		return nil, err
+	}
+
+	years := append([]int(nil), opts.Years...)
+	sort.Ints(years)
+
+	result := &Result{Weights: weights, Years: make([]Year, 0, len(years))}
+
+	// Доли фондов в текущей стоимости портфеля
+	holdings := make(map[string]float64, len(assets))
+	for ticker, w := range weights {
+		holdings[ticker] = w
+	}
+
+	value, peak := 1.0, 1.0
+	var returns []float64
+	for _, year := range years {
+		y := Year{Year: year, Value: round(value)}
+		for _, a := range assets {
+			if _, ok := a.Returns[year]; !ok {
+				y.MissingTickers = append(y.MissingTickers, a.Ticker)
+			}
+		}
+
+		available := len(y.MissingTickers) < len(assets)
+		if !available || (opts.Missing == MissingSkip && len(y.MissingTickers) > 0) {
+			y.Skipped = true
+			y.Drawdown = round((value/peak - 1) * 100)
+			result.Years = append(result.Years, y)
+			continue
+		}
+
+		if opts.Rebalance == RebalanceAnnual {
+			for ticker, w := range weights {
+				holdings[ticker] = w
+			}
+		}
+		r := yearReturn(assets, holdings, year)
+		for _, a := range assets {
+			ar, ok := a.Returns[year]
+			if !ok {
+				ar = r * 100 // фонд без данных повторяет остальной портфель
+			}
+			holdings[a.Ticker] *= 1 + ar/100
+		}
+		normalize(holdings)
+
+		value *= 1 + r
+		if value > peak {
+			peak = value
+		}
+		drawdown := (value/peak - 1) * 100
+		if drawdown < result.MaxDrawdown {
+			result.MaxDrawdown = drawdown
+		}
+
+		pct := round(r * 100)
+		y.Return = &pct
+		y.Value = round(value)
+		y.Drawdown = round(drawdown)
+		result.Years = append(result.Years, y)
+		returns = append(returns, r*100)
+	}
+
+	result.YearsCalculated = len(returns)
+	result.CumulativeReturn = round((value - 1) * 100)
+	result.MaxDrawdown = round(result.MaxDrawdown)
+	if n := len(returns); n > 0 {
+		annualized := round((math.Pow(value, 1/float64(n)) - 1) * 100)
+		result.AnnualizedReturn = &annualized
+	}
+	if vol, ok := stdev(returns); ok {
+		vol = round(vol)
+		result.Volatility = &vol
+	}
+
+	return result, nil
+}
+
+// yearReturn возвращает доходность портфеля за год (в долях) по текущим долям
+// фондов; фонды без данных исключаются, их доля распределяется между остальными
+func yearReturn(assets []Asset, holdings map[string]float64, year int) float64 {
+	var sum, base float64
+	for _, a := range assets {
+		r, ok := a.Returns[year]
+		if !ok {
+			continue
+		}
+		sum += holdings[a.Ticker] * r / 100
+		base += holdings[a.Ticker]
+	}
+	if base == 0 {
+		return 0
+	}
+	return sum / base
+}
+
+// normalizeWeights проверяет веса и приводит их к сумме 1
+func normalizeWeights(assets []Asset) (map[string]float64, error) {
+	weights := make(map[string]float64, len(assets))
+	total := 0.0
+	for _, a := range assets {
+		if a.Weight < 0 || math.IsNaN(a.Weight) || math.IsInf(a.Weight, 0) {
+			return nil, fmt.Errorf("вес %s должен быть неотрицательным числом", a.Ticker)
+		}
+		if _, dup := weights[a.Ticker]; dup {
+			return nil, fmt.Errorf("тикер %s указан дважды", a.Ticker)
+		}
+		weights[a.Ticker] = a.Weight
+		total += a.Weight
+	}
+	if total == 0 {
+		return nil, errors.New("сумма весов должна быть больше нуля")
+	}
+
+	normalize(weights)
+	return weights, nil
+}
+
+// normalize приводит доли к сумме 1
+func normalize(shares map[string]float64) {
+	total := 0.0
+	for _, v := range shares {
+		total += v
+	}
+	if total == 0 {
+		return
+	}
+	for k, v := range shares {
+		shares[k] = v / total
+	}
+}
+
+// stdev возвращает выборочное стандартное отклонение
+func stdev(values []float64) (float64, bool) {
+	n := len(values)
+	if n < 2 {
+		return 0, false
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(n)
+
+	sq := 0.0
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sq / float64(n-1)), true
+}
+
+// round округляет до четырех знаков после запятой
+func round(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
@@ -0,0 +1,255 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+// wantYear — ожидаемый результат года; Return = NaN означает пропущенный год
+type wantYear struct {
+	Year     int
+	Return   float64
+	Value    float64
+	Drawdown float64
+	Missing  []string
+}
+
+func checkYears(t *testing.T, got []Year, want []wantYear) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("years = %d, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Year != w.Year {
+			t.Errorf("year[%d] = %d, want %d", i, g.Year, w.Year)
+		}
+		if math.IsNaN(w.Return) {
+			if !g.Skipped || g.Return != nil {
+				t.Errorf("%d: expected a skipped year, got return %v", w.Year, g.Return)
+			}
+		} else if g.Skipped || g.Return == nil || !near(*g.Return, w.Return) {
+			t.Errorf("%d: return = %v, want %v", w.Year, fmtPtr(g.Return), w.Return)
+		}
+		if !near(g.Value, w.Value) {
+			t.Errorf("%d: value = %v, want %v", w.Year, g.Value, w.Value)
+		}
+		if !near(g.Drawdown, w.Drawdown) {
+			t.Errorf("%d: drawdown = %v, want %v", w.Year, g.Drawdown, w.Drawdown)
+		}
+		if !slices.Equal(g.MissingTickers, w.Missing) {
+			t.Errorf("%d: missing = %v, want %v", w.Year, g.MissingTickers, w.Missing)
+		}
+	}
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-4 }
+
+func fmtPtr(v *float64) string {
+	if v == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*v)
+}
+
+var skipped = math.NaN()
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		assets []Asset
+		opts   Options
+		years  []wantYear
+
+		cumulative  float64
+		annualized  float64
+		volatility  float64 // NaN — волатильность не рассчитывается
+		maxDrawdown float64
+		calculated  int
+	}{
+		{
+			// 1,1 → 0,88 → 1,1: просадка −20% от пика 1,1 и полное восстановление
+			name:   "single asset",
+			assets: []Asset{{Ticker: "A", Weight: 3, Returns: map[int]float64{2020: 10, 2021: -20, 2022: 25}}},
+			opts:   Options{Years: []int{2022, 2020, 2021}},
+			years: []wantYear{
+				{2020, 10, 1.1, 0, nil},
+				{2021, -20, 0.88, -20, nil},
+				{2022, 25, 1.1, 0, nil},
+			},
+			cumulative: 10, annualized: 3.228, volatility: 22.9129, maxDrawdown: -20, calculated: 3,
+		},
+		{
+			// 2020: 0,5×10 + 0,5×(−10) = 0; 2021 после ребалансировки: 0,5×10 + 0,5×30 = 20
+			name: "two assets annual rebalance",
+			assets: []Asset{
+				{Ticker: "A", Weight: 1, Returns: map[int]float64{2020: 10, 2021: 10}},
+				{Ticker: "B", Weight: 1, Returns: map[int]float64{2020: -10, 2021: 30}},
+			},
+			opts: Options{Years: []int{2020, 2021}, Rebalance: RebalanceAnnual},
+			years: []wantYear{
+				{2020, 0, 1, 0, nil},
+				{2021, 20, 1.2, 0, nil},
+			},
+			cumulative: 20, annualized: 9.5445, volatility: 14.1421, maxDrawdown: 0, calculated: 2,
+		},
+		{
+			// Без ребалансировки доли дрейфуют: после 2020 A = 0,55, B = 0,45,
+			// в 2021: 0,55×10 + 0,45×30 = 19
+			name: "two assets buy and hold",
+			assets: []Asset{
+				{Ticker: "A", Weight: 1, Returns: map[int]float64{2020: 10, 2021: 10}},
+				{Ticker: "B", Weight: 1, Returns: map[int]float64{2020: -10, 2021: 30}},
+			},
+			opts: Options{Years: []int{2020, 2021}, Rebalance: RebalanceNone},
+			years: []wantYear{
+				{2020, 0, 1, 0, nil},
+				{2021, 19, 1.19, 0, nil},
+			},
+			cumulative: 19, annualized: 9.0871, volatility: 13.435, maxDrawdown: 0, calculated: 2,
+		},
+		{
+			// Веса 3:1 → 0,75/0,25: 0,75×(−20) + 0,25×20 = −10, затем 0,75×0 + 0,25×40 = 10
+			name: "weights are normalized",
+			assets: []Asset{
+				{Ticker: "A", Weight: 75, Returns: map[int]float64{2020: -20, 2021: 0}},
+				{Ticker: "B", Weight: 25, Returns: map[int]float64{2020: 20, 2021: 40}},
+			},
+			opts: Options{Years: []int{2020, 2021}},
+			years: []wantYear{
+				{2020, -10, 0.9, -10, nil},
+				{2021, 10, 0.99, -1, nil},
+			},
+			cumulative: -1, annualized: -0.5013, volatility: 14.1421, maxDrawdown: -10, calculated: 2,
+		},
+		{
+			// История B начинается в 2021: в 2020 его доля повторяет A, и портфель растет на 10%
+			name: "asset starts partway reweight",
+			assets: []Asset{
+				{Ticker: "A", Weight: 1, Returns: map[int]float64{2020: 10, 2021: 20, 2022: 10}},
+				{Ticker: "B", Weight: 1, Returns: map[int]float64{2021: 0, 2022: 10}},
+			},
+			opts: Options{Years: []int{2019, 2020, 2021, 2022}},
+			years: []wantYear{
+				{2019, skipped, 1, 0, []string{"A", "B"}},
+				{2020, 10, 1.1, 0, []string{"B"}},
+				{2021, 10, 1.21, 0, nil},
+				{2022, 10, 1.331, 0, nil},
+			},
+			cumulative: 33.1, annualized: 10, volatility: 0, maxDrawdown: 0, calculated: 3,
+		},
+		{
+			name: "asset starts partway skip",
+			assets: []Asset{
+				{Ticker: "A", Weight: 1, Returns: map[int]float64{2020: 10, 2021: 20, 2022: 10}},
+				{Ticker: "B", Weight: 1, Returns: map[int]float64{2021: 0, 2022: 10}},
+			},
+			opts: Options{Years: []int{2020, 2021, 2022}, Missing: MissingSkip},
+			years: []wantYear{
+				{2020, skipped, 1, 0, []string{"B"}},
+				{2021, 10, 1.1, 0, nil},
+				{2022, 10, 1.21, 0, nil},
+			},
+			cumulative: 21, annualized: 10, volatility: 0, maxDrawdown: 0, calculated: 2,
+		},
+		{
+			// Пропущенный год сохраняет просадку от прежнего пика
+			name: "skipped year keeps drawdown",
+			assets: []Asset{
+				{Ticker: "A", Weight: 1, Returns: map[int]float64{2020: 20, 2021: -50, 2023: 50}},
+			},
+			opts: Options{Years: []int{2020, 2021, 2022, 2023}},
+			years: []wantYear{
+				{2020, 20, 1.2, 0, nil},
+				{2021, -50, 0.6, -50, nil},
+				{2022, skipped, 0.6, -50, []string{"A"}},
+				{2023, 50, 0.9, -25, nil},
+			},
+			cumulative: -10, annualized: -3.4511, volatility: 51.316, maxDrawdown: -50, calculated: 3,
+		},
+		{
+			name:       "single year has no volatility",
+			assets:     []Asset{{Ticker: "A", Weight: 1, Returns: map[int]float64{2024: 5}}},
+			opts:       Options{Years: []int{2024}},
+			years:      []wantYear{{2024, 5, 1.05, 0, nil}},
+			cumulative: 5, annualized: 5, volatility: math.NaN(), maxDrawdown: 0, calculated: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Run(tt.assets, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkYears(t, result.Years, tt.years)
+
+			if !near(result.CumulativeReturn, tt.cumulative) {
+				t.Errorf("cumulative = %v, want %v", result.CumulativeReturn, tt.cumulative)
+			}
+			if result.AnnualizedReturn == nil || !near(*result.AnnualizedReturn, tt.annualized) {
+				t.Errorf("annualized = %s, want %v", fmtPtr(result.AnnualizedReturn), tt.annualized)
+			}
+			if math.IsNaN(tt.volatility) {
+				if result.Volatility != nil {
+					t.Errorf("volatility = %v, want nil", *result.Volatility)
+				}
+			} else if result.Volatility == nil || !near(*result.Volatility, tt.volatility) {
+				t.Errorf("volatility = %s, want %v", fmtPtr(result.Volatility), tt.volatility)
+			}
+			if !near(result.MaxDrawdown, tt.maxDrawdown) {
+				t.Errorf("max drawdown = %v, want %v", result.MaxDrawdown, tt.maxDrawdown)
+			}
+			if result.YearsCalculated != tt.calculated {
+				t.Errorf("years calculated = %d, want %d", result.YearsCalculated, tt.calculated)
+			}
+		})
+	}
+}
+
+func TestRunWeights(t *testing.T) {
+	result, err := Run([]Asset{
+		{Ticker: "A", Weight: 3, Returns: map[int]float64{2020: 0}},
+		{Ticker: "B", Weight: 1, Returns: map[int]float64{2020: 0}},
+	}, Options{Years: []int{2020}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(result.Weights["A"], 0.75) || !near(result.Weights["B"], 0.25) {
+		t.Errorf("weights = %v, want A 0.75, B 0.25", result.Weights)
+	}
+}
+
+func TestRunNoYearsCalculated(t *testing.T) {
+	result, err := Run([]Asset{{Ticker: "A", Weight: 1, Returns: map[int]float64{}}}, Options{Years: []int{2020}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AnnualizedReturn != nil || result.Volatility != nil || result.YearsCalculated != 0 || result.CumulativeReturn != 0 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	valid := []Asset{{Ticker: "A", Weight: 1}}
+	tests := []struct {
+		name   string
+		assets []Asset
+		opts   Options
+	}{
+		{"empty portfolio", nil, Options{}},
+		{"negative weight", []Asset{{Ticker: "A", Weight: -1}}, Options{}},
+		{"NaN weight", []Asset{{Ticker: "A", Weight: math.NaN()}}, Options{}},
+		{"zero total", []Asset{{Ticker: "A"}, {Ticker: "B"}}, Options{}},
+		{"duplicate ticker", []Asset{{Ticker: "A", Weight: 1}, {Ticker: "A", Weight: 1}}, Options{}},
+		{"unknown rebalance", valid, Options{Rebalance: "monthly"}},
+		{"unknown missing", valid, Options{Missing: "zero"}},
+	}
+	for _, tt := range tests {
+		if _, err := Run(tt.assets, tt.opts); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
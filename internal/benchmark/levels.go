@@ -0,0 +1,167 @@
+// Package benchmark хранит значения индексов-бенчмарков, привязывает к ним фонды
+// и считает отклонение доходности фонда от индекса (tracking difference)
+// и ошибку слежения (tracking error)
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"etf-scraper/internal/csvread"
+	"etf-scraper/internal/dateparse"
+	"etf-scraper/internal/models"
+	"etf-scraper/internal/numparse"
+)
+
+// fetchTimeout ограничивает загрузку значений индекса по HTTP
+const fetchTimeout = 30 * time.Second
+
+// NormalizeCode приводит код бенчмарка к верхнему регистру без пробелов по краям
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ParseCSV читает значения индекса из CSV с колонками «дата, значение».
+// Строка заголовка необязательна, разделитель — запятая, точка с запятой или табуляция.
+func ParseCSV(r io.Reader) ([]models.BenchmarkLevel, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csvread.ReadAll(content)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения CSV: %w", err)
+	}
+
+	var levels []models.BenchmarkLevel
+	for i, record := range records {
+		row := i + 1
+		if len(record) < 2 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		level, err := parseLevel(record[0], record[1])
+		if err != nil {
+			if row == 1 {
+				continue // заголовок
+			}
+			return nil, fmt.Errorf("строка %d: %w", row, err)
+		}
+		levels = append(levels, level)
+	}
+
+	return finish(levels)
+}
+
+// jsonLevel — значение индекса в JSON: [{"date": "2024-12-30", "level": 1234.5}, ...]
+type jsonLevel struct {
+	Date  string  `json:"date"`
+	Level float64 `json:"level"`
+}
+
+// ParseJSON читает значения индекса из JSON-массива объектов с полями date и level
+func ParseJSON(r io.Reader) ([]models.BenchmarkLevel, error) {
+	var items []jsonLevel
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON: %w", err)
+	}
+
+	levels := make([]models.BenchmarkLevel, 0, len(items))
+	for i, item := range items {
+		date, err := parseDate(item.Date)
+		if err != nil {
+			return nil, fmt.Errorf("элемент %d: %w", i+1, err)
+		}
+		if item.Level <= 0 {
+			return nil, fmt.Errorf("элемент %d: значение индекса должно быть положительным", i+1)
+		}
+		levels = append(levels, models.BenchmarkLevel{Date: date, Level: item.Level})
+	}
+
+	return finish(levels)
+}
+
+// Fetch загружает значения индекса из JSON API в формате ParseJSON
+func Fetch(url string) ([]models.BenchmarkLevel, error) {
+	client := &http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ошибка загрузки %s: HTTP %d", url, resp.StatusCode)
+	}
+	return ParseJSON(resp.Body)
+}
+
+// Load читает значения индекса из источника: URL JSON API, файла .json или CSV
+func Load(source string) ([]models.BenchmarkLevel, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return Fetch(source)
+	}
+
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(source), ".json") {
+		return ParseJSON(f)
+	}
+	return ParseCSV(f)
+}
+
+func parseLevel(dateText, levelText string) (models.BenchmarkLevel, error) {
+	date, err := parseDate(dateText)
+	if err != nil {
+		return models.BenchmarkLevel{}, err
+	}
+	value, err := numparse.Parse(levelText)
+	if err != nil {
+		return models.BenchmarkLevel{}, fmt.Errorf("неверное значение индекса %q: %w", levelText, err)
+	}
+	if value.Float() <= 0 {
+		return models.BenchmarkLevel{}, fmt.Errorf("значение индекса должно быть положительным: %q", levelText)
+	}
+	return models.BenchmarkLevel{Date: date, Level: value.Float()}, nil
+}
+
+func parseDate(text string) (string, error) {
+	d, err := dateparse.Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("неверная дата %q: %w", text, err)
+	}
+	if d.Precision != dateparse.PrecisionDay {
+		return "", fmt.Errorf("дата должна содержать день: %q", text)
+	}
+	return d.ISO(), nil
+}
+
+// finish упорядочивает значения по дате; при повторе даты остается последнее
+func finish(levels []models.BenchmarkLevel) ([]models.BenchmarkLevel, error) {
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("нет значений индекса")
+	}
+
+	byDate := make(map[string]float64, len(levels))
+	for _, l := range levels {
+		byDate[l.Date] = l.Level
+	}
+
+	result := make([]models.BenchmarkLevel, 0, len(byDate))
+	for date, level := range byDate {
+		result = append(result, models.BenchmarkLevel{Date: date, Level: level})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result, nil
+}
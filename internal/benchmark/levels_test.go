@@ -0,0 +1,65 @@
+package benchmark
+
+import (
+	"strings"
+	"testing"
+
+	"etf-scraper/internal/models"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []models.BenchmarkLevel
+	}{
+		{
+			name:    "header and semicolon",
+			content: "\ufeffДата;Значение\n30.12.2022;2 154,12\n29.12.2023;3 099,11\n",
+			want:    []models.BenchmarkLevel{{Date: "2022-12-30", Level: 2154.12}, {Date: "2023-12-29", Level: 3099.11}},
+		},
+		{
+			// Порядок строк не важен, при повторе даты остается последнее значение
+			name:    "unsorted with duplicate",
+			content: "2023-12-29,3100\n2022-12-30,2154.12\n2023-12-29,3099.11\n",
+			want:    []models.BenchmarkLevel{{Date: "2022-12-30", Level: 2154.12}, {Date: "2023-12-29", Level: 3099.11}},
+		},
+		{
+			name:    "tab and blank lines",
+			content: "date\tlevel\n\n2023-12-29\t3099.11\n",
+			want:    []models.BenchmarkLevel{{Date: "2023-12-29", Level: 3099.11}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCSV(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseCSV() = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("level %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "",
+		"header only":    "date,level\n",
+		"bad value":      "date,level\n2023-12-29,n/a\n",
+		"negative value": "2023-12-29,-1\n",
+		"month only":     "date,level\n2023-12,3099\n",
+	}
+	for name, content := range tests {
+		if _, err := ParseCSV(strings.NewReader(content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
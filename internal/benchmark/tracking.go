@@ -0,0 +1,400 @@
+package benchmark
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"etf-scraper/internal/analytics"
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+// Способы привязки фонда к бенчмарку
+const (
+	LinkManual      = "manual"       // задана администратором
+	LinkTargetIndex = "target_index" // целевой индекс фонда совпал с названием бенчмарка
+)
+
+// YearlyReturns считает доходность индекса за календарные годы в процентах: последнее
+// значение года к последнему значению предыдущего. Год учитывается, только если
+// у него и у предыдущего года есть значения в декабре.
+func YearlyReturns(levels []models.BenchmarkLevel) map[int]float64 {
+	last := make(map[int]models.BenchmarkLevel)
+	for _, l := range levels {
+		if len(l.Date) < 4 {
+			continue
+		}
+		year, err := strconv.Atoi(l.Date[:4])
+		if err != nil {
+			continue
+		}
+		if cur, ok := last[year]; !ok || l.Date > cur.Date {
+			last[year] = l
+		}
+	}
+
+	returns := make(map[int]float64)
+	for year, end := range last {
+		start, ok := last[year-1]
+		if !ok || !isDecember(start.Date) || !isDecember(end.Date) {
+			continue
+		}
+		returns[year] = (end.Level/start.Level - 1) * 100
+	}
+	return returns
+}
+
+func isDecember(date string) bool {
+	return len(date) >= 7 && date[5:7] == "12"
+}
+
+// Tracking сравнивает годовые доходности фонда и индекса (в процентах) за годы
+// from..to (0 — без ограничения). Показатели периода считаются по годам, где
+// известны обе доходности.
+func Tracking(fund, index map[int]float64, from, to int) ([]models.TrackingYear, models.TrackingPeriod) {
+	yearSet := make(map[int]bool)
+	for year := range fund {
+		yearSet[year] = true
+	}
+	for year := range index {
+		yearSet[year] = true
+	}
+
+	var years []int
+	for year := range yearSet {
+		if (from == 0 || year >= from) && (to == 0 || year <= to) {
+			years = append(years, year)
+		}
+	}
+	sort.Ints(years)
+
+	result := make([]models.TrackingYear, 0, len(years))
+	period := models.TrackingPeriod{From: from, To: to}
+	fundGrowth, indexGrowth := 1.0, 1.0
+	var differences []float64
+	for _, year := range years {
+		y := models.TrackingYear{Year: year}
+		f, okFund := fund[year]
+		i, okIndex := index[year]
+		if okFund {
+			y.FundReturn = roundPtr(f)
+		}
+		if okIndex {
+			y.IndexReturn = roundPtr(i)
+		}
+		if okFund && okIndex {
+			y.TrackingDifference = roundPtr(f - i)
+			fundGrowth *= 1 + f/100
+			indexGrowth *= 1 + i/100
+			differences = append(differences, f-i)
+		}
+		result = append(result, y)
+	}
+
+	n := len(differences)
+	period.Years = n
+	if n == 0 {
+		return result, period
+	}
+
+	fundAnnual := (math.Pow(fundGrowth, 1/float64(n)) - 1) * 100
+	indexAnnual := (math.Pow(indexGrowth, 1/float64(n)) - 1) * 100
+	period.FundReturn = roundPtr(fundAnnual)
+	period.IndexReturn = roundPtr(indexAnnual)
+	period.TrackingDifference = roundPtr(fundAnnual - indexAnnual)
+
+	avg := 0.0
+	for _, d := range differences {
+		avg += d / float64(n)
+	}
+	period.AverageDifference = roundPtr(avg)
+
+	if n > 1 {
+		sq := 0.0
+		for _, d := range differences {
+			sq += (d - avg) * (d - avg)
+		}
+		period.TrackingError = roundPtr(math.Sqrt(sq / float64(n-1)))
+	}
+
+	return result, period
+}
+
+// Resolver определяет бенчмарк фонда: сначала по привязке администратора,
+// затем по совпадению целевого индекса фонда с названием бенчмарка (с учетом синонимов)
+type Resolver struct {
+	manual     map[string]string
+	byIndex    map[string]string
+	normalizer *analytics.IndexNormalizer
+}
+
+// NewResolver загружает бенчмарки и привязки фондов из БД
+func NewResolver(repo *database.Repository) (*Resolver, error) {
+	normalizer, err := analytics.NewIndexNormalizer(repo)
+	if err != nil {
+		return nil, err
+	}
+	benchmarks, err := repo.GetBenchmarks()
+	if err != nil {
+		return nil, err
+	}
+	links, err := repo.GetFundBenchmarks()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Resolver{
+		manual:     make(map[string]string, len(links)),
+		byIndex:    make(map[string]string, len(benchmarks)),
+		normalizer: normalizer,
+	}
+	for _, l := range links {
+		r.manual[l.Ticker] = l.Code
+	}
+	for _, b := range benchmarks {
+		key := normalizer.Normalize(b.Name)
+		if _, ok := r.byIndex[key]; !ok && key != "" {
+			r.byIndex[key] = b.Code
+		}
+	}
+	return r, nil
+}
+
+// Resolve возвращает код бенчмарка фонда и способ привязки; пустой код — бенчмарка нет
+func (r *Resolver) Resolve(etf models.ETFData) (string, string) {
+	if code, ok := r.manual[etf.Ticker]; ok {
+		return code, LinkManual
+	}
+	if key := r.normalizer.Normalize(etf.TargetIndex); key != "" {
+		if code, ok := r.byIndex[key]; ok {
+			return code, LinkTargetIndex
+		}
+	}
+	return "", ""
+}
+
+// FundTracking сравнивает фонд с его бенчмарком по последним данным фонда.
+// Возвращает nil, если фонда нет в БД; Benchmark пуст, если фонд не привязан.
+func FundTracking(repo *database.Repository, ticker string, from, to int) (*models.TrackingResponse, error) {
+	latest, err := repo.GetLatestETFs(ticker)
+	if err != nil {
+		return nil, err
+	}
+	if len(latest) == 0 {
+		return nil, nil
+	}
+	etf := latest[0]
+
+	resolver, err := NewResolver(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.TrackingResponse{
+		Ticker:      etf.Ticker,
+		FundName:    etf.FundName,
+		TargetIndex: etf.TargetIndex,
+		Years:       []models.TrackingYear{},
+		Period:      models.TrackingPeriod{From: from, To: to},
+	}
+
+	code, link := resolver.Resolve(etf)
+	if code == "" {
+		return response, nil
+	}
+	b, err := repo.GetBenchmark(code)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return response, nil
+	}
+
+	levels, err := repo.GetBenchmarkLevels(code)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Benchmark = b.Code
+	response.BenchmarkName = b.Name
+	response.Link = link
+	response.Years, response.Period = Tracking(analytics.CompletedReturns(etf), YearlyReturns(levels), from, to)
+	return response, nil
+}
+
+// League строит таблицу фондов последнего сеанса, привязанных к бенчмарку: по убыванию
+// среднегодового отклонения от индекса, при равенстве — по возрастанию ошибки слежения.
+// Возвращает nil, если бенчмарка нет.
+func League(repo *database.Repository, code string, from, to int) (*models.LeagueResponse, error) {
+	b, err := repo.GetBenchmark(code)
+	if err != nil || b == nil {
+		return nil, err
+	}
+
+	levels, err := repo.GetBenchmarkLevels(code)
+	if err != nil {
+		return nil, err
+	}
+	index := YearlyReturns(levels)
+
+	resolver, err := NewResolver(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := repo.GetLatestSession("")
+	if err != nil {
+		return nil, err
+	}
+	data, err := repo.GetETFsBySession(session)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.LeagueResponse{
+		Benchmark:   b.Code,
+		Name:        b.Name,
+		DateScraped: session,
+		From:        from,
+		To:          to,
+		Funds:       []models.LeagueEntry{},
+	}
+
+	seen := make(map[string]bool, len(data))
+	for _, etf := range data {
+		if seen[etf.Ticker] {
+			continue
+		}
+		seen[etf.Ticker] = true
+
+		fundCode, link := resolver.Resolve(etf)
+		if fundCode != b.Code {
+			continue
+		}
+
+		_, period := Tracking(analytics.CompletedReturns(etf), index, from, to)
+		response.Funds = append(response.Funds, models.LeagueEntry{
+			Ticker:         etf.Ticker,
+			FundName:       etf.FundName,
+			ManagementCo:   etf.ManagementCo,
+			Link:           link,
+			TERPercent:     etf.TERPercent,
+			NAVMillionRub:  etf.NAVMillionRub,
+			TrackingPeriod: period,
+		})
+	}
+
+	rankLeague(response.Funds)
+	return response, nil
+}
+
+// rankLeague упорядочивает таблицу и проставляет места; фонды без общих с индексом
+// лет оказываются в конце
+func rankLeague(funds []models.LeagueEntry) {
+	sort.SliceStable(funds, func(i, j int) bool {
+		a, b := funds[i], funds[j]
+		if c := compareNullable(a.TrackingDifference, b.TrackingDifference, true); c != 0 {
+			return c < 0
+		}
+		if c := compareNullable(a.TrackingError, b.TrackingError, false); c != 0 {
+			return c < 0
+		}
+		return a.Ticker < b.Ticker
+	})
+	for i := range funds {
+		funds[i].Rank = i + 1
+	}
+}
+
+// compareNullable сравнивает значения по возрастанию (desc — по убыванию), nil — в конце:
+// -1, если a идет раньше b
+func compareNullable(a, b *float64, desc bool) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	case *a == *b:
+		return 0
+	case (*a < *b) != desc:
+		return -1
+	}
+	return 1
+}
+
+// roundPtr округляет проценты до сотых
+func roundPtr(v float64) *float64 {
+	r := math.Round(v*100) / 100
+	return &r
+}
+
+// List возвращает бенчмарки с охватом значений и фондами последнего сеанса,
+// привязанными к каждому из них
+func List(repo *database.Repository) ([]models.BenchmarkResponse, error) {
+	benchmarks, err := repo.GetBenchmarks()
+	if err != nil {
+		return nil, err
+	}
+
+	funds, err := linkedFunds(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.BenchmarkResponse, 0, len(benchmarks))
+	for _, b := range benchmarks {
+		levels, err := repo.GetBenchmarkLevels(b.Code)
+		if err != nil {
+			return nil, err
+		}
+
+		item := models.BenchmarkResponse{
+			Code:      b.Code,
+			Name:      b.Name,
+			Levels:    len(levels),
+			Funds:     funds[b.Code],
+			CreatedAt: b.CreatedAt,
+		}
+		if len(levels) > 0 {
+			item.FirstDate = levels[0].Date
+			item.LastDate = levels[len(levels)-1].Date
+		}
+		if item.Funds == nil {
+			item.Funds = []string{}
+		}
+		response = append(response, item)
+	}
+	return response, nil
+}
+
+// linkedFunds группирует тикеры последнего сеанса по кодам бенчмарков
+func linkedFunds(repo *database.Repository) (map[string][]string, error) {
+	resolver, err := NewResolver(repo)
+	if err != nil {
+		return nil, err
+	}
+	session, err := repo.GetLatestSession("")
+	if err != nil {
+		return nil, err
+	}
+	data, err := repo.GetETFsBySession(session)
+	if err != nil {
+		return nil, err
+	}
+
+	funds := make(map[string][]string)
+	seen := make(map[string]bool, len(data))
+	for _, etf := range data {
+		if seen[etf.Ticker] {
+			continue
+		}
+		seen[etf.Ticker] = true
+		if code, _ := resolver.Resolve(etf); code != "" {
+			funds[code] = append(funds[code], etf.Ticker)
+		}
+	}
+	return funds, nil
+}
@@ -0,0 +1,175 @@
+package benchmark
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"etf-scraper/internal/models"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func fmtPtr(v *float64) string {
+	if v == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*v)
+}
+
+func equalPtr(a, b *float64) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
+
+func TestYearlyReturns(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels []models.BenchmarkLevel
+		want   map[int]float64
+	}{
+		{
+			// Конец года — последнее значение года, а не последнее в списке
+			name: "year end level",
+			levels: []models.BenchmarkLevel{
+				{Date: "2023-12-29", Level: 121},
+				{Date: "2022-12-30", Level: 110},
+				{Date: "2023-06-30", Level: 90},
+				{Date: "2022-12-29", Level: 100},
+				{Date: "2024-12-30", Level: 108.9},
+			},
+			want: map[int]float64{2023: 10, 2024: -10},
+		},
+		{
+			// Без значений за 2021 год не считаются ни 2021, ни 2022
+			name: "gap in years",
+			levels: []models.BenchmarkLevel{
+				{Date: "2020-12-30", Level: 100},
+				{Date: "2022-12-30", Level: 150},
+				{Date: "2023-12-29", Level: 165},
+			},
+			want: map[int]float64{2023: 10},
+		},
+		{
+			// Незавершенный год и год, закончившийся в ноябре, не учитываются
+			name: "year without December",
+			levels: []models.BenchmarkLevel{
+				{Date: "2021-12-30", Level: 100},
+				{Date: "2022-11-30", Level: 120},
+				{Date: "2023-12-29", Level: 130},
+				{Date: "2024-05-15", Level: 140},
+			},
+			want: map[int]float64{},
+		},
+		{
+			name:   "no levels",
+			levels: nil,
+			want:   map[int]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := YearlyReturns(tt.levels)
+			if len(got) != len(tt.want) {
+				t.Fatalf("YearlyReturns() = %v, want %v", got, tt.want)
+			}
+			for year, want := range tt.want {
+				if r, ok := got[year]; !ok || math.Abs(r-want) > 1e-9 {
+					t.Errorf("%d: return = %v, want %v", year, r, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTracking(t *testing.T) {
+	fund := map[int]float64{2021: 10, 2022: -5, 2023: 20}
+	index := map[int]float64{2022: -4, 2023: 18, 2024: 7}
+
+	tests := []struct {
+		name     string
+		from, to int
+		years    []models.TrackingYear
+		period   models.TrackingPeriod
+	}{
+		{
+			// Общие годы — 2022 и 2023: разницы −1 и 2, среднегодовые
+			// √(0,95 × 1,2) − 1 = 6,77% и √(0,96 × 1,18) − 1 = 6,43%
+			name: "all years",
+			years: []models.TrackingYear{
+				{Year: 2021, FundReturn: ptr(10)},
+				{Year: 2022, FundReturn: ptr(-5), IndexReturn: ptr(-4), TrackingDifference: ptr(-1)},
+				{Year: 2023, FundReturn: ptr(20), IndexReturn: ptr(18), TrackingDifference: ptr(2)},
+				{Year: 2024, IndexReturn: ptr(7)},
+			},
+			period: models.TrackingPeriod{
+				Years: 2, FundReturn: ptr(6.77), IndexReturn: ptr(6.43), TrackingDifference: ptr(0.34),
+				AverageDifference: ptr(0.5), TrackingError: ptr(2.12),
+			},
+		},
+		{
+			// Один общий год: ошибка слежения не считается
+			name: "single common year",
+			from: 2023, to: 2024,
+			years: []models.TrackingYear{
+				{Year: 2023, FundReturn: ptr(20), IndexReturn: ptr(18), TrackingDifference: ptr(2)},
+				{Year: 2024, IndexReturn: ptr(7)},
+			},
+			period: models.TrackingPeriod{
+				From: 2023, To: 2024, Years: 1, FundReturn: ptr(20), IndexReturn: ptr(18),
+				TrackingDifference: ptr(2), AverageDifference: ptr(2),
+			},
+		},
+		{
+			name:   "range after data",
+			from:   2030,
+			years:  []models.TrackingYear{},
+			period: models.TrackingPeriod{From: 2030},
+		},
+		{
+			name: "range before data",
+			from: 2010, to: 2015,
+			years:  []models.TrackingYear{},
+			period: models.TrackingPeriod{From: 2010, To: 2015},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			years, period := Tracking(fund, index, tt.from, tt.to)
+
+			if len(years) != len(tt.years) {
+				t.Fatalf("years = %+v, want %+v", years, tt.years)
+			}
+			for i, w := range tt.years {
+				g := years[i]
+				if g.Year != w.Year || !equalPtr(g.FundReturn, w.FundReturn) ||
+					!equalPtr(g.IndexReturn, w.IndexReturn) || !equalPtr(g.TrackingDifference, w.TrackingDifference) {
+					t.Errorf("year %d = %d %s %s %s, want %d %s %s %s", i,
+						g.Year, fmtPtr(g.FundReturn), fmtPtr(g.IndexReturn), fmtPtr(g.TrackingDifference),
+						w.Year, fmtPtr(w.FundReturn), fmtPtr(w.IndexReturn), fmtPtr(w.TrackingDifference))
+				}
+			}
+
+			w := tt.period
+			if period.From != w.From || period.To != w.To || period.Years != w.Years {
+				t.Errorf("period = %d..%d (%d years), want %d..%d (%d years)",
+					period.From, period.To, period.Years, w.From, w.To, w.Years)
+			}
+			for _, f := range []struct {
+				name      string
+				got, want *float64
+			}{
+				{"fund return", period.FundReturn, w.FundReturn},
+				{"index return", period.IndexReturn, w.IndexReturn},
+				{"tracking difference", period.TrackingDifference, w.TrackingDifference},
+				{"average difference", period.AverageDifference, w.AverageDifference},
+				{"tracking error", period.TrackingError, w.TrackingError},
+			} {
+				if !equalPtr(f.got, f.want) {
+					t.Errorf("%s = %s, want %s", f.name, fmtPtr(f.got), fmtPtr(f.want))
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -11,6 +12,16 @@ type Config struct {
 	AdminPort       string
 	ScraperURL      string
 	Verbose         bool
+	HTTPCache       bool
+	MarkersPath     string
+	DumpDir         string
+	NAVThresholds   []float64
+	SMTPAddr        string
+	SMTPFrom        string
+	SMTPUsername    string
+	SMTPPassword    string
+	AlertSecret     string
+	RiskFreeRate    float64
 	StaticDir       string
 	CACertPath      string
 	ServerCertPath  string
@@ -34,6 +45,16 @@ func NewConfig() *Config {
 		AdminPort:       getEnv("ADMIN_PORT", "8443"),
 		ScraperURL:      getEnv("SCRAPER_URL", "https://assetallocation.ru/etf/"),
 		Verbose:         getEnv("VERBOSE", "false") == "true",
+		HTTPCache:       getEnv("HTTP_CACHE", "true") == "true",
+		MarkersPath:     getEnv("MARKERS_PATH", ""),
+		DumpDir:         getEnv("DUMP_DIR", "./dumps"),
+		NAVThresholds:   parseFloatList(getEnv("NAV_THRESHOLDS", "1000,10000,100000")),
+		SMTPAddr:        getEnv("SMTP_ADDR", ""),
+		SMTPFrom:        getEnv("SMTP_FROM", "etf-scraper@localhost"),
+		SMTPUsername:    getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:    getEnv("SMTP_PASSWORD", ""),
+		AlertSecret:     getEnv("ALERT_WEBHOOK_SECRET", ""),
+		RiskFreeRate:    parseFloat(getEnv("RISK_FREE_RATE", "0"), 0),
 		StaticDir:       getEnv("STATIC_DIR", "./static"),
 		CACertPath:      getEnv("CA_CERT_PATH", "./certs/ca.crt"),
 		ServerCertPath:  getEnv("SERVER_CERT_PATH", "./certs/server.crt"),
@@ -48,3 +69,23 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// parseFloatList разбирает список чисел через запятую, пропуская некорректные значения
+func parseFloatList(value string) []float64 {
+	var list []float64
+	for _, part := range strings.Split(value, ",") {
+		if v, err := strconv.ParseFloat(strings.TrimSpace(part), 64); err == nil {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// parseFloat разбирает число, при ошибке возвращает defaultValue
+func parseFloat(value string, defaultValue float64) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
@@ -0,0 +1,31 @@
+// Package csvread читает небольшие CSV файлы, выгруженные из Excel и
+// сторонних сервисов: отбрасывает BOM и определяет разделитель по первой строке
+package csvread
+
+import (
+	"bytes"
+	"encoding/csv"
+)
+
+// ReadAll разбирает содержимое CSV целиком. Строки могут иметь разное число колонок.
+func ReadAll(content []byte) ([][]string, error) {
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+
+	r := csv.NewReader(bytes.NewReader(content))
+	r.Comma = DetectDelimiter(content)
+	r.FieldsPerRecord = -1
+	return r.ReadAll()
+}
+
+// DetectDelimiter выбирает разделитель CSV по первой строке:
+// точка с запятой или табуляция, если их больше, чем запятых, иначе запятая
+func DetectDelimiter(content []byte) rune {
+	firstLine, _, _ := bytes.Cut(content, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		return ';'
+	}
+	if bytes.Count(firstLine, []byte("\t")) > bytes.Count(firstLine, []byte(",")) {
+		return '\t'
+	}
+	return ','
+}
@@ -0,0 +1,37 @@
+package csvread
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"comma", "a,b\n1,2\n", "a|b/1|2"},
+		{"semicolon with decimal comma", "a;b\n1,5;2,5\n", "a|b/1,5|2,5"},
+		{"tab", "a\tb\n1\t2\n", "a|b/1|2"},
+		{"BOM", "\ufeffa;b\n1;2\n", "a|b/1|2"},
+		{"ragged rows", "a,b,c\n1\n", "a|b|c/1"},
+		{"delimiter from first line only", "a,b\n1;2;3\n", "a|b/1;2;3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := ReadAll([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rows []string
+			for _, r := range records {
+				rows = append(rows, strings.Join(r, "|"))
+			}
+			if got := strings.Join(rows, "/"); got != tt.want {
+				t.Errorf("ReadAll() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"etf-scraper/internal/models"
+)
+
+// SaveBenchmark добавляет бенчмарк или меняет его название
+func (r *Repository) SaveBenchmark(b models.Benchmark) error {
+	_, err := r.db.DB.Exec(`
+		INSERT INTO benchmarks (code, name, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(code) DO UPDATE SET name = excluded.name
+	`, b.Code, b.Name, b.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения бенчмарка: %w", err)
+	}
+	return nil
+}
+
+// GetBenchmarks возвращает все бенчмарки
+func (r *Repository) GetBenchmarks() ([]models.Benchmark, error) {
+	rows, err := r.db.DB.Query("SELECT code, name, created_at FROM benchmarks ORDER BY code")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var benchmarks []models.Benchmark
+	for rows.Next() {
+		var b models.Benchmark
+		if err := rows.Scan(&b.Code, &b.Name, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		benchmarks = append(benchmarks, b)
+	}
+
+	return benchmarks, rows.Err()
+}
+
+// GetBenchmark возвращает бенчмарк по коду; nil, если его нет
+func (r *Repository) GetBenchmark(code string) (*models.Benchmark, error) {
+	var b models.Benchmark
+	err := r.db.DB.QueryRow(
+		"SELECT code, name, created_at FROM benchmarks WHERE code = ?", code,
+	).Scan(&b.Code, &b.Name, &b.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// DeleteBenchmark удаляет бенчмарк вместе со значениями и привязками фондов;
+// false, если его нет
+func (r *Repository) DeleteBenchmark(code string) (bool, error) {
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("DELETE FROM benchmarks WHERE code = ?", code)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	if _, err := tx.Exec("DELETE FROM benchmark_levels WHERE code = ?", code); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec("DELETE FROM fund_benchmarks WHERE code = ?", code); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// SaveBenchmarkLevels добавляет значения индекса, заменяя значения на те же даты
+func (r *Repository) SaveBenchmarkLevels(code string, levels []models.BenchmarkLevel) error {
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO benchmark_levels (code, date, level)
+		VALUES (?, ?, ?)
+		ON CONFLICT(code, date) DO UPDATE SET level = excluded.level
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, l := range levels {
+		if _, err := stmt.Exec(code, l.Date, l.Level); err != nil {
+			return fmt.Errorf("ошибка сохранения значения индекса за %s: %w", l.Date, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetBenchmarkLevels возвращает значения индекса по возрастанию даты
+func (r *Repository) GetBenchmarkLevels(code string) ([]models.BenchmarkLevel, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT date, level
+		FROM benchmark_levels
+		WHERE code = ?
+		ORDER BY date
+	`, code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var levels []models.BenchmarkLevel
+	for rows.Next() {
+		var l models.BenchmarkLevel
+		if err := rows.Scan(&l.Date, &l.Level); err != nil {
+			return nil, err
+		}
+		levels = append(levels, l)
+	}
+
+	return levels, rows.Err()
+}
+
+// SetFundBenchmark привязывает фонд к бенчмарку, заменяя прежнюю привязку
+func (r *Repository) SetFundBenchmark(link models.FundBenchmark) error {
+	_, err := r.db.DB.Exec(`
+		INSERT INTO fund_benchmarks (ticker, code, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(ticker) DO UPDATE SET
+			code = excluded.code,
+			created_at = excluded.created_at
+	`, link.Ticker, link.Code, link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка привязки фонда к бенчмарку: %w", err)
+	}
+	return nil
+}
+
+// GetFundBenchmarks возвращает привязки фондов, заданные администратором
+func (r *Repository) GetFundBenchmarks() ([]models.FundBenchmark, error) {
+	rows, err := r.db.DB.Query("SELECT ticker, code, created_at FROM fund_benchmarks ORDER BY ticker")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []models.FundBenchmark
+	for rows.Next() {
+		var l models.FundBenchmark
+		if err := rows.Scan(&l.Ticker, &l.Code, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+
+	return links, rows.Err()
+}
+
+// DeleteFundBenchmark удаляет привязку фонда; false, если ее нет
+func (r *Repository) DeleteFundBenchmark(ticker string) (bool, error) {
+	res, err := r.db.DB.Exec("DELETE FROM fund_benchmarks WHERE ticker = ?", ticker)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
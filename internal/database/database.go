@@ -10,6 +10,9 @@ import (
 // Database представляет подключение к базе данных
 type Database struct {
 	DB *sql.DB
+
+	// vocabulary кэширует словарь поискового индекса (см. searchVocabulary)
+	vocabulary vocabularyCache
 }
 
 // NewDatabase создает новое подключение к БД и инициализирует схему
@@ -58,6 +61,162 @@ func (d *Database) initSchema() error {
 
 	CREATE INDEX IF NOT EXISTS idx_date_ticker 
 	ON etf_data(date_scraped, ticker);
+
+	CREATE TABLE IF NOT EXISTS scrape_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		started_at TEXT NOT NULL,
+		finished_at TEXT NOT NULL,
+		date_scraped TEXT,
+		status TEXT NOT NULL,
+		rows_total INTEGER NOT NULL DEFAULT 0,
+		rows_saved INTEGER NOT NULL DEFAULT 0,
+		error_count INTEGER NOT NULL DEFAULT 0,
+		message TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS parse_errors (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		run_id INTEGER NOT NULL REFERENCES scrape_runs(id),
+		row_index INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		column_name TEXT,
+		raw_text TEXT,
+		reason TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_parse_errors_run
+	ON parse_errors(run_id);
+
+	CREATE TABLE IF NOT EXISTS http_cache (
+		url TEXT PRIMARY KEY,
+		etag TEXT,
+		last_modified TEXT,
+		updated_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		date_scraped TEXT NOT NULL,
+		prev_date_scraped TEXT NOT NULL,
+		ticker TEXT NOT NULL,
+		management_company TEXT,
+		fund_name TEXT,
+		kind TEXT NOT NULL,
+		title TEXT NOT NULL,
+		old_value TEXT,
+		new_value TEXT,
+		created_at TEXT NOT NULL,
+		UNIQUE(date_scraped, ticker, kind)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_events_date
+	ON events(date_scraped);
+
+	CREATE TABLE IF NOT EXISTS webhooks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		url TEXT NOT NULL,
+		event_types TEXT,
+		tickers TEXT,
+		secret TEXT NOT NULL,
+		active INTEGER NOT NULL DEFAULT 1,
+		created_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		webhook_id INTEGER NOT NULL REFERENCES webhooks(id),
+		delivery_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		attempts INTEGER NOT NULL,
+		status_code INTEGER,
+		success INTEGER NOT NULL,
+		error TEXT,
+		duration_ms INTEGER,
+		created_at TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook
+	ON webhook_deliveries(webhook_id);
+
+	CREATE TABLE IF NOT EXISTS watchlists (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		created_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS watchlist_tickers (
+		watchlist_id INTEGER NOT NULL REFERENCES watchlists(id),
+		ticker TEXT NOT NULL,
+		PRIMARY KEY (watchlist_id, ticker)
+	);
+
+	CREATE TABLE IF NOT EXISTS alert_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		watchlist_id INTEGER NOT NULL REFERENCES watchlists(id),
+		kind TEXT NOT NULL,
+		threshold REAL,
+		notifier TEXT NOT NULL,
+		target TEXT,
+		created_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS alerts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		rule_id INTEGER NOT NULL,
+		watchlist_id INTEGER NOT NULL,
+		ticker TEXT NOT NULL,
+		date_scraped TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		message TEXT NOT NULL,
+		delivered INTEGER NOT NULL DEFAULT 0,
+		error TEXT,
+		created_at TEXT NOT NULL,
+		UNIQUE(rule_id, ticker, date_scraped)
+	);
+
+	CREATE TABLE IF NOT EXISTS index_aliases (
+		alias_key TEXT PRIMARY KEY,
+		alias TEXT NOT NULL,
+		canonical TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS risk_metrics (
+		date_scraped TEXT NOT NULL,
+		ticker TEXT NOT NULL,
+		returns TEXT NOT NULL,
+		annualized_return REAL,
+		volatility REAL,
+		max_drawdown REAL,
+		PRIMARY KEY (date_scraped, ticker)
+	);
+
+	CREATE TABLE IF NOT EXISTS benchmarks (
+		code TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS benchmark_levels (
+		code TEXT NOT NULL,
+		date TEXT NOT NULL,
+		level REAL NOT NULL,
+		PRIMARY KEY (code, date)
+	);
+
+	CREATE TABLE IF NOT EXISTS fx_rates (
+		date TEXT NOT NULL,
+		currency TEXT NOT NULL,
+		rate REAL NOT NULL,
+		PRIMARY KEY (currency, date)
+	);
+
+	CREATE TABLE IF NOT EXISTS fund_benchmarks (
+		ticker TEXT PRIMARY KEY,
+		code TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	);
 	`
 
 	_, err := d.DB.Exec(createTableSQL)
@@ -65,9 +224,81 @@ func (d *Database) initSchema() error {
 		return fmt.Errorf("ошибка создания схемы: %w", err)
 	}
 
+	if err := d.migrate(); err != nil {
+		return err
+	}
+
+	return d.initSearchIndex()
+}
+
+// columnMigrations перечисляет колонки, добавленные после первой версии схемы
+var columnMigrations = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"etf_data", "ter_trend", "TEXT"},
+	{"etf_data", "info_flags", "TEXT"},
+}
+
+// migrate добавляет недостающие колонки в существующие таблицы
+// и индексы по ним
+func (d *Database) migrate() error {
+	for _, m := range columnMigrations {
+		exists, err := d.columnExists(m.table, m.column)
+		if err != nil {
+			return fmt.Errorf("ошибка проверки схемы: %w", err)
+		}
+		if exists {
+			continue
+		}
+
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)
+		if _, err := d.DB.Exec(query); err != nil {
+			return fmt.Errorf("ошибка миграции %s.%s: %w", m.table, m.column, err)
+		}
+	}
+
+	// Частичный индекс по записям без маркеров: проверка HasUnclassifiedMarkers
+	// при запуске не сканирует всю историю
+	if _, err := d.DB.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_etf_data_unclassified ON etf_data(id)
+		WHERE ter_trend IS NULL OR info_flags IS NULL
+	`); err != nil {
+		return fmt.Errorf("ошибка создания индекса маркеров: %w", err)
+	}
+
 	return nil
 }
 
+// columnExists проверяет наличие колонки в таблице
+func (d *Database) columnExists(table, column string) (bool, error) {
+	rows, err := d.DB.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
+
 // Close закрывает подключение к БД
 func (d *Database) Close() error {
 	if d.DB != nil {
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"etf-scraper/internal/models"
+)
+
+// EventFilter задает отбор событий для ленты
+type EventFilter struct {
+	Ticker string
+	// ManagementCos — точные названия управляющих компаний (см. analytics.FilterEvents);
+	// пустой список не ограничивает выборку
+	ManagementCos []string
+	Kind          string
+	Limit         int
+}
+
+// SaveEvents сохраняет события; уже записанные события сеанса пропускаются.
+// Возвращает только новые события с заполненными ID.
+func (r *Repository) SaveEvents(events []models.Event) ([]models.Event, error) {
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO events (
+			date_scraped, prev_date_scraped, ticker, management_company, fund_name,
+			kind, title, old_value, new_value, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	createdAt := time.Now().Format("2006-01-02 15:04:05")
+	var saved []models.Event
+	for _, e := range events {
+		res, err := stmt.Exec(
+			e.DateScraped, e.PrevDateScraped, e.Ticker, e.ManagementCo, e.FundName,
+			e.Kind, e.Title, e.OldValue, e.NewValue, createdAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сохранения события: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		if e.ID, err = res.LastInsertId(); err != nil {
+			return nil, err
+		}
+		e.CreatedAt = createdAt
+		saved = append(saved, e)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// GetEvents возвращает последние события с учетом фильтра
+func (r *Repository) GetEvents(filter EventFilter) ([]models.Event, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Ticker != "" {
+		conditions = append(conditions, "ticker = ?")
+		args = append(args, filter.Ticker)
+	}
+	if len(filter.ManagementCos) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(filter.ManagementCos)), ",")
+		conditions = append(conditions, "management_company IN ("+placeholders+")")
+		for _, c := range filter.ManagementCos {
+			args = append(args, c)
+		}
+	}
+	if filter.Kind != "" {
+		conditions = append(conditions, "kind = ?")
+		args = append(args, filter.Kind)
+	}
+
+	query := `
+		SELECT id, date_scraped, prev_date_scraped, ticker, COALESCE(management_company, ''),
+			COALESCE(fund_name, ''), kind, title, COALESCE(old_value, ''),
+			COALESCE(new_value, ''), created_at
+		FROM events
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY date_scraped DESC, id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.Event
+	for rows.Next() {
+		var e models.Event
+		if err := rows.Scan(
+			&e.ID, &e.DateScraped, &e.PrevDateScraped, &e.Ticker, &e.ManagementCo,
+			&e.FundName, &e.Kind, &e.Title, &e.OldValue, &e.NewValue, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// GetEventCompanies возвращает названия управляющих компаний, встречающиеся в событиях
+func (r *Repository) GetEventCompanies() ([]string, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT DISTINCT management_company FROM events
+		WHERE management_company IS NOT NULL AND management_company != ''
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var companies []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		companies = append(companies, c)
+	}
+
+	return companies, rows.Err()
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"fmt"
+
+	"etf-scraper/internal/models"
+)
+
+// SaveFXRates сохраняет курсы валют, заменяя курсы на те же даты
+func (r *Repository) SaveFXRates(rates []models.FXRate) error {
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO fx_rates (date, currency, rate)
+		VALUES (?, ?, ?)
+		ON CONFLICT(currency, date) DO UPDATE SET rate = excluded.rate
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, rate := range rates {
+		if _, err := stmt.Exec(rate.Date, rate.Currency, rate.Rate); err != nil {
+			return fmt.Errorf("ошибка сохранения курса %s за %s: %w", rate.Currency, rate.Date, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetFXRates возвращает курсы по валюте и дате; пустые параметры не ограничивают выборку
+func (r *Repository) GetFXRates(currency, from, to string) ([]models.FXRate, error) {
+	query := "SELECT date, currency, rate FROM fx_rates WHERE 1=1"
+	var args []interface{}
+	if currency != "" {
+		query += " AND currency = ?"
+		args = append(args, currency)
+	}
+	if from != "" {
+		query += " AND date >= ?"
+		args = append(args, from)
+	}
+	if to != "" {
+		query += " AND date <= ?"
+		args = append(args, to)
+	}
+	query += " ORDER BY currency, date"
+
+	rows, err := r.db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []models.FXRate
+	for rows.Next() {
+		var rate models.FXRate
+		if err := rows.Scan(&rate.Date, &rate.Currency, &rate.Rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
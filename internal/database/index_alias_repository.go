@@ -0,0 +1,57 @@
+package database
+
+import (
+	"fmt"
+
+	"etf-scraper/internal/models"
+)
+
+// SaveIndexAlias добавляет или заменяет синоним индекса
+func (r *Repository) SaveIndexAlias(alias models.IndexAlias) error {
+	_, err := r.db.DB.Exec(`
+		INSERT INTO index_aliases (alias_key, alias, canonical, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(alias_key) DO UPDATE SET
+			alias = excluded.alias,
+			canonical = excluded.canonical,
+			created_at = excluded.created_at
+	`, alias.AliasKey, alias.Alias, alias.Canonical, alias.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения синонима индекса: %w", err)
+	}
+	return nil
+}
+
+// GetIndexAliases возвращает все синонимы индексов
+func (r *Repository) GetIndexAliases() ([]models.IndexAlias, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT alias_key, alias, canonical, created_at
+		FROM index_aliases
+		ORDER BY canonical, alias_key
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aliases []models.IndexAlias
+	for rows.Next() {
+		var a models.IndexAlias
+		if err := rows.Scan(&a.AliasKey, &a.Alias, &a.Canonical, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, a)
+	}
+
+	return aliases, rows.Err()
+}
+
+// DeleteIndexAlias удаляет синоним по нормализованному написанию; false, если его нет
+func (r *Repository) DeleteIndexAlias(aliasKey string) (bool, error) {
+	res, err := r.db.DB.Exec("DELETE FROM index_aliases WHERE alias_key = ?", aliasKey)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -4,10 +4,22 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 
 	"etf-scraper/internal/models"
 )
 
+// ETFColumns перечисляет колонки etf_data в порядке сканирования scanETFRows;
+// тот же порядок использует scanETFResponse в пакете server
+const ETFColumns = `
+	id, date_scraped, ticker, trade_status, management_company,
+	asset_class, ter_percent, ter_direction, fund_name, management_style,
+	target_index, currency, start_date, info_icon,
+	COALESCE(ter_trend, ''), COALESCE(info_flags, ''),
+	price_change_6m, price_change_2024, price_change_2023, price_change_2022,
+	price_change_2021, price_change_2020, nav_million_rub, last_update_date
+`
+
 // Repository предоставляет методы для работы с данными ETF
 type Repository struct {
 	db *Database
@@ -30,42 +42,58 @@ func (r *Repository) SaveETFs(data []models.ETFData) error {
 	}
 	defer tx.Rollback()
 
+	savedCount, err := saveETFs(tx, data)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("Сохранено записей в БД: %d из %d", savedCount, len(data))
+	return nil
+}
+
+// saveETFs добавляет записи в etf_data и поисковый индекс в транзакции tx;
+// записи, которые не удалось сохранить, пропускаются. Возвращает число сохраненных.
+func saveETFs(tx *sql.Tx, data []models.ETFData) (int, error) {
 	stmt, err := tx.Prepare(`
 		INSERT INTO etf_data (
 			date_scraped, ticker, trade_status, management_company, asset_class,
 			ter_percent, ter_direction, fund_name, management_style, target_index,
-			currency, start_date, info_icon, price_change_6m, price_change_2024,
-			price_change_2023, price_change_2022, price_change_2021, price_change_2020,
-			nav_million_rub, last_update_date
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			currency, start_date, info_icon, ter_trend, info_flags, price_change_6m,
+			price_change_2024, price_change_2023, price_change_2022, price_change_2021,
+			price_change_2020, nav_million_rub, last_update_date
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer stmt.Close()
 
 	savedCount := 0
 	for _, etf := range data {
-		_, err := stmt.Exec(
+		res, err := stmt.Exec(
 			etf.DateScraped, etf.Ticker, etf.TradeStatus, etf.ManagementCo, etf.AssetClass,
 			etf.TERPercent, etf.TERDirection, etf.FundName, etf.ManagementStyle, etf.TargetIndex,
-			etf.Currency, etf.StartDate, etf.InfoIcon, etf.PriceChange6M, etf.PriceChange2024,
-			etf.PriceChange2023, etf.PriceChange2022, etf.PriceChange2021, etf.PriceChange2020,
-			etf.NAVMillionRub, etf.LastUpdateDate,
+			etf.Currency, etf.StartDate, etf.InfoIcon, string(etf.TERTrend), models.JoinInfoFlags(etf.InfoFlags),
+			etf.PriceChange6M, etf.PriceChange2024, etf.PriceChange2023, etf.PriceChange2022,
+			etf.PriceChange2021, etf.PriceChange2020, etf.NAVMillionRub, etf.LastUpdateDate,
 		)
 		if err != nil {
 			log.Printf("Ошибка сохранения записи %s: %v", etf.Ticker, err)
 			continue
 		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+		if err := indexETF(tx, id, etf); err != nil {
+			return 0, err
+		}
 		savedCount++
 	}
-
-	if err := tx.Commit(); err != nil {
-		return err
-	}
-
-	log.Printf("Сохранено записей в БД: %d из %d", savedCount, len(data))
-	return nil
+	return savedCount, nil
 }
 
 // GetLatestETFs возвращает последние данные ETF
@@ -74,16 +102,16 @@ func (r *Repository) GetLatestETFs(ticker string) ([]models.ETFData, error) {
 	var args []interface{}
 
 	if ticker != "" {
-		query = `
-			SELECT * FROM etf_data 
+		query = `SELECT ` + ETFColumns + `
+			FROM etf_data 
 			WHERE ticker = ? 
 			ORDER BY date_scraped DESC 
 			LIMIT 1
 		`
 		args = append(args, ticker)
 	} else {
-		query = `
-			SELECT * FROM etf_data 
+		query = `SELECT ` + ETFColumns + `
+			FROM etf_data 
 			WHERE date_scraped = (SELECT MAX(date_scraped) FROM etf_data)
 			ORDER BY ticker
 		`
@@ -98,10 +126,155 @@ func (r *Repository) GetLatestETFs(ticker string) ([]models.ETFData, error) {
 	return r.scanETFRows(rows)
 }
 
+// ETFFilter задает фильтры и сортировку списка ETF (как в /api/etfs)
+type ETFFilter struct {
+	AssetClass string
+	TERTrend   string
+	InfoFlag   string
+	SortBy     string
+	Order      string
+}
+
+// sortableColumns перечисляет колонки, по которым разрешена сортировка
+var sortableColumns = map[string]bool{
+	"ticker": true, "trade_status": true, "management_company": true, "asset_class": true,
+	"ter_percent": true, "fund_name": true, "currency": true, "start_date": true,
+	"price_change_6m": true, "price_change_2024": true, "price_change_2023": true,
+	"price_change_2022": true, "price_change_2021": true, "price_change_2020": true,
+	"nav_million_rub": true,
+}
+
+// Clause возвращает условия фильтра (начиная с AND) и ORDER BY с аргументами запроса
+func (f ETFFilter) Clause() (string, []interface{}) {
+	var clause string
+	var args []interface{}
+
+	if f.AssetClass != "" && f.AssetClass != "Все" {
+		clause += " AND asset_class = ?"
+		args = append(args, f.AssetClass)
+	}
+	if f.TERTrend != "" {
+		clause += " AND ter_trend = ?"
+		args = append(args, f.TERTrend)
+	}
+	if f.InfoFlag != "" {
+		clause += " AND instr(',' || COALESCE(info_flags, '') || ',', ?) > 0"
+		args = append(args, ","+f.InfoFlag+",")
+	}
+
+	sortBy := f.SortBy
+	if !sortableColumns[sortBy] {
+		sortBy = "nav_million_rub"
+	}
+	order := "DESC"
+	if strings.EqualFold(f.Order, "ASC") {
+		order = "ASC"
+	}
+	clause += fmt.Sprintf(" ORDER BY %s %s", sortBy, order)
+
+	return clause, args
+}
+
+// ListETFs возвращает ETF сеанса session (см. ResolveAsOf) с учетом фильтра
+func (r *Repository) ListETFs(session string, filter ETFFilter) ([]models.ETFData, error) {
+	clause, args := filter.Clause()
+	query := `SELECT ` + ETFColumns + `
+		FROM etf_data
+		WHERE date_scraped = ?
+	` + clause
+
+	rows, err := r.db.DB.Query(query, append([]interface{}{session}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanETFRows(rows)
+}
+
+// GetTickerHistory возвращает все сохраненные записи тикера в хронологическом порядке
+func (r *Repository) GetTickerHistory(ticker string) ([]models.ETFData, error) {
+	rows, err := r.db.DB.Query(`SELECT `+ETFColumns+`
+		FROM etf_data
+		WHERE ticker = ?
+		ORDER BY date_scraped
+	`, ticker)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanETFRows(rows)
+}
+
+// GetScrapeSessions возвращает даты сеансов скрейпинга позже after (пустая строка — все)
+func (r *Repository) GetScrapeSessions(after string) ([]string, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT DISTINCT date_scraped
+		FROM etf_data
+		WHERE date_scraped > ?
+		ORDER BY date_scraped
+	`, after)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []string
+	for rows.Next() {
+		var session string
+		if err := rows.Scan(&session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// GetLatestSession возвращает последний сеанс скрейпинга раньше before
+// (пустая строка — без ограничения); пустая строка, если сеансов нет
+func (r *Repository) GetLatestSession(before string) (string, error) {
+	query := "SELECT COALESCE(MAX(date_scraped), '') FROM etf_data"
+	var args []interface{}
+	if before != "" {
+		query += " WHERE date_scraped < ?"
+		args = append(args, before)
+	}
+
+	var session string
+	err := r.db.DB.QueryRow(query, args...).Scan(&session)
+	return session, err
+}
+
+// SessionExists проверяет, есть ли в БД записи сеанса скрейпинга
+func (r *Repository) SessionExists(dateScraped string) (bool, error) {
+	var exists bool
+	err := r.db.DB.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM etf_data WHERE date_scraped = ?)", dateScraped,
+	).Scan(&exists)
+	return exists, err
+}
+
+// GetETFsBySession возвращает все записи одного сеанса скрейпинга
+func (r *Repository) GetETFsBySession(dateScraped string) ([]models.ETFData, error) {
+	rows, err := r.db.DB.Query(`SELECT `+ETFColumns+`
+		FROM etf_data
+		WHERE date_scraped = ?
+		ORDER BY ticker
+	`, dateScraped)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanETFRows(rows)
+}
+
 // GetTopByNAV возвращает топ ETF по размеру СЧА
 func (r *Repository) GetTopByNAV(limit int) ([]models.ETFData, error) {
-	query := `
-		SELECT * FROM etf_data 
+	query := `SELECT ` + ETFColumns + `
+		FROM etf_data 
 		WHERE date_scraped = (SELECT MAX(date_scraped) FROM etf_data)
 		AND nav_million_rub IS NOT NULL
 		ORDER BY nav_million_rub DESC 
@@ -133,24 +306,123 @@ func (r *Repository) GetStats() (totalRecords, uniqueTickers, scrapeSessions int
 	return
 }
 
+// HasUnclassifiedMarkers проверяет, есть ли записи с пустыми ter_trend или info_flags
+func (r *Repository) HasUnclassifiedMarkers() (bool, error) {
+	var exists bool
+	err := r.db.DB.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM etf_data WHERE ter_trend IS NULL OR info_flags IS NULL)
+	`).Scan(&exists)
+	return exists, err
+}
+
+// BackfillMarkers заполняет ter_trend и info_flags записей, сохраненных до появления
+// этих колонок (в них NULL), по исходным ячейкам ter_direction и info_icon.
+// Повторный вызов ничего не меняет. Возвращает число обновленных записей.
+func (r *Repository) BackfillMarkers(classifyTER func(string) models.TERTrend, classifyInfo func(string) []models.InfoFlag) (int64, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT DISTINCT COALESCE(ter_direction, ''), COALESCE(info_icon, '')
+		FROM etf_data
+		WHERE ter_trend IS NULL OR info_flags IS NULL
+	`)
+	if err != nil {
+		return 0, err
+	}
+	type cells struct{ ter, info string }
+	var pending []cells
+	for rows.Next() {
+		var c cells
+		if err := rows.Scan(&c.ter, &c.info); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var updated int64
+	for _, c := range pending {
+		res, err := tx.Exec(`
+			UPDATE etf_data
+			SET ter_trend = COALESCE(ter_trend, ?), info_flags = COALESCE(info_flags, ?)
+			WHERE (ter_trend IS NULL OR info_flags IS NULL)
+				AND COALESCE(ter_direction, '') = ? AND COALESCE(info_icon, '') = ?
+		`, string(classifyTER(c.ter)), models.JoinInfoFlags(classifyInfo(c.info)), c.ter, c.info)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка заполнения маркеров: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		updated += n
+	}
+
+	return updated, tx.Commit()
+}
+
+// GetHTTPCache возвращает сохраненные валидаторы для URL (nil, если их нет)
+func (r *Repository) GetHTTPCache(url string) (*models.HTTPCacheEntry, error) {
+	entry := &models.HTTPCacheEntry{URL: url}
+	err := r.db.DB.QueryRow(`
+		SELECT COALESCE(etag, ''), COALESCE(last_modified, ''), updated_at
+		FROM http_cache
+		WHERE url = ?
+	`, url).Scan(&entry.ETag, &entry.LastModified, &entry.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// SaveHTTPCache сохраняет валидаторы ответа для URL
+func (r *Repository) SaveHTTPCache(entry models.HTTPCacheEntry) error {
+	_, err := r.db.DB.Exec(`
+		INSERT INTO http_cache (url, etag, last_modified, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(url) DO UPDATE SET
+			etag = excluded.etag,
+			last_modified = excluded.last_modified,
+			updated_at = excluded.updated_at
+	`, entry.URL, entry.ETag, entry.LastModified, entry.UpdatedAt)
+	return err
+}
+
 // scanETFRows сканирует строки БД в срез ETFData
 func (r *Repository) scanETFRows(rows *sql.Rows) ([]models.ETFData, error) {
 	var data []models.ETFData
 	for rows.Next() {
 		var etf models.ETFData
 		var id int
+		var terTrend, infoFlags string
 
 		err := rows.Scan(
 			&id, &etf.DateScraped, &etf.Ticker, &etf.TradeStatus, &etf.ManagementCo,
 			&etf.AssetClass, &etf.TERPercent, &etf.TERDirection, &etf.FundName,
 			&etf.ManagementStyle, &etf.TargetIndex, &etf.Currency, &etf.StartDate,
-			&etf.InfoIcon, &etf.PriceChange6M, &etf.PriceChange2024, &etf.PriceChange2023,
+			&etf.InfoIcon, &terTrend, &infoFlags,
+			&etf.PriceChange6M, &etf.PriceChange2024, &etf.PriceChange2023,
 			&etf.PriceChange2022, &etf.PriceChange2021, &etf.PriceChange2020,
 			&etf.NAVMillionRub, &etf.LastUpdateDate,
 		)
 		if err != nil {
 			return nil, err
 		}
+		etf.TERTrend = models.TERTrend(terTrend)
+		etf.InfoFlags = models.SplitInfoFlags(infoFlags)
 		data = append(data, etf)
 	}
 
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"etf-scraper/internal/models"
+)
+
+func TestBackfillMarkers(t *testing.T) {
+	repo := newSessionRepo(t)
+	session := "2024-05-01 00:00:00"
+	if err := repo.SaveETFs([]models.ETFData{
+		{DateScraped: session, Ticker: "TMOS", TERDirection: "▲", InfoIcon: "🆕", TERTrend: models.TERTrendUp},
+		{DateScraped: session, Ticker: "SBMX", TERDirection: "▲", InfoIcon: "🆕", TERTrend: models.TERTrendUp},
+		{DateScraped: session, Ticker: "GOLD", TERTrend: models.TERTrendUnknown},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if pending, err := repo.HasUnclassifiedMarkers(); err != nil || pending {
+		t.Fatalf("HasUnclassifiedMarkers() = %v, %v; want false for classified rows", pending, err)
+	}
+
+	// Записи, сохраненные до появления колонок, хранят в них NULL
+	if _, err := repo.db.DB.Exec("UPDATE etf_data SET ter_trend = NULL, info_flags = NULL WHERE ticker != 'GOLD'"); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := repo.HasUnclassifiedMarkers(); err != nil || !pending {
+		t.Fatalf("HasUnclassifiedMarkers() = %v, %v; want true", pending, err)
+	}
+
+	// Проверка не сканирует etf_data целиком, а идет по частичному индексу
+	var plan strings.Builder
+	rows, err := repo.db.DB.Query("EXPLAIN QUERY PLAN SELECT 1 FROM etf_data WHERE ter_trend IS NULL OR info_flags IS NULL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var id, parent, unused int
+		var detail string
+		if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+			t.Fatal(err)
+		}
+		plan.WriteString(detail + "\n")
+	}
+	rows.Close()
+	if !strings.Contains(plan.String(), "idx_etf_data_unclassified") {
+		t.Errorf("query plan does not use the partial index:\n%s", plan.String())
+	}
+
+	calls := 0
+	classifyTER := func(raw string) models.TERTrend {
+		calls++
+		if raw == "▲" {
+			return models.TERTrendUp
+		}
+		return models.TERTrendUnknown
+	}
+	classifyInfo := func(raw string) []models.InfoFlag {
+		if raw == "🆕" {
+			return []models.InfoFlag{models.InfoFlagNewFund}
+		}
+		return []models.InfoFlag{}
+	}
+
+	n, err := repo.BackfillMarkers(classifyTER, classifyInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Одинаковые ячейки классифицируются один раз
+	if n != 2 || calls != 1 {
+		t.Errorf("BackfillMarkers() updated %d rows with %d classifications, want 2 and 1", n, calls)
+	}
+	if pending, err := repo.HasUnclassifiedMarkers(); err != nil || pending {
+		t.Errorf("HasUnclassifiedMarkers() after backfill = %v, %v", pending, err)
+	}
+
+	data, err := repo.GetETFsBySession(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, etf := range data {
+		if etf.Ticker == "GOLD" {
+			continue
+		}
+		if etf.TERTrend != models.TERTrendUp || len(etf.InfoFlags) != 1 || etf.InfoFlags[0] != models.InfoFlagNewFund {
+			t.Errorf("%s: trend %q, flags %v", etf.Ticker, etf.TERTrend, etf.InfoFlags)
+		}
+	}
+
+	if n, err := repo.BackfillMarkers(classifyTER, classifyInfo); err != nil || n != 0 {
+		t.Errorf("second BackfillMarkers() = %d, %v; want 0", n, err)
+	}
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"etf-scraper/internal/models"
+)
+
+// SaveRiskMetrics сохраняет показатели риска фондов, заменяя ранее рассчитанные
+func (r *Repository) SaveRiskMetrics(metrics []models.RiskMetrics) error {
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO risk_metrics (date_scraped, ticker, returns, annualized_return, volatility, max_drawdown)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(date_scraped, ticker) DO UPDATE SET
+			returns = excluded.returns,
+			annualized_return = excluded.annualized_return,
+			volatility = excluded.volatility,
+			max_drawdown = excluded.max_drawdown
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, m := range metrics {
+		returns, err := json.Marshal(m.Returns)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(m.DateScraped, m.Ticker, string(returns), m.AnnualizedReturn, m.Volatility, m.MaxDrawdown); err != nil {
+			return fmt.Errorf("ошибка сохранения показателей риска %s: %w", m.Ticker, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetRiskMetrics возвращает показатели риска фондов за сеанс скрейпинга
+func (r *Repository) GetRiskMetrics(dateScraped string) ([]models.RiskMetrics, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT date_scraped, ticker, returns, annualized_return, volatility, max_drawdown
+		FROM risk_metrics
+		WHERE date_scraped = ?
+		ORDER BY ticker
+	`, dateScraped)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var metrics []models.RiskMetrics
+	for rows.Next() {
+		var m models.RiskMetrics
+		var returns string
+		if err := rows.Scan(&m.DateScraped, &m.Ticker, &returns, &m.AnnualizedReturn, &m.Volatility, &m.MaxDrawdown); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(returns), &m.Returns); err != nil {
+			return nil, fmt.Errorf("повреждены доходности %s: %w", m.Ticker, err)
+		}
+		m.Observations = len(m.Returns)
+		metrics = append(metrics, m)
+	}
+
+	return metrics, rows.Err()
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"etf-scraper/internal/models"
+)
+
+// SaveScrapeRun сохраняет запуск скрейпера вместе с ошибками парсинга и возвращает его ID
+func (r *Repository) SaveScrapeRun(run models.ScrapeRun, parseErrors []models.ParseError) (int64, error) {
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	runID, err := insertScrapeRun(tx, run, parseErrors)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return runID, nil
+}
+
+// SaveImport сохраняет импортированный снимок и его запуск в одной транзакции:
+// при ошибке в БД не остается ни данных без запуска, ни запуска без данных.
+// RowsSaved запуска — число фактически сохраненных записей; возвращаются ID запуска и оно же.
+func (r *Repository) SaveImport(data []models.ETFData, run models.ScrapeRun, parseErrors []models.ParseError) (int64, int, error) {
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	saved, err := saveETFs(tx, data)
+	if err != nil {
+		return 0, 0, err
+	}
+	run.RowsSaved = saved
+
+	runID, err := insertScrapeRun(tx, run, parseErrors)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	log.Printf("Сохранено записей в БД: %d из %d", saved, len(data))
+	return runID, saved, nil
+}
+
+// insertScrapeRun добавляет запуск и его ошибки парсинга в транзакции tx
+func insertScrapeRun(tx *sql.Tx, run models.ScrapeRun, parseErrors []models.ParseError) (int64, error) {
+	res, err := tx.Exec(`
+		INSERT INTO scrape_runs (
+			started_at, finished_at, date_scraped, status,
+			rows_total, rows_saved, error_count, message
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.StartedAt, run.FinishedAt, run.DateScraped, run.Status,
+		run.RowsTotal, run.RowsSaved, run.ErrorCount, run.Message)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сохранения запуска: %w", err)
+	}
+
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(parseErrors) > 0 {
+		stmt, err := tx.Prepare(`
+			INSERT INTO parse_errors (run_id, row_index, kind, column_name, raw_text, reason)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`)
+		if err != nil {
+			return 0, err
+		}
+		defer stmt.Close()
+
+		for _, pe := range parseErrors {
+			if _, err := stmt.Exec(runID, pe.Row, pe.Kind, pe.Column, pe.RawText, pe.Reason); err != nil {
+				return 0, fmt.Errorf("ошибка сохранения ошибки парсинга: %w", err)
+			}
+		}
+	}
+
+	return runID, nil
+}
+
+// GetScrapeRuns возвращает последние запуски скрейпера
+func (r *Repository) GetScrapeRuns(limit int) ([]models.ScrapeRun, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT id, started_at, finished_at, COALESCE(date_scraped, ''), status,
+			rows_total, rows_saved, error_count, COALESCE(message, '')
+		FROM scrape_runs
+		ORDER BY id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []models.ScrapeRun
+	for rows.Next() {
+		var run models.ScrapeRun
+		if err := rows.Scan(
+			&run.ID, &run.StartedAt, &run.FinishedAt, &run.DateScraped, &run.Status,
+			&run.RowsTotal, &run.RowsSaved, &run.ErrorCount, &run.Message,
+		); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// GetScrapeRun возвращает запуск по ID (nil, если не найден)
+func (r *Repository) GetScrapeRun(id int64) (*models.ScrapeRun, error) {
+	var run models.ScrapeRun
+	err := r.db.DB.QueryRow(`
+		SELECT id, started_at, finished_at, COALESCE(date_scraped, ''), status,
+			rows_total, rows_saved, error_count, COALESCE(message, '')
+		FROM scrape_runs
+		WHERE id = ?
+	`, id).Scan(
+		&run.ID, &run.StartedAt, &run.FinishedAt, &run.DateScraped, &run.Status,
+		&run.RowsTotal, &run.RowsSaved, &run.ErrorCount, &run.Message,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// GetParseErrors возвращает ошибки парсинга для запуска
+func (r *Repository) GetParseErrors(runID int64) ([]models.ParseError, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT run_id, row_index, kind, COALESCE(column_name, ''), COALESCE(raw_text, ''), reason
+		FROM parse_errors
+		WHERE run_id = ?
+		ORDER BY row_index, id
+	`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var parseErrors []models.ParseError
+	for rows.Next() {
+		var pe models.ParseError
+		if err := rows.Scan(&pe.RunID, &pe.Row, &pe.Kind, &pe.Column, &pe.RawText, &pe.Reason); err != nil {
+			return nil, err
+		}
+		parseErrors = append(parseErrors, pe)
+	}
+
+	return parseErrors, rows.Err()
+}
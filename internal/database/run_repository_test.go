@@ -0,0 +1,62 @@
+package database
+
+import (
+	"testing"
+
+	"etf-scraper/internal/models"
+)
+
+func TestSaveImport(t *testing.T) {
+	repo := newSessionRepo(t)
+	session := "2024-05-01 00:00:00"
+
+	data := []models.ETFData{{DateScraped: session, Ticker: "TMOS"}, {DateScraped: session, Ticker: "SBMX"}}
+	run := models.ScrapeRun{
+		StartedAt: session, FinishedAt: session, DateScraped: session,
+		Status: models.RunStatusImported, RowsTotal: 3, ErrorCount: 1,
+	}
+	parseErrors := []models.ParseError{{Row: 4, Kind: models.ParseErrorRow, RawText: " | x", Reason: "пустой тикер"}}
+
+	runID, saved, err := repo.SaveImport(data, run, parseErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved != 2 {
+		t.Errorf("saved = %d, want 2", saved)
+	}
+	stored, err := repo.GetScrapeRun(runID)
+	if err != nil || stored == nil {
+		t.Fatalf("GetScrapeRun() = %v, %v", stored, err)
+	}
+	if stored.RowsSaved != 2 || stored.RowsTotal != 3 || stored.Status != models.RunStatusImported {
+		t.Errorf("run = %+v", stored)
+	}
+	if errs, err := repo.GetParseErrors(runID); err != nil || len(errs) != 1 {
+		t.Errorf("parse errors = %+v, %v", errs, err)
+	}
+}
+
+func TestSaveImportRollsBack(t *testing.T) {
+	repo := newSessionRepo(t)
+	session := "2024-05-01 00:00:00"
+
+	// Без таблицы parse_errors запись запуска завершится ошибкой после сохранения фондов
+	if _, err := repo.db.DB.Exec("DROP TABLE parse_errors"); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := repo.SaveImport(
+		[]models.ETFData{{DateScraped: session, Ticker: "TMOS"}},
+		models.ScrapeRun{StartedAt: session, FinishedAt: session, DateScraped: session, Status: models.RunStatusImported},
+		[]models.ParseError{{Row: 2, Kind: models.ParseErrorRow, Reason: "пустой тикер"}},
+	)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if exists, err := repo.SessionExists(session); err != nil || exists {
+		t.Errorf("session saved without its run: exists = %v, %v", exists, err)
+	}
+	if runs, err := repo.GetScrapeRuns(10); err != nil || len(runs) != 0 {
+		t.Errorf("runs = %+v, %v", runs, err)
+	}
+}
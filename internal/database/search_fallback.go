@@ -0,0 +1,70 @@
+//go:build !sqlite_fts5
+
+package database
+
+import (
+	"database/sql"
+	"log"
+
+	"etf-scraper/internal/models"
+	"etf-scraper/internal/search"
+)
+
+// FullTextSearch сообщает, что бинарник собран без FTS5 (тег sqlite_fts5):
+// поиск сравнивает слова фондов сеанса в памяти по тем же правилам
+const FullTextSearch = false
+
+// vocabularyCache без FTS5 не нужен: словарь строится из фондов сеанса
+type vocabularyCache struct{}
+
+// initSearchIndex без FTS5 только предупреждает, что поиск будет без индекса
+func (d *Database) initSearchIndex() error {
+	log.Printf("⚠️  Бинарник собран без тега sqlite_fts5: /api/search перебирает фонды сеанса в памяти. " +
+		"Для индекса FTS5 соберите с -tags sqlite_fts5 (make build)")
+	return nil
+}
+
+// indexETF без FTS5 ничего не делает
+func indexETF(tx *sql.Tx, id int64, etf models.ETFData) error {
+	return nil
+}
+
+// SearchETFs ищет фонды сеанса без полнотекстового индекса, перебирая их в памяти,
+// и упорядочивает по оценке search.Score. Возвращает также разобранные слова запроса
+// (для подсветки).
+func (r *Repository) SearchETFs(session, query string) ([]SearchHit, []search.Term, error) {
+	terms := search.Parse(query)
+	if len(terms) == 0 {
+		return nil, terms, nil
+	}
+
+	rows, err := r.db.DB.Query(`
+		SELECT id, ticker, COALESCE(fund_name, ''), COALESCE(management_company, ''),
+			COALESCE(target_index, ''), COALESCE(nav_million_rub, 0)
+		FROM etf_data
+		WHERE date_scraped = ?
+	`, session)
+	if err != nil {
+		return nil, nil, err
+	}
+	docs, err := scanSearchDocuments(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vocabulary := make(map[string]bool)
+	for _, d := range docs {
+		for _, f := range d.fields {
+			for _, w := range search.Words(f.Text) {
+				vocabulary[w] = true
+			}
+		}
+	}
+	words := make([]string, 0, len(vocabulary))
+	for w := range vocabulary {
+		words = append(words, w)
+	}
+	search.Expand(terms, words)
+
+	return rankSearch(terms, docs), terms, nil
+}
@@ -0,0 +1,165 @@
+//go:build sqlite_fts5
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"etf-scraper/internal/models"
+	"etf-scraper/internal/search"
+)
+
+// FullTextSearch сообщает, что поиск использует индекс SQLite FTS5
+const FullTextSearch = true
+
+// initSearchIndex создает индекс FTS5 и добавляет в него записи, сохраненные
+// до появления индекса
+func (d *Database) initSearchIndex() error {
+	_, err := d.DB.Exec(`
+	CREATE VIRTUAL TABLE IF NOT EXISTS etf_search USING fts5(
+		ticker, fund_name, management_company, target_index, latin,
+		tokenize = 'unicode61 remove_diacritics 2'
+	);
+
+	CREATE VIRTUAL TABLE IF NOT EXISTS etf_search_vocab
+	USING fts5vocab(etf_search, 'row');
+	`)
+	if err != nil {
+		return fmt.Errorf("ошибка создания поискового индекса: %w", err)
+	}
+
+	rows, err := d.DB.Query(`
+		SELECT id, ticker, COALESCE(fund_name, ''), COALESCE(management_company, ''),
+			COALESCE(target_index, '')
+		FROM etf_data
+		WHERE id > (SELECT COALESCE(MAX(rowid), 0) FROM etf_search)
+		ORDER BY id
+	`)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	var data []models.ETFData
+	for rows.Next() {
+		var id int64
+		var etf models.ETFData
+		if err := rows.Scan(&id, &etf.Ticker, &etf.FundName, &etf.ManagementCo, &etf.TargetIndex); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+		data = append(data, etf)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(ids) == 0 {
+		return err
+	}
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, id := range ids {
+		if err := indexETF(tx, id, data[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// indexETF добавляет запись etf_data в поисковый индекс
+func indexETF(tx *sql.Tx, id int64, etf models.ETFData) error {
+	_, err := tx.Exec(`
+		INSERT INTO etf_search (rowid, ticker, fund_name, management_company, target_index, latin)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, id, etf.Ticker, etf.FundName, etf.ManagementCo, etf.TargetIndex,
+		search.IndexText(etf.Ticker, etf.FundName, etf.ManagementCo, etf.TargetIndex))
+	if err != nil {
+		return fmt.Errorf("ошибка индексации %s: %w", etf.Ticker, err)
+	}
+	return nil
+}
+
+// SearchETFs отбирает фонды сеанса по индексу FTS5 и упорядочивает их по оценке
+// search.Score, как и поиск без индекса. Возвращает также разобранные слова запроса
+// (для подсветки).
+func (r *Repository) SearchETFs(session, query string) ([]SearchHit, []search.Term, error) {
+	terms := search.Parse(query)
+	if len(terms) == 0 {
+		return nil, terms, nil
+	}
+
+	vocabulary, err := r.searchVocabulary()
+	if err != nil {
+		return nil, nil, err
+	}
+	search.Expand(terms, vocabulary)
+
+	rows, err := r.db.DB.Query(`
+		SELECT etf_data.id, etf_data.ticker, COALESCE(etf_data.fund_name, ''),
+			COALESCE(etf_data.management_company, ''), COALESCE(etf_data.target_index, ''),
+			COALESCE(etf_data.nav_million_rub, 0)
+		FROM etf_search
+		JOIN etf_data ON etf_data.id = etf_search.rowid
+		WHERE etf_search MATCH ? AND etf_data.date_scraped = ?
+	`, search.MatchExpression(terms), session)
+	if err != nil {
+		return nil, nil, err
+	}
+	docs, err := scanSearchDocuments(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rankSearch(terms, docs), terms, nil
+}
+
+// vocabularyCache хранит слова поискового индекса между запросами. Индекс только
+// пополняется, поэтому словарь перечитывается, лишь когда вырос MAX(rowid) —
+// в том числе после скрейпинга другим процессом.
+type vocabularyCache struct {
+	mu     sync.Mutex
+	rowID  int64
+	words  []string
+	loaded bool
+}
+
+// searchVocabulary возвращает все слова поискового индекса
+func (r *Repository) searchVocabulary() ([]string, error) {
+	var rowID int64
+	if err := r.db.DB.QueryRow("SELECT COALESCE(MAX(rowid), 0) FROM etf_search").Scan(&rowID); err != nil {
+		return nil, err
+	}
+
+	cache := &r.db.vocabulary
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.loaded && cache.rowID == rowID {
+		return cache.words, nil
+	}
+
+	rows, err := r.db.DB.Query("SELECT term FROM etf_search_vocab")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var words []string
+	for rows.Next() {
+		var w string
+		if err := rows.Scan(&w); err != nil {
+			return nil, err
+		}
+		words = append(words, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cache.rowID, cache.words, cache.loaded = rowID, words, true
+	return words, nil
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"database/sql"
+	"sort"
+
+	"etf-scraper/internal/search"
+)
+
+// SearchHit — запись etf_data, найденная поиском, и ее релевантность
+// (чем больше, тем выше в выдаче)
+type SearchHit struct {
+	ID    int64
+	Score float64
+}
+
+// searchWeights — веса полей индекса в оценке релевантности: тикер, название фонда,
+// управляющая компания, целевой индекс, транслитерация всех полей
+var searchWeights = [5]float64{10, 4, 2, 2, 1}
+
+// searchFields собирает поля фонда для поиска в порядке searchWeights
+func searchFields(ticker, fundName, company, targetIndex string) []search.Field {
+	texts := [5]string{
+		ticker, fundName, company, targetIndex,
+		search.IndexText(ticker, fundName, company, targetIndex),
+	}
+	fields := make([]search.Field, len(texts))
+	for i, text := range texts {
+		fields[i] = search.Field{Text: text, Weight: searchWeights[i]}
+	}
+	return fields
+}
+
+// searchDocument — фонд-кандидат поиска: поля для оценки и СЧА для упорядочивания
+// при равной релевантности
+type searchDocument struct {
+	id     int64
+	nav    float64
+	fields []search.Field
+}
+
+// scanSearchDocuments читает кандидатов из выборки
+// (id, ticker, fund_name, management_company, target_index, nav_million_rub)
+func scanSearchDocuments(rows *sql.Rows) ([]searchDocument, error) {
+	defer rows.Close()
+
+	var docs []searchDocument
+	for rows.Next() {
+		var d searchDocument
+		var ticker, fundName, company, targetIndex string
+		if err := rows.Scan(&d.id, &ticker, &fundName, &company, &targetIndex, &d.nav); err != nil {
+			return nil, err
+		}
+		d.fields = searchFields(ticker, fundName, company, targetIndex)
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
+
+// rankSearch оценивает кандидатов по search.Score и упорядочивает их по убыванию
+// релевантности, при равенстве — по СЧА. С FTS5 и без него порядок выдачи одинаков.
+func rankSearch(terms []search.Term, docs []searchDocument) []SearchHit {
+	var hits []SearchHit
+	navs := make(map[int64]float64)
+	for _, d := range docs {
+		if score := search.Score(terms, d.fields); score > 0 {
+			hits = append(hits, SearchHit{ID: d.id, Score: score})
+			navs[d.id] = d.nav
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return navs[hits[i].ID] > navs[hits[j].ID]
+	})
+	return hits
+}
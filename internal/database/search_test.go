@@ -0,0 +1,115 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"etf-scraper/internal/models"
+)
+
+const searchSession = "2024-05-01 00:00:00"
+
+func newSearchRepo(t *testing.T) (*Repository, string) {
+	t.Helper()
+	path := t.TempDir() + "/search.db"
+	db, err := NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := NewRepository(db)
+
+	nav := func(v float64) *float64 { return &v }
+	err = repo.SaveETFs([]models.ETFData{
+		{DateScraped: searchSession, Ticker: "SBMX", FundName: "Сбербанк – Индекс МосБиржи полной доходности",
+			ManagementCo: "Сбер Управление Активами", TargetIndex: "Индекс МосБиржи", NAVMillionRub: nav(500)},
+		{DateScraped: searchSession, Ticker: "TMOS", FundName: "Тинькофф Индекс МосБиржи",
+			ManagementCo: "Тинькофф Капитал", TargetIndex: "Индекс МосБиржи", NAVMillionRub: nav(1000)},
+		{DateScraped: searchSession, Ticker: "SBGD", FundName: "Сбер Золото",
+			ManagementCo: "Сбер Управление Активами", TargetIndex: "Золото", NAVMillionRub: nav(100)},
+		{DateScraped: searchSession, Ticker: "TGLD", FundName: "Тинькофф Золото",
+			ManagementCo: "Тинькофф Капитал", TargetIndex: "Золото", NAVMillionRub: nav(200)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo, path
+}
+
+// searchTickers возвращает тикеры найденных фондов в порядке выдачи
+func searchTickers(t *testing.T, repo *Repository, query string) string {
+	t.Helper()
+	hits, _, err := repo.SearchETFs(searchSession, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickers := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		var ticker string
+		if err := repo.db.DB.QueryRow("SELECT ticker FROM etf_data WHERE id = ?", hit.ID).Scan(&ticker); err != nil {
+			t.Fatal(err)
+		}
+		tickers = append(tickers, ticker)
+	}
+	return strings.Join(tickers, ",")
+}
+
+// TestSearchETFs проверяет выдачу на фиксированных фондах. Тест запускается
+// с тегом sqlite_fts5 и без него: BM25 и search.Score должны давать одинаковый порядок.
+func TestSearchETFs(t *testing.T) {
+	repo, _ := newSearchRepo(t)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"tmos", "TMOS"},
+		// Начало тикера у обоих фондов — по убыванию СЧА
+		{"SB", "SBMX,SBGD"},
+		// Транслитерация в обе стороны
+		{"сбер", "SBGD,SBMX"},
+		{"Sber", "SBGD,SBMX"},
+		{"tinkoff", "TMOS,TGLD"},
+		// Равная релевантность — по убыванию СЧА
+		{"золото", "TGLD,SBGD"},
+		{"мосбиржи", "TMOS,SBMX"},
+		// Все слова запроса должны найтись
+		{"индекс тинькофф", "TMOS"},
+		// Опечатки
+		{"злото", "TGLD,SBGD"},
+		{"тинькоф капитл", "TMOS,TGLD"},
+		{"палладий", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := searchTickers(t, repo, tt.query); got != tt.want {
+			t.Errorf("SearchETFs(%q) = %q, want %q (FullTextSearch = %v)", tt.query, got, tt.want, FullTextSearch)
+		}
+	}
+}
+
+// TestSearchVocabularyRefresh проверяет, что слова фондов, сохраненных после первого
+// поиска (в том числе через другое подключение к той же БД), находятся с опечатками
+func TestSearchVocabularyRefresh(t *testing.T) {
+	repo, path := newSearchRepo(t)
+
+	if got := searchTickers(t, repo, "паладий"); got != "" {
+		t.Fatalf("SearchETFs() before saving = %q", got)
+	}
+
+	other, err := NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if err := NewRepository(other).SaveETFs([]models.ETFData{
+		{DateScraped: searchSession, Ticker: "PLDM", FundName: "Палладий", ManagementCo: "Тинькофф Капитал"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := searchTickers(t, repo, "паладий"); got != "PLDM" {
+		t.Errorf("SearchETFs() after saving = %q, want PLDM", got)
+	}
+}
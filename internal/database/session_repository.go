@@ -0,0 +1,107 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"etf-scraper/internal/dateparse"
+)
+
+// ErrUnknownSession возвращается, если ссылку на сеанс не удалось сопоставить с данными
+var ErrUnknownSession = errors.New("сеанс не найден")
+
+// ResolveRange определяет пару сеансов по ссылкам from и to (см. ResolveSession):
+// пустой to — последний сеанс, пустой from — сеанс перед to
+func (r *Repository) ResolveRange(fromRef, toRef string) (string, string, error) {
+	var to string
+	var err error
+	if toRef != "" {
+		to, err = r.ResolveSession(toRef)
+	} else {
+		to, err = r.GetLatestSession("")
+		if err == nil && to == "" {
+			err = fmt.Errorf("%w: в БД нет данных", ErrUnknownSession)
+		}
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	var from string
+	if fromRef != "" {
+		from, err = r.ResolveSession(fromRef)
+	} else {
+		from, err = r.GetLatestSession(to)
+		if err == nil && from == "" {
+			err = fmt.Errorf("%w: нет сеанса раньше %s", ErrUnknownSession, to)
+		}
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return from, to, nil
+}
+
+// ResolveSession преобразует ссылку на сеанс в date_scraped. Ссылка — это ID запуска
+// из scrape_runs, точное значение date_scraped или дата: берется последний сеанс
+// не позже конца этого дня (даже если в сам день скрейпинга не было).
+func (r *Repository) ResolveSession(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		run, err := r.GetScrapeRun(id)
+		if err != nil {
+			return "", err
+		}
+		if run == nil {
+			return "", fmt.Errorf("%w: запуск #%d не существует", ErrUnknownSession, id)
+		}
+		return r.existingSession(run.DateScraped, fmt.Sprintf("запуск #%d не сохранил данных", id))
+	}
+
+	exists, err := r.SessionExists(ref)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return ref, nil
+	}
+
+	date, err := dateparse.Parse(ref)
+	if err != nil || date.Precision != dateparse.PrecisionDay {
+		return "", fmt.Errorf("%w: неверная ссылка %q (ожидается ID запуска или дата)", ErrUnknownSession, ref)
+	}
+
+	session, err := r.GetLatestSession(date.Time.AddDate(0, 0, 1).Format("2006-01-02"))
+	if err != nil {
+		return "", err
+	}
+	if session == "" {
+		return "", fmt.Errorf("%w: нет сеансов до %s включительно", ErrUnknownSession, date.ISO())
+	}
+	return session, nil
+}
+
+// ResolveAsOf определяет сеанс, по состоянию на который отвечает API: пустая ссылка —
+// последний сеанс (пустая строка, если данных нет), остальные — как в ResolveSession.
+func (r *Repository) ResolveAsOf(ref string) (string, error) {
+	if strings.TrimSpace(ref) == "" {
+		return r.GetLatestSession("")
+	}
+	return r.ResolveSession(ref)
+}
+
+// existingSession проверяет, что у сеанса есть данные
+func (r *Repository) existingSession(session, reason string) (string, error) {
+	exists, err := r.SessionExists(session)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrUnknownSession, reason)
+	}
+	return session, nil
+}
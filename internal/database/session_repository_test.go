@@ -0,0 +1,112 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"etf-scraper/internal/models"
+)
+
+func newSessionRepo(t *testing.T) *Repository {
+	t.Helper()
+	db, err := NewDatabase(t.TempDir() + "/sessions.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewRepository(db)
+}
+
+func TestResolveSession(t *testing.T) {
+	repo := newSessionRepo(t)
+
+	for _, session := range []string{"2024-03-15 10:00:00", "2024-05-01 09:30:00", "2024-05-01 18:00:00"} {
+		if err := repo.SaveETFs([]models.ETFData{{DateScraped: session, Ticker: "TMOS"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saveRun := func(session, status string) int64 {
+		id, err := repo.SaveScrapeRun(models.ScrapeRun{
+			StartedAt: session, FinishedAt: session, DateScraped: session, Status: status,
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	okRun := saveRun("2024-03-15 10:00:00", "success")
+	// Неудачный запуск без сохраненных данных
+	failedRun := saveRun("2024-04-01 12:00:00", "failed")
+
+	tests := []struct {
+		ref  string
+		want string // пусто — ожидается ErrUnknownSession
+	}{
+		{fmt.Sprint(okRun), "2024-03-15 10:00:00"},
+		{fmt.Sprint(failedRun), ""},
+		{"999", ""},
+		{"2024-05-01 09:30:00", "2024-05-01 09:30:00"},
+		// Дата — последний сеанс не позже конца дня
+		{"2024-05-01", "2024-05-01 18:00:00"},
+		{" 2024-05-01 ", "2024-05-01 18:00:00"},
+		{"2024-04-30", "2024-03-15 10:00:00"},
+		{"30.04.2024", "2024-03-15 10:00:00"},
+		{"2024-03-15", "2024-03-15 10:00:00"},
+		{"2030-01-01", "2024-05-01 18:00:00"},
+		// До первого сеанса
+		{"2024-03-14", ""},
+		// Точное время, которого нет среди сеансов, и неполные даты не принимаются
+		{"2024-05-01 12:00:00", ""},
+		{"2024-05", ""},
+		{"garbage", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := repo.ResolveSession(tt.ref)
+		if tt.want == "" {
+			if !errors.Is(err, ErrUnknownSession) {
+				t.Errorf("ResolveSession(%q) = %q, %v; want ErrUnknownSession", tt.ref, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ResolveSession(%q) = %q, %v; want %q", tt.ref, got, err, tt.want)
+		}
+	}
+
+	// Пустая ссылка в ResolveAsOf — последний сеанс
+	for _, ref := range []string{"", "  "} {
+		if got, err := repo.ResolveAsOf(ref); err != nil || got != "2024-05-01 18:00:00" {
+			t.Errorf("ResolveAsOf(%q) = %q, %v", ref, got, err)
+		}
+	}
+	if got, err := repo.ResolveAsOf("2024-04-30"); err != nil || got != "2024-03-15 10:00:00" {
+		t.Errorf("ResolveAsOf(2024-04-30) = %q, %v", got, err)
+	}
+	if _, err := repo.ResolveAsOf("2024-01-01"); !errors.Is(err, ErrUnknownSession) {
+		t.Errorf("ResolveAsOf(2024-01-01) error = %v, want ErrUnknownSession", err)
+	}
+
+	// Пустой from в ResolveRange — сеанс перед to
+	if from, to, err := repo.ResolveRange("", ""); err != nil || from != "2024-05-01 09:30:00" || to != "2024-05-01 18:00:00" {
+		t.Errorf("ResolveRange() = %q, %q, %v", from, to, err)
+	}
+	if _, _, err := repo.ResolveRange("", "2024-03-15"); !errors.Is(err, ErrUnknownSession) {
+		t.Errorf("ResolveRange(to first session) error = %v, want ErrUnknownSession", err)
+	}
+}
+
+func TestResolveAsOfEmptyDatabase(t *testing.T) {
+	repo := newSessionRepo(t)
+
+	if got, err := repo.ResolveAsOf(""); err != nil || got != "" {
+		t.Errorf("ResolveAsOf(\"\") = %q, %v; want empty session", got, err)
+	}
+	if _, err := repo.ResolveAsOf("2024-05-01"); !errors.Is(err, ErrUnknownSession) {
+		t.Errorf("ResolveAsOf(date) error = %v, want ErrUnknownSession", err)
+	}
+	if _, _, err := repo.ResolveRange("", ""); !errors.Is(err, ErrUnknownSession) {
+		t.Errorf("ResolveRange() error = %v, want ErrUnknownSession", err)
+	}
+}
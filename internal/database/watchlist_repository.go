@@ -0,0 +1,270 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"etf-scraper/internal/models"
+)
+
+// CreateWatchlist создает список наблюдения и возвращает его ID
+func (r *Repository) CreateWatchlist(name string, tickers []string) (int64, error) {
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("INSERT INTO watchlists (name, created_at) VALUES (?, ?)",
+		name, time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания списка: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertWatchlistTickers(tx, id, tickers); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// SetWatchlistTickers заменяет тикеры списка
+func (r *Repository) SetWatchlistTickers(id int64, tickers []string) error {
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM watchlist_tickers WHERE watchlist_id = ?", id); err != nil {
+		return err
+	}
+	if err := insertWatchlistTickers(tx, id, tickers); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertWatchlistTickers(tx *sql.Tx, id int64, tickers []string) error {
+	for _, ticker := range tickers {
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO watchlist_tickers (watchlist_id, ticker) VALUES (?, ?)", id, ticker,
+		); err != nil {
+			return fmt.Errorf("ошибка сохранения тикера %s: %w", ticker, err)
+		}
+	}
+	return nil
+}
+
+// DeleteWatchlist удаляет список вместе с правилами и оповещениями; false, если списка нет
+func (r *Repository) DeleteWatchlist(id int64) (bool, error) {
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		"DELETE FROM alerts WHERE watchlist_id = ?",
+		"DELETE FROM alert_rules WHERE watchlist_id = ?",
+		"DELETE FROM watchlist_tickers WHERE watchlist_id = ?",
+	} {
+		if _, err := tx.Exec(query, id); err != nil {
+			return false, err
+		}
+	}
+
+	res, err := tx.Exec("DELETE FROM watchlists WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, tx.Commit()
+}
+
+// GetWatchlists возвращает все списки с тикерами и правилами
+func (r *Repository) GetWatchlists() ([]models.Watchlist, error) {
+	rows, err := r.db.DB.Query("SELECT id, name, created_at FROM watchlists ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var watchlists []models.Watchlist
+	for rows.Next() {
+		var wl models.Watchlist
+		if err := rows.Scan(&wl.ID, &wl.Name, &wl.CreatedAt); err != nil {
+			return nil, err
+		}
+		watchlists = append(watchlists, wl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range watchlists {
+		if err := r.loadWatchlistDetails(&watchlists[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return watchlists, nil
+}
+
+// GetWatchlist возвращает список по ID (nil, если не найден)
+func (r *Repository) GetWatchlist(id int64) (*models.Watchlist, error) {
+	var wl models.Watchlist
+	err := r.db.DB.QueryRow("SELECT id, name, created_at FROM watchlists WHERE id = ?", id).
+		Scan(&wl.ID, &wl.Name, &wl.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadWatchlistDetails(&wl); err != nil {
+		return nil, err
+	}
+	return &wl, nil
+}
+
+// loadWatchlistDetails загружает тикеры и правила списка
+func (r *Repository) loadWatchlistDetails(wl *models.Watchlist) error {
+	rows, err := r.db.DB.Query(
+		"SELECT ticker FROM watchlist_tickers WHERE watchlist_id = ? ORDER BY ticker", wl.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	wl.Tickers = nil
+	for rows.Next() {
+		var ticker string
+		if err := rows.Scan(&ticker); err != nil {
+			return err
+		}
+		wl.Tickers = append(wl.Tickers, ticker)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	wl.Rules, err = r.getAlertRules(wl.ID)
+	return err
+}
+
+// CreateAlertRule сохраняет правило оповещения и возвращает его ID
+func (r *Repository) CreateAlertRule(rule models.AlertRule) (int64, error) {
+	res, err := r.db.DB.Exec(`
+		INSERT INTO alert_rules (watchlist_id, kind, threshold, notifier, target, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, rule.WatchlistID, rule.Kind, rule.Threshold, rule.Notifier, rule.Target,
+		time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сохранения правила: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// DeleteAlertRule удаляет правило списка; false, если правила нет
+func (r *Repository) DeleteAlertRule(watchlistID, ruleID int64) (bool, error) {
+	res, err := r.db.DB.Exec("DELETE FROM alert_rules WHERE id = ? AND watchlist_id = ?", ruleID, watchlistID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *Repository) getAlertRules(watchlistID int64) ([]models.AlertRule, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT id, watchlist_id, kind, threshold, notifier, COALESCE(target, ''), created_at
+		FROM alert_rules
+		WHERE watchlist_id = ?
+		ORDER BY id
+	`, watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []models.AlertRule
+	for rows.Next() {
+		var rule models.AlertRule
+		if err := rows.Scan(
+			&rule.ID, &rule.WatchlistID, &rule.Kind, &rule.Threshold,
+			&rule.Notifier, &rule.Target, &rule.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// SaveAlert записывает оповещение; возвращает ID и false, если оно уже было записано
+func (r *Repository) SaveAlert(alert models.Alert) (int64, bool, error) {
+	res, err := r.db.DB.Exec(`
+		INSERT OR IGNORE INTO alerts (
+			rule_id, watchlist_id, ticker, date_scraped, kind, message, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, alert.RuleID, alert.WatchlistID, alert.Ticker, alert.DateScraped, alert.Kind,
+		alert.Message, alert.CreatedAt)
+	if err != nil {
+		return 0, false, fmt.Errorf("ошибка сохранения оповещения: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return 0, false, err
+	}
+	id, err := res.LastInsertId()
+	return id, true, err
+}
+
+// UpdateAlertDelivery отмечает результат доставки оповещения
+func (r *Repository) UpdateAlertDelivery(id int64, delivered bool, errText string) error {
+	_, err := r.db.DB.Exec("UPDATE alerts SET delivered = ?, error = ? WHERE id = ?", delivered, errText, id)
+	return err
+}
+
+// GetAlerts возвращает последние оповещения
+func (r *Repository) GetAlerts(limit int) ([]models.Alert, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT a.id, a.rule_id, a.watchlist_id, COALESCE(w.name, ''), a.ticker, a.date_scraped,
+			a.kind, a.message, a.delivered, COALESCE(a.error, ''), a.created_at
+		FROM alerts a
+		LEFT JOIN watchlists w ON w.id = a.watchlist_id
+		ORDER BY a.id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []models.Alert
+	for rows.Next() {
+		var a models.Alert
+		if err := rows.Scan(
+			&a.ID, &a.RuleID, &a.WatchlistID, &a.WatchlistName, &a.Ticker, &a.DateScraped,
+			&a.Kind, &a.Message, &a.Delivered, &a.Error, &a.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+
+	return alerts, rows.Err()
+}
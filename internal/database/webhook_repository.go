@@ -0,0 +1,144 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"etf-scraper/internal/models"
+)
+
+// webhookColumns перечисляет колонки webhooks в порядке сканирования scanWebhook
+const webhookColumns = `id, url, COALESCE(event_types, ''), COALESCE(tickers, ''), secret, active, created_at`
+
+// CreateWebhook сохраняет подписку и возвращает ее ID
+func (r *Repository) CreateWebhook(wh models.Webhook) (int64, error) {
+	res, err := r.db.DB.Exec(`
+		INSERT INTO webhooks (url, event_types, tickers, secret, active, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, wh.URL, strings.Join(wh.EventTypes, ","), strings.Join(wh.Tickers, ","),
+		wh.Secret, wh.Active, time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сохранения вебхука: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// GetWebhooks возвращает все подписки
+func (r *Repository) GetWebhooks() ([]models.Webhook, error) {
+	rows, err := r.db.DB.Query(`SELECT ` + webhookColumns + ` FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		wh, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, wh)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// GetWebhook возвращает подписку по ID (nil, если не найдена)
+func (r *Repository) GetWebhook(id int64) (*models.Webhook, error) {
+	wh, err := scanWebhook(r.db.DB.QueryRow(`SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wh, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом доставок; false, если подписки нет
+func (r *Repository) DeleteWebhook(id int64) (bool, error) {
+	tx, err := r.db.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM webhook_deliveries WHERE webhook_id = ?", id); err != nil {
+		return false, err
+	}
+	res, err := tx.Exec("DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, tx.Commit()
+}
+
+// SaveWebhookDelivery записывает результат доставки в журнал
+func (r *Repository) SaveWebhookDelivery(d models.WebhookDelivery) (int64, error) {
+	res, err := r.db.DB.Exec(`
+		INSERT INTO webhook_deliveries (
+			webhook_id, delivery_id, event_type, payload, attempts,
+			status_code, success, error, duration_ms, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, d.WebhookID, d.DeliveryID, d.EventType, d.Payload, d.Attempts,
+		d.StatusCode, d.Success, d.Error, d.DurationMs, d.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сохранения доставки: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// GetWebhookDeliveries возвращает последние доставки подписки
+func (r *Repository) GetWebhookDeliveries(webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT id, webhook_id, delivery_id, event_type, payload, attempts,
+			COALESCE(status_code, 0), success, COALESCE(error, ''), COALESCE(duration_ms, 0), created_at
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(
+			&d.ID, &d.WebhookID, &d.DeliveryID, &d.EventType, &d.Payload, &d.Attempts,
+			&d.StatusCode, &d.Success, &d.Error, &d.DurationMs, &d.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// scanWebhook сканирует строку webhooks, выбранную через webhookColumns
+func scanWebhook(row interface{ Scan(...interface{}) error }) (models.Webhook, error) {
+	var wh models.Webhook
+	var eventTypes, tickers string
+	err := row.Scan(&wh.ID, &wh.URL, &eventTypes, &tickers, &wh.Secret, &wh.Active, &wh.CreatedAt)
+	wh.EventTypes = splitList(eventTypes)
+	wh.Tickers = splitList(tickers)
+	return wh, err
+}
+
+// splitList разбирает список через запятую; пустая строка дает пустой список
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
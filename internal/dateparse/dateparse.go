@@ -0,0 +1,234 @@
+// Package dateparse разбирает даты в русском и числовом форматах:
+// "15 августа 2019", "август 2019", "янв. 2020", "01.03.2018", "2018-03-01", "03.2018", "2020".
+package dateparse
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Precision задает точность разобранной даты
+type Precision int
+
+const (
+	PrecisionDay Precision = iota
+	PrecisionMonth
+	PrecisionYear
+)
+
+var (
+	// ErrEmpty возвращается для пустой строки
+	ErrEmpty = errors.New("пустое значение")
+	// ErrNoData возвращается для явных маркеров отсутствия данных
+	ErrNoData = errors.New("нет данных")
+)
+
+// SyntaxError описывает строку, которую не удалось разобрать как дату
+type SyntaxError struct {
+	Input  string
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("не удалось распарсить дату %q: %s", e.Input, e.Reason)
+}
+
+// Date представляет разобранную дату; для неполных дат день и месяц равны 1
+type Date struct {
+	Time      time.Time
+	Precision Precision
+}
+
+// ISO возвращает дату в формате ISO 8601 с учетом точности:
+// "2006-01-02", "2006-01" или "2006"
+func (d Date) ISO() string {
+	switch d.Precision {
+	case PrecisionMonth:
+		return d.Time.Format("2006-01")
+	case PrecisionYear:
+		return d.Time.Format("2006")
+	default:
+		return d.Time.Format("2006-01-02")
+	}
+}
+
+// months сопоставляет основы названий месяцев с номерами.
+// Поиск идет по префиксу, поэтому покрываются родительный и именительный
+// падежи ("января", "январь") и сокращения ("янв.", "сент.").
+var months = []struct {
+	prefix string
+	month  time.Month
+}{
+	{"янв", time.January},
+	{"фев", time.February},
+	{"мар", time.March},
+	{"апр", time.April},
+	{"мая", time.May},
+	{"май", time.May},
+	{"июн", time.June},
+	{"июл", time.July},
+	{"авг", time.August},
+	{"сен", time.September},
+	{"окт", time.October},
+	{"ноя", time.November},
+	{"дек", time.December},
+	{"jan", time.January},
+	{"feb", time.February},
+	{"mar", time.March},
+	{"apr", time.April},
+	{"may", time.May},
+	{"jun", time.June},
+	{"jul", time.July},
+	{"aug", time.August},
+	{"sep", time.September},
+	{"oct", time.October},
+	{"nov", time.November},
+	{"dec", time.December},
+}
+
+// noDataMarkers перечисляет значения, означающие отсутствие даты
+var noDataMarkers = []string{"—", "–", "-", "н/д", "n/a", "нет данных"}
+
+var (
+	reNumericDMY = regexp.MustCompile(`^(\d{1,2})[./](\d{1,2})[./](\d{2}|\d{4})$`)
+	reNumericMY  = regexp.MustCompile(`^(\d{1,2})[./](\d{4})$`)
+	reISO        = regexp.MustCompile(`^(\d{4})-(\d{2})(?:-(\d{2}))?$`)
+	reYear       = regexp.MustCompile(`^(\d{4})$`)
+	reTextDMY    = regexp.MustCompile(`^(\d{1,2})\s+(\p{L}+)\.?\s+(\d{4})$`)
+	reTextMY     = regexp.MustCompile(`^(\p{L}+)\.?\s+(\d{4})$`)
+)
+
+// Parse разбирает дату
+func Parse(text string) (Date, error) {
+	original := text
+	s := normalize(text)
+
+	if s == "" {
+		return Date{}, ErrEmpty
+	}
+	for _, marker := range noDataMarkers {
+		if s == marker {
+			return Date{}, ErrNoData
+		}
+	}
+
+	if m := reISO.FindStringSubmatch(s); m != nil {
+		day := "1"
+		precision := PrecisionMonth
+		if m[3] != "" {
+			day, precision = m[3], PrecisionDay
+		}
+		return build(original, m[1], m[2], day, precision)
+	}
+
+	if m := reNumericDMY.FindStringSubmatch(s); m != nil {
+		return build(original, expandYear(m[3]), m[2], m[1], PrecisionDay)
+	}
+
+	if m := reNumericMY.FindStringSubmatch(s); m != nil {
+		return build(original, m[2], m[1], "1", PrecisionMonth)
+	}
+
+	if m := reYear.FindStringSubmatch(s); m != nil {
+		return build(original, m[1], "1", "1", PrecisionYear)
+	}
+
+	if m := reTextDMY.FindStringSubmatch(s); m != nil {
+		month, ok := lookupMonth(m[2])
+		if !ok {
+			return Date{}, &SyntaxError{Input: original, Reason: fmt.Sprintf("неизвестный месяц %q", m[2])}
+		}
+		return build(original, m[3], strconv.Itoa(int(month)), m[1], PrecisionDay)
+	}
+
+	if m := reTextMY.FindStringSubmatch(s); m != nil {
+		month, ok := lookupMonth(m[1])
+		if !ok {
+			return Date{}, &SyntaxError{Input: original, Reason: fmt.Sprintf("неизвестный месяц %q", m[1])}
+		}
+		return build(original, m[2], strconv.Itoa(int(month)), "1", PrecisionMonth)
+	}
+
+	return Date{}, &SyntaxError{Input: original, Reason: "неизвестный формат"}
+}
+
+// pivotYear — двузначные годы больше него относятся к XX веку: "98" → 1998, "24" → 2024
+const pivotYear = 50
+
+// expandYear дополняет двузначный год до четырех цифр
+func expandYear(year string) string {
+	if len(year) != 2 {
+		return year
+	}
+	if y, _ := strconv.Atoi(year); y > pivotYear {
+		return "19" + year
+	}
+	return "20" + year
+}
+
+// ParseISO разбирает дату и возвращает ее в формате ISO 8601.
+// Для пустых значений и маркеров отсутствия данных возвращает пустую строку без ошибки.
+func ParseISO(text string) (string, error) {
+	d, err := Parse(text)
+	if errors.Is(err, ErrEmpty) || errors.Is(err, ErrNoData) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return d.ISO(), nil
+}
+
+// normalize приводит строку к нижнему регистру и схлопывает пробелы
+func normalize(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, text)
+	text = strings.ReplaceAll(text, "*", "")
+	text = strings.TrimSuffix(strings.TrimSpace(text), "г.")
+	text = strings.TrimSuffix(strings.TrimSpace(text), " года")
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// lookupMonth находит номер месяца по названию или сокращению
+func lookupMonth(name string) (time.Month, bool) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	if len([]rune(name)) < 3 {
+		return 0, false
+	}
+	for _, m := range months {
+		if strings.HasPrefix(name, m.prefix) {
+			return m.month, true
+		}
+	}
+	return 0, false
+}
+
+// build собирает дату и проверяет ее корректность
+func build(original, year, month, day string, precision Precision) (Date, error) {
+	y, errY := strconv.Atoi(year)
+	m, errM := strconv.Atoi(month)
+	d, errD := strconv.Atoi(day)
+	if errY != nil || errM != nil || errD != nil {
+		return Date{}, &SyntaxError{Input: original, Reason: "нечисловые компоненты"}
+	}
+	if m < 1 || m > 12 {
+		return Date{}, &SyntaxError{Input: original, Reason: fmt.Sprintf("месяц вне диапазона: %d", m)}
+	}
+
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	// time.Date нормализует 31 февраля в март — такие даты считаем ошибочными
+	if t.Day() != d || t.Month() != time.Month(m) {
+		return Date{}, &SyntaxError{Input: original, Reason: "несуществующий день"}
+	}
+
+	return Date{Time: t, Precision: precision}, nil
+}
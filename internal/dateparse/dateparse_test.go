@@ -0,0 +1,132 @@
+package dateparse
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input     string
+		want      string
+		precision Precision
+	}{
+		// Числовые форматы
+		{"01.03.2018", "2018-03-01", PrecisionDay},
+		{"1/3/2018", "2018-03-01", PrecisionDay},
+		{"2018-03-01", "2018-03-01", PrecisionDay},
+		{"2018-03", "2018-03", PrecisionMonth},
+		{"03.2018", "2018-03", PrecisionMonth},
+		{"2020", "2020", PrecisionYear},
+		{"2020 г.", "2020", PrecisionYear},
+
+		// Двузначный год: до pivotYear включительно — XXI век, позже — XX
+		{"01.03.24", "2024-03-01", PrecisionDay},
+		{"01.03.50", "2050-03-01", PrecisionDay},
+		{"01.03.51", "1951-03-01", PrecisionDay},
+		{"01.03.98", "1998-03-01", PrecisionDay},
+
+		// Названия месяцев: падежи, сокращения, регистр
+		{"15 августа 2019", "2019-08-15", PrecisionDay},
+		{"15 августа 2019 года", "2019-08-15", PrecisionDay},
+		{"1 мая 2021", "2021-05-01", PrecisionDay},
+		{"август 2019", "2019-08", PrecisionMonth},
+		{"Май 2021", "2021-05", PrecisionMonth},
+		{"янв. 2020", "2020-01", PrecisionMonth},
+		{"сент. 2020", "2020-09", PrecisionMonth},
+		{"3 Dec 2022", "2022-12-03", PrecisionDay},
+		{"September 2022", "2022-09", PrecisionMonth},
+		{"  01.03.2018* ", "2018-03-01", PrecisionDay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			d, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			if d.Precision != tt.precision {
+				t.Errorf("Parse(%q) precision = %v, want %v", tt.input, d.Precision, tt.precision)
+			}
+			if got := d.ISO(); got != tt.want {
+				t.Errorf("Parse(%q).ISO() = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIncompleteDatesStartOfPeriod(t *testing.T) {
+	d, err := Parse("март 2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Time.Format("2006-01-02"); got != "2020-03-01" {
+		t.Errorf("Time = %s, want 2020-03-01", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  error
+	}{
+		{"", ErrEmpty},
+		{"   ", ErrEmpty},
+		{"—", ErrNoData},
+		{"-", ErrNoData},
+		{"н/д", ErrNoData},
+		{"N/A", ErrNoData},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse(tt.input); !errors.Is(err, tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestParseSyntaxErrors(t *testing.T) {
+	inputs := []string{
+		"31.02.2020", // несуществующий день
+		"01.13.2020", // месяц вне диапазона
+		"15 ма 2019", // слишком короткое название месяца
+		"15 foo 2019",
+		"вчера",
+		"2020-1-1",
+	}
+
+	for _, input := range inputs {
+		_, err := Parse(input)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) error = %v, want *SyntaxError", input, err)
+		}
+	}
+}
+
+func TestParseISO(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"15 августа 2019", "2019-08-15"},
+		{"август 2019", "2019-08"},
+		{"", ""},
+		{"—", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseISO(tt.input)
+		if err != nil {
+			t.Errorf("ParseISO(%q) error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseISO(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := ParseISO("31.02.2020"); err == nil {
+		t.Error("ParseISO(\"31.02.2020\") must return an error")
+	}
+}
@@ -0,0 +1,150 @@
+// Package diff сравнивает два сеанса скрейпинга: появившиеся и исчезнувшие
+// фонды и изменения TER, статуса торгов, названия и СЧА
+package diff
+
+import (
+	"math"
+	"sort"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+// Поля, изменения которых попадают в сравнение
+const (
+	FieldTER         = "terPercent"
+	FieldTradeStatus = "tradeStatus"
+	FieldFundName    = "fundName"
+	FieldNAV         = "navMillionRub"
+)
+
+// Run сравнивает сеансы по ссылкам from и to (см. database.Repository.ResolveSession).
+// Пустой to означает последний сеанс, пустой from — сеанс перед to.
+func Run(repo *database.Repository, fromRef, toRef string) (*models.SnapshotDiff, error) {
+	from, to, err := repo.ResolveRange(fromRef, toRef)
+	if err != nil {
+		return nil, err
+	}
+
+	fromData, err := repo.GetETFsBySession(from)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := repo.GetETFsBySession(to)
+	if err != nil {
+		return nil, err
+	}
+
+	d := Compare(fromData, toData)
+	d.From = from
+	d.To = to
+	return d, nil
+}
+
+// Compare сравнивает два набора записей по тикерам
+func Compare(from, to []models.ETFData) *models.SnapshotDiff {
+	d := &models.SnapshotDiff{
+		Added:   []models.DiffFund{},
+		Removed: []models.DiffFund{},
+		Changed: []models.DiffChange{},
+	}
+
+	before := byTicker(from)
+	after := byTicker(to)
+
+	for ticker, etf := range after {
+		old, ok := before[ticker]
+		if !ok {
+			d.Added = append(d.Added, toDiffFund(etf))
+			continue
+		}
+		if changes := compareFund(old, etf); len(changes) > 0 {
+			d.Changed = append(d.Changed, models.DiffChange{
+				Ticker:   ticker,
+				FundName: etf.FundName,
+				Changes:  changes,
+			})
+		}
+	}
+	for ticker, etf := range before {
+		if _, ok := after[ticker]; !ok {
+			d.Removed = append(d.Removed, toDiffFund(etf))
+		}
+	}
+
+	sort.Slice(d.Added, func(i, j int) bool { return d.Added[i].Ticker < d.Added[j].Ticker })
+	sort.Slice(d.Removed, func(i, j int) bool { return d.Removed[i].Ticker < d.Removed[j].Ticker })
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].Ticker < d.Changed[j].Ticker })
+
+	return d
+}
+
+// compareFund возвращает изменения полей одного фонда
+func compareFund(old, cur models.ETFData) []models.FieldChange {
+	var changes []models.FieldChange
+
+	if c, ok := numberChange(FieldTER, old.TERPercent, cur.TERPercent, false); ok {
+		changes = append(changes, c)
+	}
+	if old.TradeStatus != cur.TradeStatus {
+		changes = append(changes, models.FieldChange{Field: FieldTradeStatus, Old: old.TradeStatus, New: cur.TradeStatus})
+	}
+	if old.FundName != cur.FundName {
+		changes = append(changes, models.FieldChange{Field: FieldFundName, Old: old.FundName, New: cur.FundName})
+	}
+	if c, ok := numberChange(FieldNAV, old.NAVMillionRub, cur.NAVMillionRub, true); ok {
+		changes = append(changes, c)
+	}
+
+	return changes
+}
+
+// numberChange сравнивает числовые значения; пустое значение тоже считается изменением
+func numberChange(field string, old, cur *float64, withPercent bool) (models.FieldChange, bool) {
+	c := models.FieldChange{Field: field, Old: old, New: cur}
+
+	switch {
+	case old == nil && cur == nil:
+		return c, false
+	case old == nil || cur == nil:
+		return c, true
+	}
+
+	delta := round(*cur - *old)
+	if delta == 0 {
+		return c, false
+	}
+	c.Delta = &delta
+	if withPercent && *old != 0 {
+		pct := round(delta / math.Abs(*old) * 100)
+		c.DeltaPercent = &pct
+	}
+	return c, true
+}
+
+// round убирает шум вычислений с плавающей точкой, чтобы совпадающие
+// значения не давали ложных изменений
+func round(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+// byTicker индексирует записи по тикеру; при повторах берется первая запись
+func byTicker(data []models.ETFData) map[string]models.ETFData {
+	index := make(map[string]models.ETFData, len(data))
+	for _, etf := range data {
+		if _, ok := index[etf.Ticker]; !ok {
+			index[etf.Ticker] = etf
+		}
+	}
+	return index
+}
+
+func toDiffFund(etf models.ETFData) models.DiffFund {
+	return models.DiffFund{
+		Ticker:        etf.Ticker,
+		FundName:      etf.FundName,
+		ManagementCo:  etf.ManagementCo,
+		AssetClass:    etf.AssetClass,
+		NAVMillionRub: etf.NAVMillionRub,
+	}
+}
@@ -0,0 +1,90 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"etf-scraper/internal/models"
+)
+
+// fieldLabels — подписи полей для текстового вывода
+var fieldLabels = map[string]string{
+	FieldTER:         "TER",
+	FieldTradeStatus: "Статус",
+	FieldFundName:    "Название",
+	FieldNAV:         "СЧА, млн ₽",
+}
+
+// WriteText выводит сравнение в виде таблиц для терминала
+func WriteText(w io.Writer, d *models.SnapshotDiff) error {
+	fmt.Fprintf(w, "Сравнение сеансов: %s → %s\n", d.From, d.To)
+	fmt.Fprintf(w, "Добавлено: %d, исчезло: %d, изменилось: %d\n",
+		len(d.Added), len(d.Removed), len(d.Changed))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	writeFunds := func(title string, funds []models.DiffFund) {
+		if len(funds) == 0 {
+			return
+		}
+		fmt.Fprintf(tw, "\n%s:\n", title)
+		fmt.Fprintln(tw, "Тикер\tФонд\tУК\tСЧА, млн ₽")
+		for _, f := range funds {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Ticker, f.FundName, f.ManagementCo, formatNumber(f.NAVMillionRub))
+		}
+	}
+	writeFunds("Новые фонды", d.Added)
+	writeFunds("Исчезнувшие фонды", d.Removed)
+
+	if len(d.Changed) > 0 {
+		fmt.Fprintln(tw, "\nИзменения:")
+		fmt.Fprintln(tw, "Тикер\tПоле\tБыло\tСтало\tРазница")
+		for _, ch := range d.Changed {
+			for i, c := range ch.Changes {
+				ticker := ch.Ticker
+				if i > 0 {
+					ticker = ""
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+					ticker, fieldLabels[c.Field], formatValue(c.Old), formatValue(c.New), formatDelta(c))
+			}
+		}
+	}
+
+	return tw.Flush()
+}
+
+// formatValue форматирует значение поля
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case *float64:
+		return formatNumber(val)
+	case string:
+		if val == "" {
+			return "—"
+		}
+		return val
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// formatNumber форматирует число; пустое значение выводится прочерком
+func formatNumber(v *float64) string {
+	if v == nil {
+		return "—"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
+
+// formatDelta форматирует разницу числового поля
+func formatDelta(c models.FieldChange) string {
+	if c.Delta == nil {
+		return ""
+	}
+	if c.DeltaPercent == nil {
+		return fmt.Sprintf("%+.2f", *c.Delta)
+	}
+	return fmt.Sprintf("%+.2f (%+.2f%%)", *c.Delta, *c.DeltaPercent)
+}
@@ -0,0 +1,312 @@
+// Package dump выгружает всю историю etf_data в Parquet и NDJSON,
+// разбивая файлы по дате скрейпинга и описывая их в manifest.json
+package dump
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+	"etf-scraper/internal/parquet"
+)
+
+// Форматы выгрузки
+const (
+	FormatParquet = "parquet"
+	FormatNDJSON  = "ndjson"
+)
+
+// ManifestFile — имя файла манифеста в каталоге выгрузки
+const ManifestFile = "manifest.json"
+
+// Options задает параметры выгрузки
+type Options struct {
+	Dir     string
+	Formats []string
+	// Incremental выгружает только сеансы, которых еще нет в манифесте в нужном формате
+	// (в том числе загруженные импортом задним числом)
+	Incremental bool
+}
+
+// field описывает колонку выгрузки
+type field struct {
+	name     string
+	typ      parquet.Type
+	optional bool
+	value    func(models.ETFData) interface{}
+}
+
+// fields перечисляет колонки выгрузки в порядке etf_data
+var fields = []field{
+	{"date_scraped", parquet.String, false, func(e models.ETFData) interface{} { return e.DateScraped }},
+	{"ticker", parquet.String, false, func(e models.ETFData) interface{} { return e.Ticker }},
+	{"trade_status", parquet.String, false, func(e models.ETFData) interface{} { return e.TradeStatus }},
+	{"management_company", parquet.String, false, func(e models.ETFData) interface{} { return e.ManagementCo }},
+	{"asset_class", parquet.String, false, func(e models.ETFData) interface{} { return e.AssetClass }},
+	{"ter_percent", parquet.Double, true, func(e models.ETFData) interface{} { return e.TERPercent }},
+	{"ter_direction", parquet.String, false, func(e models.ETFData) interface{} { return e.TERDirection }},
+	{"ter_trend", parquet.String, false, func(e models.ETFData) interface{} { return string(e.TERTrend) }},
+	{"fund_name", parquet.String, false, func(e models.ETFData) interface{} { return e.FundName }},
+	{"management_style", parquet.String, false, func(e models.ETFData) interface{} { return e.ManagementStyle }},
+	{"target_index", parquet.String, false, func(e models.ETFData) interface{} { return e.TargetIndex }},
+	{"currency", parquet.String, false, func(e models.ETFData) interface{} { return e.Currency }},
+	{"start_date", parquet.String, false, func(e models.ETFData) interface{} { return e.StartDate }},
+	{"info_icon", parquet.String, false, func(e models.ETFData) interface{} { return e.InfoIcon }},
+	{"info_flags", parquet.String, false, func(e models.ETFData) interface{} { return models.JoinInfoFlags(e.InfoFlags) }},
+	{"price_change_6m", parquet.Double, true, func(e models.ETFData) interface{} { return e.PriceChange6M }},
+	{"price_change_2024", parquet.Double, true, func(e models.ETFData) interface{} { return e.PriceChange2024 }},
+	{"price_change_2023", parquet.Double, true, func(e models.ETFData) interface{} { return e.PriceChange2023 }},
+	{"price_change_2022", parquet.Double, true, func(e models.ETFData) interface{} { return e.PriceChange2022 }},
+	{"price_change_2021", parquet.Double, true, func(e models.ETFData) interface{} { return e.PriceChange2021 }},
+	{"price_change_2020", parquet.Double, true, func(e models.ETFData) interface{} { return e.PriceChange2020 }},
+	{"nav_million_rub", parquet.Double, true, func(e models.ETFData) interface{} { return e.NAVMillionRub }},
+	{"last_update_date", parquet.String, false, func(e models.ETFData) interface{} { return e.LastUpdateDate }},
+}
+
+// Schema возвращает описание колонок для манифеста
+func Schema() []models.DumpSchemaField {
+	schema := make([]models.DumpSchemaField, len(fields))
+	for i, f := range fields {
+		typ := "string"
+		if f.typ == parquet.Double {
+			typ = "double"
+		}
+		schema[i] = models.DumpSchemaField{Name: f.name, Type: typ, Nullable: f.optional}
+	}
+	return schema
+}
+
+// ParseFormats разбирает список форматов через запятую; пустая строка означает оба формата
+func ParseFormats(value string) ([]string, error) {
+	if value == "" {
+		return []string{FormatParquet, FormatNDJSON}, nil
+	}
+
+	var formats []string
+	for _, f := range strings.Split(value, ",") {
+		f = strings.TrimSpace(f)
+		if f != FormatParquet && f != FormatNDJSON {
+			return nil, fmt.Errorf("неизвестный формат выгрузки: %s", f)
+		}
+		formats = append(formats, f)
+	}
+	return formats, nil
+}
+
+// Run выполняет выгрузку и возвращает обновленный манифест
+func Run(repo *database.Repository, opts Options) (*models.DumpManifest, error) {
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога выгрузки: %w", err)
+	}
+
+	manifest := &models.DumpManifest{Files: []models.DumpFile{}}
+	if opts.Incremental {
+		previous, err := LoadManifest(opts.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if previous != nil {
+			manifest = previous
+		}
+	}
+
+	// Выгруженные файлы отслеживаются по паре сеанс/формат, а не по последней дате:
+	// импорт добавляет сеансы задним числом, а форматы можно выгружать по отдельности
+	exported := make(map[string]bool, len(manifest.Files))
+	sessionRows := make(map[string]bool)
+	for _, f := range manifest.Files {
+		exported[f.DateScraped+"|"+f.Format] = true
+		sessionRows[f.DateScraped] = true
+	}
+
+	sessions, err := repo.GetScrapeSessions("")
+	if err != nil {
+		return nil, err
+	}
+
+	written := 0
+	for _, session := range sessions {
+		var formats []string
+		for _, format := range opts.Formats {
+			if !exported[session+"|"+format] {
+				formats = append(formats, format)
+			}
+		}
+		if len(formats) == 0 {
+			continue
+		}
+
+		data, err := repo.GetETFsBySession(session)
+		if err != nil {
+			return nil, err
+		}
+
+		partition, name := partitionPath(session)
+		if err := os.MkdirAll(filepath.Join(opts.Dir, partition), 0o755); err != nil {
+			return nil, err
+		}
+
+		for _, format := range formats {
+			rel := filepath.Join(partition, name+"."+format)
+			if err := writeFile(filepath.Join(opts.Dir, rel), format, data); err != nil {
+				return nil, fmt.Errorf("ошибка записи %s: %w", rel, err)
+			}
+			manifest.Files = append(manifest.Files, models.DumpFile{
+				Path:        filepath.ToSlash(rel),
+				Format:      format,
+				Partition:   partition,
+				DateScraped: session,
+				Rows:        len(data),
+			})
+		}
+
+		if !sessionRows[session] {
+			sessionRows[session] = true
+			manifest.TotalRows += len(data)
+		}
+		if session > manifest.LastDateScraped {
+			manifest.LastDateScraped = session
+		}
+		written++
+	}
+	log.Printf("Сеансов выгружено: %d", written)
+
+	sort.SliceStable(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].DateScraped < manifest.Files[j].DateScraped
+	})
+
+	manifest.GeneratedAt = time.Now().Format(time.RFC3339)
+	manifest.Schema = Schema()
+
+	if err := saveManifest(opts.Dir, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// LoadManifest читает манифест из каталога (nil, если его нет)
+func LoadManifest(dir string) (*models.DumpManifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest models.DumpManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("ошибка чтения манифеста: %w", err)
+	}
+	return &manifest, nil
+}
+
+// saveManifest записывает манифест через временный файл
+func saveManifest(dir string, manifest *models.DumpManifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(dir, ManifestFile+".tmp")
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, ManifestFile))
+}
+
+// partitionPath возвращает каталог раздела и имя файла для сеанса скрейпинга
+func partitionPath(dateScraped string) (string, string) {
+	t, err := time.Parse("2006-01-02 15:04:05", dateScraped)
+	if err != nil {
+		safe := strings.NewReplacer(" ", "T", ":", "").Replace(dateScraped)
+		return "date=unknown", "part-" + safe
+	}
+	return "date=" + t.Format("2006-01-02"), "part-" + t.Format("20060102T150405")
+}
+
+// writeFile записывает данные сеанса в файл выбранного формата
+func writeFile(path, format string, data []models.ETFData) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch format {
+	case FormatParquet:
+		err = writeParquet(f, data)
+	case FormatNDJSON:
+		err = writeNDJSON(f, data)
+	default:
+		err = fmt.Errorf("неизвестный формат: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeParquet записывает сеанс в Parquet
+func writeParquet(f *os.File, data []models.ETFData) error {
+	columns := make([]parquet.Column, len(fields))
+	for i, fl := range fields {
+		columns[i] = parquet.Column{Name: fl.name, Type: fl.typ, Optional: fl.optional}
+	}
+
+	bw := bufio.NewWriter(f)
+	pw := parquet.NewWriter(bw, columns)
+	row := make([]interface{}, len(fields))
+	for _, etf := range data {
+		for i, fl := range fields {
+			row[i] = fl.value(etf)
+		}
+		if err := pw.Write(row); err != nil {
+			return err
+		}
+	}
+	if err := pw.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeNDJSON записывает сеанс построчно в JSON, сохраняя порядок колонок
+func writeNDJSON(f *os.File, data []models.ETFData) error {
+	bw := bufio.NewWriter(f)
+	var line bytes.Buffer
+
+	for _, etf := range data {
+		line.Reset()
+		line.WriteByte('{')
+		for i, fl := range fields {
+			if i > 0 {
+				line.WriteByte(',')
+			}
+			key, _ := json.Marshal(fl.name)
+			value, err := json.Marshal(fl.value(etf))
+			if err != nil {
+				return err
+			}
+			line.Write(key)
+			line.WriteByte(':')
+			line.Write(value)
+		}
+		line.WriteString("}\n")
+		if _, err := bw.Write(line.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
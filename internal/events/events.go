@@ -0,0 +1,246 @@
+// Package events выводит события фондов (новый фонд, исключение из списка,
+// приостановка торгов, изменение TER, переименование, пересечение порогов СЧА)
+// из сравнения соседних сеансов скрейпинга
+package events
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/diff"
+	"etf-scraper/internal/models"
+)
+
+// tradingStatus — статус торгов, при котором фонд считается торгуемым
+const tradingStatus = "торгуется"
+
+// Generate находит события сеанса dateScraped относительно предыдущего сеанса,
+// сохраняет их и возвращает новые. Для первого сеанса в БД событий нет.
+func Generate(repo *database.Repository, dateScraped string, navThresholds []float64) ([]models.Event, error) {
+	prev, err := repo.GetLatestSession(dateScraped)
+	if err != nil {
+		return nil, err
+	}
+	if prev == "" {
+		return nil, nil
+	}
+
+	prevData, err := repo.GetETFsBySession(prev)
+	if err != nil {
+		return nil, err
+	}
+	curData, err := repo.GetETFsBySession(dateScraped)
+	if err != nil {
+		return nil, err
+	}
+
+	events := Detect(prevData, curData, navThresholds)
+	for i := range events {
+		events[i].DateScraped = dateScraped
+		events[i].PrevDateScraped = prev
+	}
+
+	return repo.SaveEvents(events)
+}
+
+// Backfill строит события для всех сеансов в БД; уже сохраненные события не дублируются
+func Backfill(repo *database.Repository, navThresholds []float64) (int, error) {
+	sessions, err := repo.GetScrapeSessions("")
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, session := range sessions {
+		saved, err := Generate(repo, session, navThresholds)
+		if err != nil {
+			return total, fmt.Errorf("сеанс %s: %w", session, err)
+		}
+		total += len(saved)
+	}
+	return total, nil
+}
+
+// GenerateLogged вызывает Generate и пишет результат в лог; ошибка не прерывает запуск
+func GenerateLogged(repo *database.Repository, dateScraped string, navThresholds []float64) []models.Event {
+	saved, err := Generate(repo, dateScraped, navThresholds)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Не удалось построить события: %v", err)
+		return nil
+	}
+	log.Printf("Новых событий: %d", len(saved))
+	return saved
+}
+
+// Detect сравнивает два набора записей и возвращает события без дат сеансов
+func Detect(prev, cur []models.ETFData, navThresholds []float64) []models.Event {
+	d := diff.Compare(prev, cur)
+	before := byTicker(prev)
+	after := byTicker(cur)
+
+	var events []models.Event
+
+	for _, f := range d.Added {
+		events = append(events, newEvent(after[f.Ticker], models.EventListed,
+			fmt.Sprintf("%s: новый фонд «%s» (%s)", f.Ticker, f.FundName, f.ManagementCo), "", ""))
+	}
+	for _, f := range d.Removed {
+		events = append(events, newEvent(before[f.Ticker], models.EventDelisted,
+			fmt.Sprintf("%s: фонд «%s» исключен из списка", f.Ticker, f.FundName), "", ""))
+	}
+
+	for _, ch := range d.Changed {
+		old, etf := before[ch.Ticker], after[ch.Ticker]
+		for _, c := range ch.Changes {
+			switch c.Field {
+			case diff.FieldTER:
+				events = append(events, terEvent(old, etf))
+			case diff.FieldFundName:
+				events = append(events, newEvent(etf, models.EventRenamed,
+					fmt.Sprintf("%s: фонд переименован: «%s» → «%s»", etf.Ticker, old.FundName, etf.FundName),
+					old.FundName, etf.FundName))
+			case diff.FieldTradeStatus:
+				if e, ok := statusEvent(old, etf); ok {
+					events = append(events, e)
+				}
+			case diff.FieldNAV:
+				if e, ok := navEvent(old, etf, navThresholds); ok {
+					events = append(events, e)
+				}
+			}
+		}
+	}
+
+	// Отметка о приостановке могла появиться без смены статуса торгов
+	for ticker, etf := range after {
+		old, ok := before[ticker]
+		if !ok || !hasFlag(etf, models.InfoFlagSuspended) || hasFlag(old, models.InfoFlagSuspended) {
+			continue
+		}
+		if IsTrading(old.TradeStatus) && !IsTrading(etf.TradeStatus) {
+			continue // уже учтено по статусу торгов
+		}
+		events = append(events, newEvent(etf, models.EventSuspended,
+			fmt.Sprintf("%s: торги приостановлены", ticker), "", string(models.InfoFlagSuspended)))
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Ticker < events[j].Ticker })
+	return events
+}
+
+// terEvent описывает изменение TER
+func terEvent(old, etf models.ETFData) models.Event {
+	verb := "изменен"
+	if old.TERPercent != nil && etf.TERPercent != nil {
+		if *etf.TERPercent < *old.TERPercent {
+			verb = "снижен"
+		} else {
+			verb = "повышен"
+		}
+	}
+
+	oldValue, newValue := formatFloat(old.TERPercent), formatFloat(etf.TERPercent)
+	return newEvent(etf, models.EventTERChanged,
+		fmt.Sprintf("%s: TER %s с %s%% до %s%%", etf.Ticker, verb, oldValue, newValue),
+		oldValue, newValue)
+}
+
+// statusEvent описывает приостановку или возобновление торгов
+func statusEvent(old, etf models.ETFData) (models.Event, bool) {
+	wasTrading, trading := IsTrading(old.TradeStatus), IsTrading(etf.TradeStatus)
+
+	switch {
+	case wasTrading && !trading:
+		return newEvent(etf, models.EventSuspended,
+			fmt.Sprintf("%s: торги приостановлены (%s)", etf.Ticker, etf.TradeStatus),
+			old.TradeStatus, etf.TradeStatus), true
+	case !wasTrading && trading:
+		return newEvent(etf, models.EventResumed,
+			fmt.Sprintf("%s: торги возобновлены", etf.Ticker),
+			old.TradeStatus, etf.TradeStatus), true
+	}
+	return models.Event{}, false
+}
+
+// navEvent описывает пересечение порога СЧА; при пересечении нескольких
+// порогов за один сеанс берется самый дальний
+func navEvent(old, etf models.ETFData, thresholds []float64) (models.Event, bool) {
+	if old.NAVMillionRub == nil || etf.NAVMillionRub == nil {
+		return models.Event{}, false
+	}
+	before, after := *old.NAVMillionRub, *etf.NAVMillionRub
+
+	crossed, found := 0.0, false
+	for _, t := range thresholds {
+		up := before < t && after >= t
+		down := before >= t && after < t
+		if !up && !down {
+			continue
+		}
+		if !found || (up && t > crossed) || (down && t < crossed) {
+			crossed, found = t, true
+		}
+	}
+	if !found {
+		return models.Event{}, false
+	}
+
+	direction := "превысила"
+	if after < before {
+		direction = "опустилась ниже"
+	}
+	oldValue, newValue := formatFloat(old.NAVMillionRub), formatFloat(etf.NAVMillionRub)
+	return newEvent(etf, models.EventNAVThreshold,
+		fmt.Sprintf("%s: СЧА %s %s млн ₽ (%s → %s)", etf.Ticker, direction,
+			strconv.FormatFloat(crossed, 'f', -1, 64), oldValue, newValue),
+		oldValue, newValue), true
+}
+
+func newEvent(etf models.ETFData, kind, title, oldValue, newValue string) models.Event {
+	return models.Event{
+		Ticker:       etf.Ticker,
+		ManagementCo: etf.ManagementCo,
+		FundName:     etf.FundName,
+		Kind:         kind,
+		Title:        title,
+		OldValue:     oldValue,
+		NewValue:     newValue,
+	}
+}
+
+// IsTrading проверяет, что статус означает обычные торги
+func IsTrading(status string) bool {
+	return strings.ToLower(strings.TrimSpace(status)) == tradingStatus
+}
+
+func hasFlag(etf models.ETFData, flag models.InfoFlag) bool {
+	for _, f := range etf.InfoFlags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFloat форматирует число для заголовка; пустое значение — прочерк
+func formatFloat(v *float64) string {
+	if v == nil {
+		return "—"
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+// byTicker индексирует записи по тикеру
+func byTicker(data []models.ETFData) map[string]models.ETFData {
+	index := make(map[string]models.ETFData, len(data))
+	for _, etf := range data {
+		if _, ok := index[etf.Ticker]; !ok {
+			index[etf.Ticker] = etf
+		}
+	}
+	return index
+}
@@ -0,0 +1,282 @@
+// Package export выгружает данные ETF в CSV и XLSX
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"etf-scraper/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Column описывает колонку выгрузки
+type Column struct {
+	Key      string
+	HeaderRU string
+	HeaderEN string
+	text     func(models.ETFData) string
+	number   func(models.ETFData) *float64
+}
+
+// Columns перечисляет все доступные колонки в порядке по умолчанию.
+// Ключи совпадают с именами полей JSON в /api/etfs.
+var Columns = []Column{
+	textColumn("dateScraped", "Дата сбора", "Date scraped", func(e models.ETFData) string { return e.DateScraped }),
+	textColumn("ticker", "Тикер", "Ticker", func(e models.ETFData) string { return e.Ticker }),
+	textColumn("tradeStatus", "Статус торгов", "Trade status", func(e models.ETFData) string { return e.TradeStatus }),
+	textColumn("managementCo", "УК", "Management company", func(e models.ETFData) string { return e.ManagementCo }),
+	textColumn("assetClass", "Класс активов", "Asset class", func(e models.ETFData) string { return e.AssetClass }),
+	numberColumn("terPercent", "TER, %", "TER, %", func(e models.ETFData) *float64 { return e.TERPercent }),
+	textColumn("terTrend", "Изменение TER", "TER trend", func(e models.ETFData) string { return string(e.TERTrend) }),
+	textColumn("fundName", "Название фонда", "Fund name", func(e models.ETFData) string { return e.FundName }),
+	textColumn("managementStyle", "Стиль управления", "Management style", func(e models.ETFData) string { return e.ManagementStyle }),
+	textColumn("targetIndex", "Целевой индекс", "Target index", func(e models.ETFData) string { return e.TargetIndex }),
+	textColumn("currency", "Валюта", "Currency", func(e models.ETFData) string { return e.Currency }),
+	textColumn("startDate", "Дата запуска", "Start date", func(e models.ETFData) string { return e.StartDate }),
+	textColumn("infoFlags", "Отметки", "Info flags", func(e models.ETFData) string { return models.JoinInfoFlags(e.InfoFlags) }),
+	numberColumn("priceChange6M", "Изменение за 6 мес, %", "6M change, %", func(e models.ETFData) *float64 { return e.PriceChange6M }),
+	numberColumn("priceChange2024", "2024, %", "2024, %", func(e models.ETFData) *float64 { return e.PriceChange2024 }),
+	numberColumn("priceChange2023", "2023, %", "2023, %", func(e models.ETFData) *float64 { return e.PriceChange2023 }),
+	numberColumn("priceChange2022", "2022, %", "2022, %", func(e models.ETFData) *float64 { return e.PriceChange2022 }),
+	numberColumn("priceChange2021", "2021, %", "2021, %", func(e models.ETFData) *float64 { return e.PriceChange2021 }),
+	numberColumn("priceChange2020", "2020, %", "2020, %", func(e models.ETFData) *float64 { return e.PriceChange2020 }),
+	numberColumn("navMillionRub", "СЧА, млн ₽", "NAV, RUB mln", func(e models.ETFData) *float64 { return e.NAVMillionRub }),
+	textColumn("lastUpdateDate", "Дата обновления", "Last update", func(e models.ETFData) string { return e.LastUpdateDate }),
+}
+
+func textColumn(key, ru, en string, f func(models.ETFData) string) Column {
+	return Column{Key: key, HeaderRU: ru, HeaderEN: en, text: f}
+}
+
+func numberColumn(key, ru, en string, f func(models.ETFData) *float64) Column {
+	return Column{Key: key, HeaderRU: ru, HeaderEN: en, number: f}
+}
+
+// SelectColumns возвращает колонки по ключам; пустой список означает все колонки
+func SelectColumns(keys []string) ([]Column, error) {
+	if len(keys) == 0 {
+		return Columns, nil
+	}
+
+	var selected []Column
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		found := false
+		for _, c := range Columns {
+			if strings.EqualFold(c.Key, key) {
+				selected = append(selected, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("неизвестная колонка: %s", key)
+		}
+	}
+
+	if len(selected) == 0 {
+		return Columns, nil
+	}
+	return selected, nil
+}
+
+// Options задает параметры выгрузки
+type Options struct {
+	Columns []Column
+	// Lang выбирает язык заголовков: "ru" (по умолчанию) или "en"
+	Lang string
+	// Delimiter — разделитель CSV; ';' нужен для Excel с русской локалью
+	Delimiter rune
+	// DecimalComma выводит дробные числа CSV с запятой
+	DecimalComma bool
+	// BOM добавляет UTF-8 BOM в начало CSV, чтобы Excel распознал кодировку
+	BOM bool
+}
+
+// header возвращает заголовок колонки на выбранном языке
+func (o Options) header(c Column) string {
+	if o.Lang == "en" {
+		return c.HeaderEN
+	}
+	return c.HeaderRU
+}
+
+// columns возвращает выбранные колонки или все по умолчанию
+func (o Options) columns() []Column {
+	if len(o.Columns) == 0 {
+		return Columns
+	}
+	return o.Columns
+}
+
+// WriteCSV записывает данные в CSV
+func WriteCSV(w io.Writer, data []models.ETFData, opts Options) error {
+	bw := bufio.NewWriter(w)
+	if opts.BOM {
+		if _, err := bw.WriteString("\ufeff"); err != nil {
+			return err
+		}
+	}
+
+	cw := csv.NewWriter(bw)
+	if opts.Delimiter != 0 {
+		cw.Comma = opts.Delimiter
+	}
+
+	columns := opts.columns()
+	record := make([]string, len(columns))
+	for i, c := range columns {
+		record[i] = opts.header(c)
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+
+	for _, etf := range data {
+		for i, c := range columns {
+			record[i] = c.csvValue(etf, opts.DecimalComma)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// csvValue форматирует значение ячейки для CSV
+func (c Column) csvValue(etf models.ETFData, decimalComma bool) string {
+	if c.text != nil {
+		return c.text(etf)
+	}
+	val := c.number(etf)
+	if val == nil {
+		return ""
+	}
+	s := strconv.FormatFloat(*val, 'f', -1, 64)
+	if decimalComma {
+		s = strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
+
+// cellValue возвращает значение ячейки для XLSX (числа остаются числами)
+func (c Column) cellValue(etf models.ETFData) interface{} {
+	if c.text != nil {
+		return c.text(etf)
+	}
+	if val := c.number(etf); val != nil {
+		return *val
+	}
+	return nil
+}
+
+// WriteXLSX записывает данные в XLSX с одним листом
+func WriteXLSX(w io.Writer, data []models.ETFData, opts Options) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	columns := opts.columns()
+	header := make([]interface{}, len(columns))
+	for i, c := range columns {
+		header[i] = opts.header(c)
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	for r, etf := range data {
+		row := make([]interface{}, len(columns))
+		for i, c := range columns {
+			row[i] = c.cellValue(etf)
+		}
+		cell, err := excelize.CoordinatesToCellName(1, r+2)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+
+	_, err = f.WriteTo(w)
+	return err
+}
+
+// Форматы выгрузки
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// NewOptions собирает параметры выгрузки из строковых значений (параметры запроса, флаги CLI).
+// sep принимает "comma" или "semicolon"; точка с запятой включает десятичную запятую.
+func NewOptions(columns string, lang, sep string, bom bool) (Options, error) {
+	var keys []string
+	if columns != "" {
+		keys = strings.Split(columns, ",")
+	}
+
+	selected, err := SelectColumns(keys)
+	if err != nil {
+		return Options{}, err
+	}
+
+	opts := Options{Columns: selected, Lang: lang, Delimiter: ',', BOM: bom}
+	switch sep {
+	case "", "comma":
+	case "semicolon":
+		opts.Delimiter = ';'
+		opts.DecimalComma = true
+	default:
+		return Options{}, fmt.Errorf("неизвестный разделитель: %s", sep)
+	}
+
+	if lang != "" && lang != "ru" && lang != "en" {
+		return Options{}, fmt.Errorf("неизвестный язык заголовков: %s", lang)
+	}
+
+	return opts, nil
+}
+
+// Write записывает данные в выбранном формате
+func Write(w io.Writer, format string, data []models.ETFData, opts Options) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, data, opts)
+	case FormatXLSX:
+		return WriteXLSX(w, data, opts)
+	default:
+		return fmt.Errorf("неизвестный формат: %s", format)
+	}
+}
+
+// ContentType возвращает MIME тип для формата
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
@@ -0,0 +1,172 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"etf-scraper/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func testData() []models.ETFData {
+	return []models.ETFData{
+		{
+			DateScraped: "2024-05-01 00:00:00", Ticker: "TMOS", FundName: "Тинькофф iMOEX; «Индекс»",
+			TERPercent: ptr(0.79), NAVMillionRub: ptr(12345.6), PriceChange2024: ptr(-3.5),
+			InfoFlags: []models.InfoFlag{models.InfoFlags[0]},
+		},
+		{DateScraped: "2024-05-01 00:00:00", Ticker: "GOLD", FundName: "Золото", NAVMillionRub: ptr(100)},
+	}
+}
+
+func mustOptions(t *testing.T, columns, lang, sep string, bom bool) Options {
+	t.Helper()
+	opts, err := NewOptions(columns, lang, sep, bom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return opts
+}
+
+func TestWriteCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "ru comma with BOM",
+			opts: mustOptions(t, "ticker,fundName,terPercent,navMillionRub", "ru", "comma", true),
+			want: "\ufeffТикер,Название фонда,\"TER, %\",\"СЧА, млн ₽\"\n" +
+				"TMOS,Тинькофф iMOEX; «Индекс»,0.79,12345.6\n" +
+				"GOLD,Золото,,100\n",
+		},
+		{
+			name: "en semicolon with decimal comma",
+			opts: mustOptions(t, "ticker,fundName,terPercent,priceChange2024", "en", "semicolon", false),
+			want: "Ticker;Fund name;TER, %;2024, %\n" +
+				"TMOS;\"Тинькофф iMOEX; «Индекс»\";0,79;-3,5\n" +
+				"GOLD;Золото;;\n",
+		},
+		{
+			name: "default language is russian",
+			opts: mustOptions(t, "ticker,dateScraped", "", "", false),
+			want: "Тикер,Дата сбора\nTMOS,2024-05-01 00:00:00\nGOLD,2024-05-01 00:00:00\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, testData(), tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteCSV() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCSVAllColumnsParses(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testData(), mustOptions(t, "", "en", "semicolon", true)); err != nil {
+		t.Fatal(err)
+	}
+
+	body, ok := strings.CutPrefix(buf.String(), "\ufeff")
+	if !ok {
+		t.Fatal("CSV does not start with a UTF-8 BOM")
+	}
+	r := csv.NewReader(strings.NewReader(body))
+	r.Comma = ';'
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || len(records[0]) != len(Columns) {
+		t.Fatalf("records = %d x %d, want 3 x %d", len(records), len(records[0]), len(Columns))
+	}
+	for i, c := range Columns {
+		if records[0][i] != c.HeaderEN {
+			t.Errorf("header %d = %q, want %q", i, records[0][i], c.HeaderEN)
+		}
+	}
+}
+
+func TestWriteXLSXReadBack(t *testing.T) {
+	var buf bytes.Buffer
+	opts := mustOptions(t, "ticker,fundName,terPercent,navMillionRub", "en", "semicolon", true)
+	if err := WriteXLSX(&buf, testData(), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Ticker", "Fund name", "TER, %", "NAV, RUB mln"},
+		{"TMOS", "Тинькофф iMOEX; «Индекс»", "0.79", "12345.6"},
+		{"GOLD", "Золото", "", "100"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %v, want %v", rows, want)
+	}
+	for i := range want {
+		// GetRows отбрасывает пустые ячейки в конце строки
+		for len(rows[i]) < len(want[i]) {
+			rows[i] = append(rows[i], "")
+		}
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i+1, rows[i], want[i])
+		}
+	}
+
+	// Числа хранятся числами, а не строками: десятичная запятая CSV на них не влияет.
+	// Потоковая запись не указывает тип числовой ячейки — по OOXML это число.
+	for _, cell := range []string{"C2", "D2", "D3"} {
+		typ, err := f.GetCellType(sheet, cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != excelize.CellTypeNumber && typ != excelize.CellTypeUnset {
+			t.Errorf("%s type = %v, want a number", cell, typ)
+		}
+	}
+	if typ, _ := f.GetCellType(sheet, "A2"); typ == excelize.CellTypeNumber || typ == excelize.CellTypeUnset {
+		t.Errorf("A2 (ticker) type = %v, want a string", typ)
+	}
+}
+
+func TestNewOptions(t *testing.T) {
+	opts := mustOptions(t, "Ticker, NAVMILLIONRUB", "en", "semicolon", true)
+	if len(opts.Columns) != 2 || opts.Columns[0].Key != "ticker" || opts.Columns[1].Key != "navMillionRub" {
+		t.Errorf("columns = %+v", opts.Columns)
+	}
+	if opts.Delimiter != ';' || !opts.DecimalComma || !opts.BOM {
+		t.Errorf("options = %+v", opts)
+	}
+
+	if opts := mustOptions(t, "", "", "", false); len(opts.Columns) != len(Columns) || opts.Delimiter != ',' || opts.DecimalComma {
+		t.Errorf("default options = %+v", opts)
+	}
+
+	for _, bad := range [][3]string{{"unknown", "ru", "comma"}, {"", "de", "comma"}, {"", "ru", "tab"}} {
+		if _, err := NewOptions(bad[0], bad[1], bad[2], true); err == nil {
+			t.Errorf("NewOptions(%q, %q, %q) expected an error", bad[0], bad[1], bad[2])
+		}
+	}
+}
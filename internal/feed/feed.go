@@ -0,0 +1,202 @@
+// Package feed формирует ленты Atom и RSS 2.0 из событий фондов
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"etf-scraper/internal/models"
+)
+
+// Meta описывает ленту
+type Meta struct {
+	Title string
+	// BaseURL — адрес сервера без завершающего слеша, например http://localhost:8080
+	BaseURL string
+	// SelfURL — полный адрес самой ленты
+	SelfURL string
+	// Filter — нормализованные фильтры ленты; входят в ее идентификатор, чтобы
+	// ленты с разными фильтрами не считались одной лентой
+	Filter url.Values
+}
+
+// feedID — пространство идентификаторов записей (tag URI, RFC 4151)
+const feedID = "tag:etf-scraper,2024:"
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Link       atomLink       `xml:"link"`
+	Summary    string         `xml:"summary"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// WriteAtom записывает ленту в формате Atom 1.0
+func WriteAtom(w io.Writer, meta Meta, events []models.Event) error {
+	feed := atomFeed{
+		Title:   meta.Title,
+		ID:      atomFeedID(meta.Filter),
+		Updated: updated(events).Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: meta.SelfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: meta.BaseURL + "/"},
+		},
+		Author: atomAuthor{Name: "ETF Scraper"},
+	}
+
+	for _, e := range events {
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:      e.Title,
+			ID:         entryID(e),
+			Updated:    eventTime(e).Format(time.RFC3339),
+			Link:       atomLink{Href: tickerURL(meta.BaseURL, e.Ticker)},
+			Summary:    summary(e),
+			Categories: []atomCategory{{Term: e.Kind}, {Term: e.Ticker}},
+		})
+	}
+
+	return writeXML(w, feed)
+}
+
+// WriteRSS записывает ленту в формате RSS 2.0
+func WriteRSS(w io.Writer, meta Meta, events []models.Event) error {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       meta.Title,
+			Link:        meta.BaseURL + "/",
+			Description: "События ETF фондов: новые фонды, исключения, изменения TER и СЧА",
+		},
+	}
+	if len(events) > 0 {
+		feed.Channel.LastBuildDate = updated(events).Format(time.RFC1123Z)
+	}
+
+	for _, e := range events {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        tickerURL(meta.BaseURL, e.Ticker),
+			Description: summary(e),
+			GUID:        rssGUID{Value: entryID(e)},
+			PubDate:     eventTime(e).Format(time.RFC1123Z),
+			Categories:  []string{e.Kind, e.Ticker},
+		})
+	}
+
+	return writeXML(w, feed)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// summary формирует описание записи
+func summary(e models.Event) string {
+	return fmt.Sprintf("%s — %s (УК: %s). Сеанс %s, предыдущий %s.",
+		e.Ticker, e.FundName, e.ManagementCo, e.DateScraped, e.PrevDateScraped)
+}
+
+// atomFeedID возвращает идентификатор ленты; фильтры кодируются в порядке ключей,
+// поэтому идентификатор не зависит от порядка параметров запроса
+func atomFeedID(filter url.Values) string {
+	if query := filter.Encode(); query != "" {
+		return feedID + "feed?" + query
+	}
+	return feedID + "feed"
+}
+
+func entryID(e models.Event) string {
+	return fmt.Sprintf("%sevent/%d", feedID, e.ID)
+}
+
+func tickerURL(baseURL, ticker string) string {
+	return baseURL + "/api/etfs/" + url.PathEscape(ticker)
+}
+
+// eventTime возвращает время сеанса, в котором найдено событие
+func eventTime(e models.Event) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", e.DateScraped, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// updated возвращает время самого свежего события (или текущее время для пустой ленты)
+func updated(events []models.Event) time.Time {
+	latest := time.Time{}
+	for _, e := range events {
+		if t := eventTime(e); t.After(latest) {
+			latest = t
+		}
+	}
+	if latest.IsZero() {
+		return time.Now()
+	}
+	return latest
+}
@@ -0,0 +1,137 @@
+package fxrates
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+// maxRateAge — насколько курс может быть старше даты пересчета (выходные, праздники)
+const maxRateAge = 10 * 24 * time.Hour
+
+// Converter пересчитывает суммы и доходности по курсам из БД
+type Converter struct {
+	rates map[string][]models.FXRate // по валюте, по возрастанию даты
+}
+
+// NewConverter загружает курсы валют из БД
+func NewConverter(repo *database.Repository) (*Converter, error) {
+	list, err := repo.GetFXRates("", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Converter{rates: make(map[string][]models.FXRate)}
+	for _, r := range list {
+		c.rates[r.Currency] = append(c.rates[r.Currency], r)
+	}
+	return c, nil
+}
+
+// Rate возвращает курс валюты в рублях за единицу на дату (последний известный курс
+// не старше maxRateAge) и дату курса; false, если курса нет
+func (c *Converter) Rate(currency, date string) (float64, string, bool) {
+	if currency == RUB {
+		return 1, date, true
+	}
+
+	list := c.rates[currency]
+	i := sort.Search(len(list), func(i int) bool { return list[i].Date > date })
+	if i == 0 {
+		return 0, "", false
+	}
+	r := list[i-1]
+
+	at, errAt := time.Parse("2006-01-02", date)
+	found, errFound := time.Parse("2006-01-02", r.Date)
+	if errAt != nil || errFound != nil || at.Sub(found) > maxRateAge {
+		return 0, "", false
+	}
+	return r.Rate, r.Date, true
+}
+
+// Cross возвращает число единиц валюты to за единицу валюты from на дату
+func (c *Converter) Cross(from, to, date string) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+	rateFrom, _, okFrom := c.Rate(from, date)
+	rateTo, _, okTo := c.Rate(to, date)
+	if !okFrom || !okTo {
+		return 0, false
+	}
+	return rateFrom / rateTo, true
+}
+
+// Convert выражает СЧА и доходности фонда в валюте target на дату сеанса.
+// Доходность в валюте фонда r пересчитывается как (1 + r) × X(конец) / X(начало) − 1,
+// где X — кросс-курс валюты фонда к target; за прошлые годы берутся курсы на конец
+// года, за текущий год и 6 месяцев — на дату сеанса.
+func (c *Converter) Convert(etf models.ETFResponse, target string) *models.ConvertedValues {
+	date := sessionDate(etf.DateScraped)
+	result := &models.ConvertedValues{Currency: target}
+
+	if rate, rateDate, ok := c.Rate(target, date); ok {
+		result.RateDate = rateDate
+		if etf.NAVMillionRub != nil {
+			result.NAVMillion = round(*etf.NAVMillionRub / rate)
+		}
+	}
+
+	fund := NormalizeCurrency(etf.Currency)
+	at, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return result
+	}
+
+	convert := func(r *float64, start, end time.Time) *float64 {
+		if r == nil {
+			return nil
+		}
+		x0, ok0 := c.Cross(fund, target, start.Format("2006-01-02"))
+		x1, ok1 := c.Cross(fund, target, end.Format("2006-01-02"))
+		if !ok0 || !ok1 {
+			return nil
+		}
+		return round(((1+*r/100)*x1/x0 - 1) * 100)
+	}
+
+	yearEnd := func(year int) time.Time {
+		end := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
+		if end.After(at) {
+			return at
+		}
+		return end
+	}
+	year := func(r *float64, y int) *float64 {
+		if y > at.Year() {
+			return nil
+		}
+		return convert(r, yearEnd(y-1), yearEnd(y))
+	}
+
+	result.PriceChange6M = convert(etf.PriceChange6M, at.AddDate(0, -6, 0), at)
+	result.PriceChange2024 = year(etf.PriceChange2024, 2024)
+	result.PriceChange2023 = year(etf.PriceChange2023, 2023)
+	result.PriceChange2022 = year(etf.PriceChange2022, 2022)
+	result.PriceChange2021 = year(etf.PriceChange2021, 2021)
+	result.PriceChange2020 = year(etf.PriceChange2020, 2020)
+	return result
+}
+
+// sessionDate возвращает дату сеанса YYYY-MM-DD из date_scraped
+func sessionDate(dateScraped string) string {
+	if len(dateScraped) >= 10 {
+		return dateScraped[:10]
+	}
+	return dateScraped
+}
+
+// round округляет до сотых
+func round(v float64) *float64 {
+	r := math.Round(v*100) / 100
+	return &r
+}
@@ -0,0 +1,154 @@
+package fxrates
+
+import (
+	"fmt"
+	"testing"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func newTestConverter(t *testing.T, rates []models.FXRate) *Converter {
+	t.Helper()
+	db, err := database.NewDatabase(t.TempDir() + "/fx.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := database.NewRepository(db)
+	if err := repo.SaveFXRates(rates); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewConverter(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// testRates: рубль слабеет к доллару с 80 до 100 за 2024 год, евро дорожает с 88 до 125
+var testRates = []models.FXRate{
+	{Date: "2023-12-29", Currency: "USD", Rate: 80},
+	{Date: "2024-04-30", Currency: "USD", Rate: 100},
+	{Date: "2023-12-29", Currency: "EUR", Rate: 88},
+	{Date: "2024-04-30", Currency: "EUR", Rate: 125},
+}
+
+func TestConverterRate(t *testing.T) {
+	c := newTestConverter(t, testRates)
+
+	tests := []struct {
+		currency string
+		date     string
+		rate     float64
+		rateDate string
+		ok       bool
+	}{
+		{RUB, "2024-05-01", 1, "2024-05-01", true},
+		{"USD", "2024-04-30", 100, "2024-04-30", true},
+		{"USD", "2024-05-01", 100, "2024-04-30", true},
+		// Курс на 10 дней старше даты еще годится, на 11 — уже нет
+		{"USD", "2024-05-10", 100, "2024-04-30", true},
+		{"USD", "2024-05-11", 0, "", false},
+		{"USD", "2023-12-28", 0, "", false},
+		{"CNY", "2024-05-01", 0, "", false},
+	}
+	for _, tt := range tests {
+		rate, rateDate, ok := c.Rate(tt.currency, tt.date)
+		if rate != tt.rate || rateDate != tt.rateDate || ok != tt.ok {
+			t.Errorf("Rate(%s, %s) = %v, %q, %v; want %v, %q, %v",
+				tt.currency, tt.date, rate, rateDate, ok, tt.rate, tt.rateDate, tt.ok)
+		}
+	}
+
+	// Кросс-курс: 100 ₽ / 125 ₽ = 0,8 евро за доллар
+	if x, ok := c.Cross("USD", "EUR", "2024-05-01"); !ok || x != 0.8 {
+		t.Errorf("Cross(USD, EUR) = %v, %v; want 0.8", x, ok)
+	}
+	if _, ok := c.Cross("USD", "EUR", "2024-05-20"); ok {
+		t.Error("Cross() on stale rates should fail")
+	}
+}
+
+func TestConverterConvert(t *testing.T) {
+	c := newTestConverter(t, testRates)
+
+	tests := []struct {
+		name   string
+		etf    models.ETFResponse
+		target string
+
+		rateDate   string
+		nav        *float64
+		change2024 *float64
+	}{
+		{
+			// СЧА: 900 млн ₽ / 100 = 9 млн $; доходность 2024: 1,1 × 80 / 100 − 1 = −12%.
+			// Курсов на 2022 год и полгода назад нет — доходности не пересчитываются.
+			name: "RUB fund to USD",
+			etf: models.ETFResponse{
+				DateScraped: "2024-05-01 00:00:00", Currency: "RUB", NAVMillionRub: ptr(900),
+				PriceChange2024: ptr(10), PriceChange2023: ptr(30), PriceChange6M: ptr(5),
+			},
+			target:   "USD",
+			rateDate: "2024-04-30", nav: ptr(9), change2024: ptr(-12),
+		},
+		{
+			// Кросс-курс доллар/евро: 80/88 на начало года, 100/125 = 0,8 на дату сеанса;
+			// 1,1 × 0,8 / (80/88) − 1 = −3,2%
+			name: "USD fund to EUR",
+			etf: models.ETFResponse{
+				DateScraped: "2024-05-01 00:00:00", Currency: "$", NAVMillionRub: ptr(1000), PriceChange2024: ptr(10),
+			},
+			target:   "EUR",
+			rateDate: "2024-04-30", nav: ptr(8), change2024: ptr(-3.2),
+		},
+		{
+			// Последний курс старше 10 дней: ни СЧА, ни доходности не пересчитываются
+			name: "stale rates",
+			etf: models.ETFResponse{
+				DateScraped: "2024-05-20 00:00:00", Currency: "RUB", NAVMillionRub: ptr(900), PriceChange2024: ptr(10),
+			},
+			target: "USD",
+		},
+		{
+			name: "same currency",
+			etf: models.ETFResponse{
+				DateScraped: "2024-05-01 00:00:00", Currency: "USD", NAVMillionRub: ptr(1000), PriceChange2024: ptr(10),
+			},
+			target:   "USD",
+			rateDate: "2024-04-30", nav: ptr(10), change2024: ptr(10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.Convert(tt.etf, tt.target)
+			if got.Currency != tt.target || got.RateDate != tt.rateDate {
+				t.Errorf("currency, rate date = %s, %q; want %s, %q", got.Currency, got.RateDate, tt.target, tt.rateDate)
+			}
+			for _, f := range []struct {
+				name      string
+				got, want *float64
+			}{
+				{"NAVMillion", got.NAVMillion, tt.nav},
+				{"PriceChange2024", got.PriceChange2024, tt.change2024},
+				{"PriceChange2023", got.PriceChange2023, nil},
+				{"PriceChange6M", got.PriceChange6M, nil},
+			} {
+				if (f.got == nil) != (f.want == nil) || (f.got != nil && *f.got != *f.want) {
+					t.Errorf("%s = %s, want %s", f.name, fmtPtr(f.got), fmtPtr(f.want))
+				}
+			}
+		})
+	}
+}
+
+func fmtPtr(v *float64) string {
+	if v == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*v)
+}
@@ -0,0 +1,265 @@
+// Package fxrates хранит официальные курсы валют к рублю и пересчитывает СЧА
+// и доходности фондов в другую валюту на дату сеанса скрейпинга
+package fxrates
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"etf-scraper/internal/csvread"
+	"etf-scraper/internal/dateparse"
+	"etf-scraper/internal/models"
+	"etf-scraper/internal/numparse"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// RUB — базовая валюта: курсы хранятся в рублях за единицу валюты
+const RUB = "RUB"
+
+// Supported перечисляет валюты, в которых API выражает СЧА и доходности
+var Supported = []string{RUB, "USD", "EUR", "CNY"}
+
+// fetchTimeout ограничивает загрузку курсов по HTTP
+const fetchTimeout = 30 * time.Second
+
+// currencyAliases сводит обозначения валют с сайта и из выгрузок к кодам ISO 4217
+var currencyAliases = map[string]string{
+	"₽": RUB, "РУБ": RUB, "RUR": RUB, "РУБЛЬ": RUB,
+	"$": "USD", "ДОЛЛАР": "USD",
+	"€": "EUR", "ЕВРО": "EUR",
+	"¥": "CNY", "ЮАНЬ": "CNY", "RMB": "CNY",
+}
+
+// cbrCodes сопоставляет внутренние коды валют Банка России с кодами ISO 4217
+// (нужно для выгрузки динамики курса, где указан только внутренний код)
+var cbrCodes = map[string]string{
+	"R01235": "USD",
+	"R01239": "EUR",
+	"R01375": "CNY",
+}
+
+// NormalizeCurrency приводит обозначение валюты к коду ISO 4217; пустое — рубли
+func NormalizeCurrency(code string) string {
+	c := strings.ToUpper(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(code), ".")))
+	if c == "" {
+		return RUB
+	}
+	if alias, ok := currencyAliases[c]; ok {
+		return alias
+	}
+	return c
+}
+
+// IsSupported проверяет, что в валюте можно выразить показатели
+func IsSupported(code string) bool {
+	for _, c := range Supported {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Load читает курсы из файла или URL; формат определяется по содержимому:
+// XML Банка России или CSV
+func Load(source string) ([]models.FXRate, error) {
+	var content []byte
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		content, err = fetch(source)
+	} else {
+		content, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(content)
+}
+
+// Parse разбирает курсы в формате XML Банка России или CSV
+func Parse(content []byte) ([]models.FXRate, error) {
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("<")) {
+		return ParseCBR(bytes.NewReader(content))
+	}
+	return ParseCSV(bytes.NewReader(content))
+}
+
+func fetch(url string) ([]byte, error) {
+	client := &http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ошибка загрузки %s: HTTP %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ParseCSV читает курсы из CSV с колонками «дата, валюта, курс[, номинал]»;
+// курс — рублей за номинал (по умолчанию 1). Строка заголовка необязательна.
+func ParseCSV(r io.Reader) ([]models.FXRate, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csvread.ReadAll(content)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения CSV: %w", err)
+	}
+
+	var rates []models.FXRate
+	for i, record := range records {
+		row := i + 1
+		if len(record) < 3 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		nominal := "1"
+		if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+			nominal = record[3]
+		}
+		rate, err := parseRate(record[0], record[1], record[2], nominal)
+		if err != nil {
+			if row == 1 {
+				continue // заголовок
+			}
+			return nil, fmt.Errorf("строка %d: %w", row, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return finish(rates)
+}
+
+// cbrValCurs — корневой элемент XML Банка России: курсы на дату (Valute)
+// или динамика курса одной валюты (Record)
+type cbrValCurs struct {
+	Date    string `xml:"Date,attr"`
+	ID      string `xml:"ID,attr"`
+	Valutes []struct {
+		CharCode string `xml:"CharCode"`
+		Nominal  string `xml:"Nominal"`
+		Value    string `xml:"Value"`
+	} `xml:"Valute"`
+	Records []struct {
+		Date    string `xml:"Date,attr"`
+		ID      string `xml:"Id,attr"`
+		Nominal string `xml:"Nominal"`
+		Value   string `xml:"Value"`
+	} `xml:"Record"`
+}
+
+// ParseCBR читает курсы из XML Банка России: ежедневных курсов (XML_daily)
+// или динамики курса USD, EUR или CNY (XML_dynamic)
+func ParseCBR(r io.Reader) ([]models.FXRate, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charsetReader
+
+	var doc cbrValCurs
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("ошибка разбора XML: %w", err)
+	}
+
+	var rates []models.FXRate
+	for _, v := range doc.Valutes {
+		rate, err := parseRate(doc.Date, v.CharCode, v.Value, v.Nominal)
+		if err != nil {
+			return nil, fmt.Errorf("валюта %s: %w", v.CharCode, err)
+		}
+		rates = append(rates, rate)
+	}
+	for _, rec := range doc.Records {
+		id := rec.ID
+		if id == "" {
+			id = doc.ID
+		}
+		currency, ok := cbrCodes[id]
+		if !ok {
+			return nil, fmt.Errorf("неизвестный код валюты Банка России %q", id)
+		}
+		rate, err := parseRate(rec.Date, currency, rec.Value, rec.Nominal)
+		if err != nil {
+			return nil, fmt.Errorf("запись за %s: %w", rec.Date, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return finish(rates)
+}
+
+// charsetReader поддерживает кодировку windows-1251, в которой отдает XML Банк России
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(label) {
+	case "windows-1251", "cp1251":
+		return charmap.Windows1251.NewDecoder().Reader(input), nil
+	case "utf-8", "utf8":
+		return input, nil
+	}
+	return nil, fmt.Errorf("неподдерживаемая кодировка %q", label)
+}
+
+func parseRate(dateText, currency, valueText, nominalText string) (models.FXRate, error) {
+	d, err := dateparse.Parse(dateText)
+	if err != nil {
+		return models.FXRate{}, fmt.Errorf("неверная дата %q: %w", dateText, err)
+	}
+	if d.Precision != dateparse.PrecisionDay {
+		return models.FXRate{}, fmt.Errorf("дата должна содержать день: %q", dateText)
+	}
+
+	code := NormalizeCurrency(currency)
+	if len(code) != 3 || code == RUB {
+		return models.FXRate{}, fmt.Errorf("неверная валюта %q", currency)
+	}
+
+	value, err := numparse.Parse(valueText)
+	if err != nil {
+		return models.FXRate{}, fmt.Errorf("неверный курс %q: %w", valueText, err)
+	}
+	nominal, err := numparse.Parse(nominalText)
+	if err != nil || nominal.Float() <= 0 {
+		return models.FXRate{}, fmt.Errorf("неверный номинал %q", nominalText)
+	}
+	if value.Float() <= 0 {
+		return models.FXRate{}, fmt.Errorf("курс должен быть положительным: %q", valueText)
+	}
+
+	return models.FXRate{Date: d.ISO(), Currency: code, Rate: value.Float() / nominal.Float()}, nil
+}
+
+// finish упорядочивает курсы по валюте и дате; при повторе остается последний
+func finish(rates []models.FXRate) ([]models.FXRate, error) {
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("нет курсов валют")
+	}
+
+	type key struct{ date, currency string }
+	unique := make(map[key]float64, len(rates))
+	for _, r := range rates {
+		unique[key{r.Date, r.Currency}] = r.Rate
+	}
+
+	result := make([]models.FXRate, 0, len(unique))
+	for k, rate := range unique {
+		result = append(result, models.FXRate{Date: k.date, Currency: k.currency, Rate: rate})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Currency != result[j].Currency {
+			return result[i].Currency < result[j].Currency
+		}
+		return result[i].Date < result[j].Date
+	})
+	return result, nil
+}
@@ -0,0 +1,133 @@
+package fxrates
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"etf-scraper/internal/models"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// cbrDaily — ежедневные курсы в формате XML_daily.asp Банка России
+const cbrDaily = `<?xml version="1.0" encoding="windows-1251"?>
+<ValCurs Date="02.03.2024" name="Foreign Currency Market">
+<Valute ID="R01235"><NumCode>840</NumCode><CharCode>USD</CharCode><Nominal>1</Nominal><Name>Доллар США</Name><Value>91,3336</Value></Valute>
+<Valute ID="R01375"><NumCode>156</NumCode><CharCode>CNY</CharCode><Nominal>10</Nominal><Name>Китайских юаней</Name><Value>126,5432</Value></Valute>
+<Valute ID="R01820"><NumCode>392</NumCode><CharCode>JPY</CharCode><Nominal>100</Nominal><Name>Японских иен</Name><Value>60,7870</Value></Valute>
+</ValCurs>`
+
+// cbrDynamic — динамика курса евро в формате XML_dynamic.asp
+const cbrDynamic = `<?xml version="1.0" encoding="windows-1251"?>
+<ValCurs ID="R01239" DateRange1="01.03.2024" DateRange2="04.03.2024" name="Foreign Currency Market Dynamic">
+<Record Date="01.03.2024" Id="R01239"><Nominal>1</Nominal><Value>98,8233</Value></Record>
+<Record Date="02.03.2024" Id="R01239"><Nominal>1</Nominal><Value>99,0999</Value></Record>
+</ValCurs>`
+
+// serveCBR отдает XML в windows-1251, как сайт Банка России
+func serveCBR(t *testing.T, body string) string {
+	t.Helper()
+	encoded, err := charmap.Windows1251.NewEncoder().String(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml; charset=windows-1251")
+		fmt.Fprint(w, encoded)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func checkRates(t *testing.T, got, want []models.FXRate) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("rates = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Date != want[i].Date || got[i].Currency != want[i].Currency || math.Abs(got[i].Rate-want[i].Rate) > 1e-9 {
+			t.Errorf("rate[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadCBRDaily(t *testing.T) {
+	rates, err := Load(serveCBR(t, cbrDaily))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Курс делится на номинал: 10 юаней = 126,5432 ₽, 100 иен = 60,7870 ₽
+	checkRates(t, rates, []models.FXRate{
+		{Date: "2024-03-02", Currency: "CNY", Rate: 12.65432},
+		{Date: "2024-03-02", Currency: "JPY", Rate: 0.60787},
+		{Date: "2024-03-02", Currency: "USD", Rate: 91.3336},
+	})
+}
+
+func TestLoadCBRDynamic(t *testing.T) {
+	rates, err := Load(serveCBR(t, cbrDynamic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRates(t, rates, []models.FXRate{
+		{Date: "2024-03-01", Currency: "EUR", Rate: 98.8233},
+		{Date: "2024-03-02", Currency: "EUR", Rate: 99.0999},
+	})
+}
+
+func TestLoadHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	if _, err := Load(srv.URL); err == nil {
+		t.Error("expected an error for HTTP 404")
+	}
+}
+
+func TestParseCBRErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown encoding": `<?xml version="1.0" encoding="koi8-r"?><ValCurs Date="02.03.2024"></ValCurs>`,
+		"empty":            `<ValCurs Date="02.03.2024"></ValCurs>`,
+		"zero nominal":     `<ValCurs Date="02.03.2024"><Valute><CharCode>USD</CharCode><Nominal>0</Nominal><Value>91,33</Value></Valute></ValCurs>`,
+		"month only":       `<ValCurs Date="03.2024"><Valute><CharCode>USD</CharCode><Nominal>1</Nominal><Value>91,33</Value></Valute></ValCurs>`,
+		"unknown code":     `<ValCurs ID="R99999"><Record Date="01.03.2024"><Nominal>1</Nominal><Value>1,0</Value></Record></ValCurs>`,
+	}
+	for name, body := range tests {
+		if _, err := Parse([]byte(body)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	content := "\ufeffДата;Валюта;Курс;Номинал\n" +
+		"01.03.2024;usd;90,5\n" +
+		"01.03.2024;CNY;125,4;10\n" +
+		"2024-03-01;$;90,75\n" // повтор USD за ту же дату: остается последний
+	rates, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRates(t, rates, []models.FXRate{
+		{Date: "2024-03-01", Currency: "CNY", Rate: 12.54},
+		{Date: "2024-03-01", Currency: "USD", Rate: 90.75},
+	})
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	for input, want := range map[string]string{
+		"":      RUB,
+		" руб.": RUB,
+		"RUR":   RUB,
+		"usd":   "USD",
+		"$":     "USD",
+		"€":     "EUR",
+		"юань":  "CNY",
+	} {
+		if got := NormalizeCurrency(input); got != want {
+			t.Errorf("NormalizeCurrency(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
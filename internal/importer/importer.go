@@ -0,0 +1,264 @@
+// Package importer загружает исторические снимки из CSV/XLSX таблиц
+// как синтетические запуски скрейпера
+package importer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"etf-scraper/internal/analytics"
+	"etf-scraper/internal/csvread"
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/dateparse"
+	"etf-scraper/internal/events"
+	"etf-scraper/internal/export"
+	"etf-scraper/internal/models"
+	"etf-scraper/internal/scraper"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Options задает параметры импорта
+type Options struct {
+	Path string
+	// Date — дата снимка; становится date_scraped синтетического запуска
+	Date string
+	// Mapping дополняет сопоставление заголовков: заголовок файла -> колонка БД
+	Mapping map[string]string
+	Markers scraper.MarkerMapping
+	// NAVThresholds — пороги СЧА для событий импортированного сеанса
+	NAVThresholds []float64
+	// DryRun только проверяет файл, ничего не сохраняя
+	DryRun bool
+}
+
+// Result описывает итог импорта
+type Result struct {
+	RunID       int64
+	DateScraped string
+	RowsTotal   int
+	RowsSaved   int
+	Ignored     []string
+	Errors      []models.ParseError
+}
+
+// jsonKeys сопоставляет ключи JSON (и колонок выгрузки) с колонками БД
+var jsonKeys = map[string]string{
+	"ticker":          "ticker",
+	"tradeStatus":     "trade_status",
+	"managementCo":    "management_company",
+	"assetClass":      "asset_class",
+	"terPercent":      "ter_percent",
+	"terDirection":    "ter_direction",
+	"fundName":        "fund_name",
+	"managementStyle": "management_style",
+	"targetIndex":     "target_index",
+	"currency":        "currency",
+	"startDate":       "start_date",
+	"infoIcon":        "info_icon",
+	"priceChange6M":   "price_change_6m",
+	"priceChange2024": "price_change_2024",
+	"priceChange2023": "price_change_2023",
+	"priceChange2022": "price_change_2022",
+	"priceChange2021": "price_change_2021",
+	"priceChange2020": "price_change_2020",
+	"navMillionRub":   "nav_million_rub",
+	"lastUpdateDate":  "last_update_date",
+}
+
+// headerAliases строит словарь известных заголовков: имена колонок БД,
+// ключи JSON и локализованные заголовки выгрузки
+func headerAliases() map[string]string {
+	aliases := make(map[string]string)
+	for _, column := range scraper.Columns() {
+		aliases[normalizeHeader(column)] = column
+	}
+	for key, column := range jsonKeys {
+		aliases[normalizeHeader(key)] = column
+	}
+	for _, c := range export.Columns {
+		if column, ok := jsonKeys[c.Key]; ok {
+			aliases[normalizeHeader(c.HeaderRU)] = column
+			aliases[normalizeHeader(c.HeaderEN)] = column
+		}
+	}
+	return aliases
+}
+
+// normalizeHeader приводит заголовок к виду для сравнения
+func normalizeHeader(h string) string {
+	h = strings.TrimPrefix(h, "\ufeff")
+	return strings.ToLower(strings.Join(strings.Fields(h), " "))
+}
+
+// Run выполняет импорт
+func Run(repo *database.Repository, opts Options) (*Result, error) {
+	date, err := dateparse.Parse(opts.Date)
+	if err != nil {
+		return nil, fmt.Errorf("неверная дата снимка: %w", err)
+	}
+	if date.Precision != dateparse.PrecisionDay {
+		return nil, fmt.Errorf("дата снимка должна содержать день: %s", opts.Date)
+	}
+	dateISO := date.ISO()
+	dateScraped := dateISO + " 00:00:00"
+
+	exists, err := repo.SessionExists(dateScraped)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("снимок за %s уже есть в БД", dateISO)
+	}
+
+	table, err := readTable(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	if len(table) < 2 {
+		return nil, fmt.Errorf("в файле нет строк с данными")
+	}
+
+	columns, ignored, err := mapHeader(table[0], opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &scraper.ParseReport{}
+	parser := &scraper.RowParser{
+		Markers:     opts.Markers,
+		Report:      report,
+		DateScraped: dateScraped,
+	}
+
+	startedAt := time.Now()
+	var data []models.ETFData
+	for i, record := range table[1:] {
+		row := i + 2 // номер строки в файле с учетом заголовка
+		if isBlank(record) {
+			continue
+		}
+
+		values := map[string]string{"last_update_date": dateISO}
+		for idx, column := range columns {
+			if column != "" && idx < len(record) {
+				values[column] = record[idx]
+			}
+		}
+
+		etf, err := parser.Parse(row, values)
+		if err != nil {
+			report.RejectRow(row, record, err)
+			continue
+		}
+		data = append(data, *etf)
+	}
+
+	result := &Result{
+		DateScraped: dateScraped,
+		RowsTotal:   len(table) - 1,
+		Ignored:     ignored,
+		Errors:      report.Errors,
+	}
+
+	if opts.DryRun {
+		result.RowsSaved = len(data)
+		return result, nil
+	}
+
+	run := models.ScrapeRun{
+		StartedAt:   startedAt.Format("2006-01-02 15:04:05"),
+		FinishedAt:  time.Now().Format("2006-01-02 15:04:05"),
+		DateScraped: dateScraped,
+		Status:      models.RunStatusImported,
+		RowsTotal:   result.RowsTotal,
+		ErrorCount:  len(report.Errors),
+		Message:     "import: " + filepath.Base(opts.Path),
+	}
+	result.RunID, result.RowsSaved, err = repo.SaveImport(data, run, report.Errors)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.RowsSaved > 0 {
+		events.GenerateLogged(repo, dateScraped, opts.NAVThresholds)
+		analytics.UpdateRiskLogged(repo, dateScraped)
+	}
+
+	return result, nil
+}
+
+// mapHeader сопоставляет колонки файла с колонками БД
+func mapHeader(header []string, custom map[string]string) ([]string, []string, error) {
+	aliases := headerAliases()
+	for from, to := range custom {
+		aliases[normalizeHeader(from)] = to
+	}
+
+	known := make(map[string]bool)
+	for _, column := range scraper.Columns() {
+		known[column] = true
+	}
+
+	columns := make([]string, len(header))
+	var ignored []string
+	hasTicker := false
+	for i, h := range header {
+		column, ok := aliases[normalizeHeader(h)]
+		if !ok {
+			ignored = append(ignored, h)
+			continue
+		}
+		if !known[column] {
+			return nil, nil, fmt.Errorf("заголовок %q сопоставлен с неизвестной колонкой %q", h, column)
+		}
+		columns[i] = column
+		hasTicker = hasTicker || column == "ticker"
+	}
+
+	if !hasTicker {
+		return nil, nil, fmt.Errorf("в файле нет колонки с тикером")
+	}
+	if len(ignored) > 0 {
+		log.Printf("Колонки без сопоставления (пропущены): %s", strings.Join(ignored, ", "))
+	}
+
+	return columns, ignored, nil
+}
+
+// readTable читает CSV или первый лист XLSX
+func readTable(path string) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx":
+		f, err := excelize.OpenFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка открытия XLSX: %w", err)
+		}
+		defer f.Close()
+		return f.GetRows(f.GetSheetName(0))
+
+	case ".csv", ".txt":
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return csvread.ReadAll(content)
+
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат файла: %s", path)
+	}
+}
+
+// isBlank проверяет, что все ячейки строки пустые
+func isBlank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,299 @@
+package importer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/export"
+	"etf-scraper/internal/models"
+	"etf-scraper/internal/scraper"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func newTestRepo(t *testing.T) *database.Repository {
+	t.Helper()
+	db, err := database.NewDatabase(t.TempDir() + "/import.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return database.NewRepository(db)
+}
+
+func writeFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMapHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  []string
+		custom  map[string]string
+		columns []string
+		ignored []string
+	}{
+		{
+			name:    "russian export headers",
+			header:  []string{"Тикер", "Название фонда", "СЧА, млн ₽", "TER, %", "Изменение за 6 мес, %", "Дата сбора"},
+			columns: []string{"ticker", "fund_name", "nav_million_rub", "ter_percent", "price_change_6m", ""},
+			ignored: []string{"Дата сбора"},
+		},
+		{
+			name:    "english export headers",
+			header:  []string{"Ticker", "Fund name", "NAV, RUB mln", "6M change, %", "Management company"},
+			columns: []string{"ticker", "fund_name", "nav_million_rub", "price_change_6m", "management_company"},
+		},
+		{
+			name:    "JSON keys and database columns",
+			header:  []string{"ticker", "navMillionRub", "priceChange2024", "fund_name", "lastUpdateDate"},
+			columns: []string{"ticker", "nav_million_rub", "price_change_2024", "fund_name", "last_update_date"},
+		},
+		{
+			name:    "case, spaces and BOM",
+			header:  []string{"\ufeff  TICKER ", "fund   NAME", "сча, МЛН ₽"},
+			columns: []string{"ticker", "fund_name", "nav_million_rub"},
+		},
+		{
+			name:    "custom mapping",
+			header:  []string{"Код", "Фонд", "Тикер", "Прочее"},
+			custom:  map[string]string{"код": "ticker", " Фонд ": "fund_name", "Тикер": "trade_status"},
+			columns: []string{"ticker", "fund_name", "trade_status", ""},
+			ignored: []string{"Прочее"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, ignored, err := mapHeader(tt.header, tt.custom)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(columns, tt.columns) {
+				t.Errorf("columns = %q, want %q", columns, tt.columns)
+			}
+			if !slices.Equal(ignored, tt.ignored) {
+				t.Errorf("ignored = %q, want %q", ignored, tt.ignored)
+			}
+		})
+	}
+}
+
+func TestMapHeaderErrors(t *testing.T) {
+	if _, _, err := mapHeader([]string{"Название фонда", "СЧА, млн ₽"}, nil); err == nil {
+		t.Error("expected an error without a ticker column")
+	}
+	if _, _, err := mapHeader([]string{"Тикер", "Код"}, map[string]string{"Код": "isin"}); err == nil {
+		t.Error("expected an error for a mapping to an unknown column")
+	}
+}
+
+// rejectsCSV — файл с отклоненной строкой и ячейками, которые не удалось разобрать
+const rejectsCSV = "ticker;fundName;terPercent;navMillionRub;startDate\n" +
+	"TMOS;Тинькофф iMOEX;0,79;1 000;01.02.2020\n" +
+	";Без тикера;1;2;\n" +
+	"SBMX;Сбер;abc;500;32.13.2020\n" +
+	" ; ; ; ; \n"
+
+func TestRunReportsRejectedRows(t *testing.T) {
+	repo := newTestRepo(t)
+	path := writeFile(t, "rejects.csv", []byte(rejectsCSV))
+
+	result, err := Run(repo, Options{Path: path, Date: "2024-05-01", Markers: scraper.DefaultMarkerMapping()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RowsTotal != 4 || result.RowsSaved != 2 || result.DateScraped != "2024-05-01 00:00:00" {
+		t.Errorf("result = %+v", result)
+	}
+
+	stored, err := repo.GetParseErrors(result.RunID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Номера строк — как в файле, с учетом заголовка
+	want := []models.ParseError{
+		{Row: 3, Kind: models.ParseErrorRow, RawText: " | Без тикера | 1 | 2 | ", Reason: "пустой тикер"},
+		{Row: 4, Kind: models.ParseErrorCell, Column: "ter_percent", RawText: "abc"},
+		{Row: 4, Kind: models.ParseErrorCell, Column: "start_date", RawText: "32.13.2020"},
+	}
+	if len(stored) != len(want) {
+		t.Fatalf("parse errors = %+v, want %d", stored, len(want))
+	}
+	for i, w := range want {
+		g := stored[i]
+		if g.Row != w.Row || g.Kind != w.Kind || g.Column != w.Column || g.RawText != w.RawText || g.Reason == "" ||
+			(w.Reason != "" && g.Reason != w.Reason) {
+			t.Errorf("parse error %d = %+v, want %+v", i, g, w)
+		}
+	}
+
+	run, err := repo.GetScrapeRun(result.RunID)
+	if err != nil || run == nil {
+		t.Fatalf("GetScrapeRun() = %v, %v", run, err)
+	}
+	if run.Status != models.RunStatusImported || run.RowsTotal != 4 || run.RowsSaved != 2 || run.ErrorCount != 3 ||
+		run.Message != "import: rejects.csv" {
+		t.Errorf("run = %+v", run)
+	}
+
+	// Повторный импорт того же дня отклоняется
+	if _, err := Run(repo, Options{Path: path, Date: "01.05.2024"}); err == nil {
+		t.Error("expected an error for an existing snapshot")
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	repo := newTestRepo(t)
+	path := writeFile(t, "rejects.csv", []byte(rejectsCSV))
+
+	result, err := Run(repo, Options{Path: path, Date: "2024-05-01", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RunID != 0 || result.RowsTotal != 4 || result.RowsSaved != 2 || len(result.Errors) != 3 {
+		t.Errorf("result = %+v", result)
+	}
+
+	if exists, err := repo.SessionExists(result.DateScraped); err != nil || exists {
+		t.Errorf("dry run saved the session: %v, %v", exists, err)
+	}
+	if runs, err := repo.GetScrapeRuns(10); err != nil || len(runs) != 0 {
+		t.Errorf("dry run saved runs: %+v, %v", runs, err)
+	}
+}
+
+func TestRunOptionsErrors(t *testing.T) {
+	repo := newTestRepo(t)
+	csvPath := writeFile(t, "ok.csv", []byte("ticker\nTMOS\n"))
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"month only date", Options{Path: csvPath, Date: "2024-05"}},
+		{"bad date", Options{Path: csvPath, Date: "garbage"}},
+		{"unsupported format", Options{Path: writeFile(t, "data.json", []byte("[]")), Date: "2024-05-01"}},
+		{"header only", Options{Path: writeFile(t, "empty.csv", []byte("ticker\n")), Date: "2024-05-01"}},
+		{"no ticker column", Options{Path: writeFile(t, "nav.csv", []byte("nav\n100\n")), Date: "2024-05-01"}},
+	}
+	for _, tt := range tests {
+		if _, err := Run(repo, tt.opts); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+// roundTripFunds — фонды с заполненными полями, которые выгрузка сохраняет
+func roundTripFunds() []models.ETFData {
+	return []models.ETFData{
+		{
+			DateScraped: "2024-04-01 12:30:00", Ticker: "TMOS", TradeStatus: "Торгуется",
+			ManagementCo: "Т-Капитал", AssetClass: "Акции", TERPercent: ptr(0.79),
+			FundName: "Тинькофф iMOEX; «Индекс»", ManagementStyle: "Пассивное", TargetIndex: "MOEX Russia Index",
+			Currency: "RUB", StartDate: "2020-08-05",
+			PriceChange6M: ptr(-3.5), PriceChange2024: ptr(1.25), PriceChange2023: ptr(52.1),
+			PriceChange2022: ptr(-41.07), PriceChange2021: ptr(12), PriceChange2020: ptr(8.9),
+			NAVMillionRub: ptr(12345.6), LastUpdateDate: "2024-03-29",
+		},
+		{
+			DateScraped: "2024-04-01 12:30:00", Ticker: "GOLD", FundName: "Золото, \"биржевой\"",
+			NAVMillionRub: ptr(1234567.89), LastUpdateDate: "2024-03-29",
+		},
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	formats := []struct {
+		name      string
+		lang, sep string
+	}{
+		{"russian excel", "ru", "semicolon"},
+		{"english comma", "en", "comma"},
+	}
+
+	for _, f := range formats {
+		t.Run(f.name, func(t *testing.T) {
+			opts, err := export.NewOptions("", f.lang, f.sep, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := export.WriteCSV(&buf, roundTripFunds(), opts); err != nil {
+				t.Fatal(err)
+			}
+
+			repo := newTestRepo(t)
+			result, err := Run(repo, Options{
+				Path: writeFile(t, "export.csv", buf.Bytes()), Date: "2024-04-01", Markers: scraper.DefaultMarkerMapping(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Errors) != 0 || result.RowsSaved != 2 {
+				t.Fatalf("result = %+v", result)
+			}
+			// Производные колонки выгрузки не импортируются
+			if len(result.Ignored) != 3 {
+				t.Errorf("ignored = %q, want date scraped, TER trend and info flags", result.Ignored)
+			}
+
+			imported, err := repo.GetETFsBySession(result.DateScraped)
+			if err != nil {
+				t.Fatal(err)
+			}
+			byTicker := make(map[string]models.ETFData)
+			for _, etf := range imported {
+				byTicker[etf.Ticker] = etf
+			}
+
+			for _, want := range roundTripFunds() {
+				got, ok := byTicker[want.Ticker]
+				if !ok {
+					t.Errorf("%s was not imported", want.Ticker)
+					continue
+				}
+				if diff := compareExported(got, want); diff != "" {
+					t.Errorf("%s: %s", want.Ticker, diff)
+				}
+			}
+		})
+	}
+}
+
+// compareExported сравнивает поля, которые есть в выгрузке; пустая строка — совпадают
+func compareExported(got, want models.ETFData) string {
+	var diffs []string
+	for _, c := range export.Columns {
+		switch c.Key {
+		case "dateScraped", "terTrend", "infoFlags":
+			continue
+		}
+		g, w := exportValue(c, got), exportValue(c, want)
+		if g != w {
+			diffs = append(diffs, c.Key+" = "+g+", want "+w)
+		}
+	}
+	return strings.Join(diffs, "; ")
+}
+
+// exportValue возвращает значение колонки так, как его записывает выгрузка
+func exportValue(c export.Column, etf models.ETFData) string {
+	var buf bytes.Buffer
+	opts := export.Options{Columns: []export.Column{c}, Delimiter: ','}
+	if err := export.WriteCSV(&buf, []models.ETFData{etf}, opts); err != nil {
+		return err.Error()
+	}
+	_, value, _ := strings.Cut(buf.String(), "\n")
+	return strings.TrimSpace(value)
+}
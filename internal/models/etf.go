@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 // ETFData представляет данные о ETF фонде
 type ETFData struct {
 	DateScraped     string
@@ -15,6 +17,8 @@ type ETFData struct {
 	Currency        string
 	StartDate       string
 	InfoIcon        string
+	TERTrend        TERTrend
+	InfoFlags       []InfoFlag
 	PriceChange6M   *float64
 	PriceChange2024 *float64
 	PriceChange2023 *float64
@@ -25,34 +29,115 @@ type ETFData struct {
 	LastUpdateDate  string
 }
 
+// TERTrend описывает направление изменения TER
+type TERTrend string
+
+const (
+	TERTrendUp        TERTrend = "up"
+	TERTrendDown      TERTrend = "down"
+	TERTrendUnchanged TERTrend = "unchanged"
+	TERTrendUnknown   TERTrend = "unknown"
+)
+
+// TERTrends перечисляет допустимые направления изменения TER
+var TERTrends = []TERTrend{TERTrendUp, TERTrendDown, TERTrendUnchanged, TERTrendUnknown}
+
+// InfoFlag описывает информационную отметку фонда
+type InfoFlag string
+
+const (
+	InfoFlagNewFund       InfoFlag = "new_fund"
+	InfoFlagClosed        InfoFlag = "closed"
+	InfoFlagQualifiedOnly InfoFlag = "qualified_only"
+	InfoFlagSuspended     InfoFlag = "suspended"
+	InfoFlagUnknown       InfoFlag = "unknown"
+)
+
+// InfoFlags перечисляет допустимые отметки фондов
+var InfoFlags = []InfoFlag{InfoFlagNewFund, InfoFlagClosed, InfoFlagQualifiedOnly, InfoFlagSuspended, InfoFlagUnknown}
+
+// JoinInfoFlags кодирует отметки для хранения в БД
+func JoinInfoFlags(flags []InfoFlag) string {
+	parts := make([]string, len(flags))
+	for i, f := range flags {
+		parts[i] = string(f)
+	}
+	return strings.Join(parts, ",")
+}
+
+// SplitInfoFlags декодирует отметки из БД
+func SplitInfoFlags(value string) []InfoFlag {
+	flags := []InfoFlag{}
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			flags = append(flags, InfoFlag(part))
+		}
+	}
+	return flags
+}
+
+// ReturnYears перечисляет календарные годы, за которые хранится изменение цены
+var ReturnYears = []int{2020, 2021, 2022, 2023, 2024}
+
+// PriceChangeYear возвращает изменение цены за календарный год (для текущего
+// года — с начала года); nil, если за этот год колонки нет или значение пустое
+func (e ETFData) PriceChangeYear(year int) *float64 {
+	switch year {
+	case 2024:
+		return e.PriceChange2024
+	case 2023:
+		return e.PriceChange2023
+	case 2022:
+		return e.PriceChange2022
+	case 2021:
+		return e.PriceChange2021
+	case 2020:
+		return e.PriceChange2020
+	}
+	return nil
+}
+
 // ETFResponse представляет ответ API для ETF
 type ETFResponse struct {
-	ID              int      `json:"id"`
-	DateScraped     string   `json:"dateScraped"`
-	Ticker          string   `json:"ticker"`
-	TradeStatus     string   `json:"tradeStatus"`
-	ManagementCo    string   `json:"managementCo"`
-	AssetClass      string   `json:"assetClass"`
-	TERPercent      *float64 `json:"terPercent"`
-	TERDirection    string   `json:"terDirection"`
-	FundName        string   `json:"fundName"`
-	ManagementStyle string   `json:"managementStyle"`
-	TargetIndex     string   `json:"targetIndex"`
-	Currency        string   `json:"currency"`
-	StartDate       string   `json:"startDate"`
-	InfoIcon        string   `json:"infoIcon"`
-	PriceChange6M   *float64 `json:"priceChange6M"`
-	PriceChange2024 *float64 `json:"priceChange2024"`
-	PriceChange2023 *float64 `json:"priceChange2023"`
-	PriceChange2022 *float64 `json:"priceChange2022"`
-	PriceChange2021 *float64 `json:"priceChange2021"`
-	PriceChange2020 *float64 `json:"priceChange2020"`
-	NAVMillionRub   *float64 `json:"navMillionRub"`
-	LastUpdateDate  string   `json:"lastUpdateDate"`
+	ID              int        `json:"id"`
+	DateScraped     string     `json:"dateScraped"`
+	Ticker          string     `json:"ticker"`
+	TradeStatus     string     `json:"tradeStatus"`
+	ManagementCo    string     `json:"managementCo"`
+	AssetClass      string     `json:"assetClass"`
+	TERPercent      *float64   `json:"terPercent"`
+	TERDirection    string     `json:"terDirection"`
+	FundName        string     `json:"fundName"`
+	ManagementStyle string     `json:"managementStyle"`
+	TargetIndex     string     `json:"targetIndex"`
+	Currency        string     `json:"currency"`
+	StartDate       string     `json:"startDate"`
+	InfoIcon        string     `json:"infoIcon"`
+	TERTrend        TERTrend   `json:"terTrend"`
+	InfoFlags       []InfoFlag `json:"infoFlags"`
+	PriceChange6M   *float64   `json:"priceChange6M"`
+	PriceChange2024 *float64   `json:"priceChange2024"`
+	PriceChange2023 *float64   `json:"priceChange2023"`
+	PriceChange2022 *float64   `json:"priceChange2022"`
+	PriceChange2021 *float64   `json:"priceChange2021"`
+	PriceChange2020 *float64   `json:"priceChange2020"`
+	NAVMillionRub   *float64   `json:"navMillionRub"`
+	LastUpdateDate  string     `json:"lastUpdateDate"`
+	// Converted — СЧА и доходности в валюте из параметра currency
+	Converted *ConvertedValues `json:"converted,omitempty"`
+}
+
+// SearchResult — фонд, найденный поиском: релевантность (больше — выше в выдаче)
+// и поля с совпадениями, выделенными тегами <mark> (остальной текст экранирован для HTML)
+type SearchResult struct {
+	ETFResponse
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
 }
 
 // StatsResponse представляет статистику по ETF
 type StatsResponse struct {
+	DateScraped    string  `json:"dateScraped"` // сеанс, по состоянию на который посчитана статистика
 	TotalRecords   int     `json:"totalRecords"`
 	UniqueTickers  int     `json:"uniqueTickers"`
 	ScrapeSessions int     `json:"scrapeSessions"`
@@ -71,3 +156,818 @@ type ScrapeResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 }
+
+// HTTPCacheEntry хранит валидаторы ответа для условных GET запросов
+type HTTPCacheEntry struct {
+	URL          string
+	ETag         string
+	LastModified string
+	UpdatedAt    string
+}
+
+// Статусы запуска скрейпера
+const (
+	RunStatusSuccess  = "success"
+	RunStatusFailed   = "failed"
+	RunStatusImported = "imported"
+)
+
+// Виды ошибок парсинга
+const (
+	ParseErrorCell = "cell"
+	ParseErrorRow  = "row"
+)
+
+// ScrapeRun представляет один запуск скрейпера
+type ScrapeRun struct {
+	ID          int64
+	StartedAt   string
+	FinishedAt  string
+	DateScraped string
+	Status      string
+	RowsTotal   int
+	RowsSaved   int
+	ErrorCount  int
+	Message     string
+}
+
+// ParseError описывает ячейку или строку, которую не удалось разобрать
+type ParseError struct {
+	RunID   int64
+	Row     int
+	Kind    string
+	Column  string
+	RawText string
+	Reason  string
+}
+
+// ScrapeRunResponse представляет запуск скрейпера в ответе API
+type ScrapeRunResponse struct {
+	ID          int64  `json:"id"`
+	StartedAt   string `json:"startedAt"`
+	FinishedAt  string `json:"finishedAt"`
+	DateScraped string `json:"dateScraped"`
+	Status      string `json:"status"`
+	RowsTotal   int    `json:"rowsTotal"`
+	RowsSaved   int    `json:"rowsSaved"`
+	ErrorCount  int    `json:"errorCount"`
+	Message     string `json:"message"`
+}
+
+// NewScrapeRunResponse преобразует запуск в ответ API
+func NewScrapeRunResponse(run ScrapeRun) ScrapeRunResponse {
+	return ScrapeRunResponse{
+		ID:          run.ID,
+		StartedAt:   run.StartedAt,
+		FinishedAt:  run.FinishedAt,
+		DateScraped: run.DateScraped,
+		Status:      run.Status,
+		RowsTotal:   run.RowsTotal,
+		RowsSaved:   run.RowsSaved,
+		ErrorCount:  run.ErrorCount,
+		Message:     run.Message,
+	}
+}
+
+// ParseErrorResponse представляет ошибку парсинга в ответе API
+type ParseErrorResponse struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	RawText string `json:"rawText"`
+	Reason  string `json:"reason"`
+}
+
+// ParseErrorReportResponse представляет отчет об ошибках парсинга за запуск
+type ParseErrorReportResponse struct {
+	Run          ScrapeRunResponse    `json:"run"`
+	CellErrors   []ParseErrorResponse `json:"cellErrors"`
+	RejectedRows []ParseErrorResponse `json:"rejectedRows"`
+}
+
+// DumpManifest описывает выгрузку истории etf_data
+type DumpManifest struct {
+	GeneratedAt     string            `json:"generatedAt"`
+	LastDateScraped string            `json:"lastDateScraped"`
+	TotalRows       int               `json:"totalRows"`
+	Schema          []DumpSchemaField `json:"schema"`
+	Files           []DumpFile        `json:"files"`
+}
+
+// DumpSchemaField описывает колонку выгрузки
+type DumpSchemaField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// DumpFile описывает один файл выгрузки
+type DumpFile struct {
+	Path        string `json:"path"`
+	Format      string `json:"format"`
+	Partition   string `json:"partition"`
+	DateScraped string `json:"dateScraped"`
+	Rows        int    `json:"rows"`
+}
+
+// SnapshotDiff описывает изменения между двумя сеансами скрейпинга
+type SnapshotDiff struct {
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	Added   []DiffFund   `json:"added"`
+	Removed []DiffFund   `json:"removed"`
+	Changed []DiffChange `json:"changed"`
+}
+
+// DiffFund описывает добавленный или исчезнувший фонд
+type DiffFund struct {
+	Ticker        string   `json:"ticker"`
+	FundName      string   `json:"fundName"`
+	ManagementCo  string   `json:"managementCo"`
+	AssetClass    string   `json:"assetClass"`
+	NAVMillionRub *float64 `json:"navMillionRub"`
+}
+
+// DiffChange перечисляет изменившиеся поля одного фонда
+type DiffChange struct {
+	Ticker   string        `json:"ticker"`
+	FundName string        `json:"fundName"`
+	Changes  []FieldChange `json:"changes"`
+}
+
+// FieldChange описывает изменение поля; для числовых полей заполняются разницы
+type FieldChange struct {
+	Field        string      `json:"field"`
+	Old          interface{} `json:"old"`
+	New          interface{} `json:"new"`
+	Delta        *float64    `json:"delta,omitempty"`
+	DeltaPercent *float64    `json:"deltaPercent,omitempty"`
+}
+
+// Виды событий фондов
+const (
+	EventListed       = "listed"
+	EventDelisted     = "delisted"
+	EventSuspended    = "suspended"
+	EventResumed      = "resumed"
+	EventTERChanged   = "ter_changed"
+	EventRenamed      = "renamed"
+	EventNAVThreshold = "nav_threshold"
+)
+
+// Event описывает событие фонда, найденное при сравнении соседних сеансов
+type Event struct {
+	ID              int64
+	DateScraped     string
+	PrevDateScraped string
+	Ticker          string
+	ManagementCo    string
+	FundName        string
+	Kind            string
+	Title           string
+	OldValue        string
+	NewValue        string
+	CreatedAt       string
+}
+
+// EventResponse представляет событие фонда в ответе API и в вебхуках
+type EventResponse struct {
+	ID              int64  `json:"id"`
+	DateScraped     string `json:"dateScraped"`
+	PrevDateScraped string `json:"prevDateScraped"`
+	Ticker          string `json:"ticker"`
+	ManagementCo    string `json:"managementCo"`
+	FundName        string `json:"fundName"`
+	Kind            string `json:"kind"`
+	Title           string `json:"title"`
+	OldValue        string `json:"oldValue"`
+	NewValue        string `json:"newValue"`
+}
+
+// NewEventResponse преобразует событие в ответ API
+func NewEventResponse(e Event) EventResponse {
+	return EventResponse{
+		ID:              e.ID,
+		DateScraped:     e.DateScraped,
+		PrevDateScraped: e.PrevDateScraped,
+		Ticker:          e.Ticker,
+		ManagementCo:    e.ManagementCo,
+		FundName:        e.FundName,
+		Kind:            e.Kind,
+		Title:           e.Title,
+		OldValue:        e.OldValue,
+		NewValue:        e.NewValue,
+	}
+}
+
+// Типы уведомлений вебхуков помимо видов событий фондов
+const (
+	WebhookEventScrapeFailed = "scrape_failed"
+	WebhookEventPing         = "ping"
+)
+
+// Webhook описывает подписку на уведомления
+type Webhook struct {
+	ID         int64
+	URL        string
+	EventTypes []string
+	Tickers    []string
+	Secret     string
+	Active     bool
+	CreatedAt  string
+}
+
+// WebhookDelivery описывает попытку доставки уведомления
+type WebhookDelivery struct {
+	ID         int64
+	WebhookID  int64
+	DeliveryID string
+	EventType  string
+	Payload    string
+	Attempts   int
+	StatusCode int
+	Success    bool
+	Error      string
+	DurationMs int64
+	CreatedAt  string
+}
+
+// WebhookRequest — тело запроса на создание подписки
+type WebhookRequest struct {
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+	Tickers []string `json:"tickers"`
+	Secret  string   `json:"secret"`
+}
+
+// WebhookResponse представляет подписку в ответе API; секрет показывается только при создании
+type WebhookResponse struct {
+	ID        int64    `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Tickers   []string `json:"tickers"`
+	Secret    string   `json:"secret,omitempty"`
+	Active    bool     `json:"active"`
+	CreatedAt string   `json:"createdAt"`
+}
+
+// WebhookDeliveryResponse представляет доставку в ответе API
+type WebhookDeliveryResponse struct {
+	ID         int64  `json:"id"`
+	DeliveryID string `json:"deliveryId"`
+	EventType  string `json:"eventType"`
+	Attempts   int    `json:"attempts"`
+	StatusCode int    `json:"statusCode"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+	CreatedAt  string `json:"createdAt"`
+	Payload    string `json:"payload"`
+}
+
+// Виды правил оповещений
+const (
+	AlertTERAbove      = "ter_above"
+	AlertNAVDropPct    = "nav_drop_pct"
+	AlertStatusChanged = "status_changed"
+)
+
+// Watchlist — именованный список тикеров с правилами оповещений
+type Watchlist struct {
+	ID        int64
+	Name      string
+	Tickers   []string
+	Rules     []AlertRule
+	CreatedAt string
+}
+
+// AlertRule описывает условие оповещения и способ доставки
+type AlertRule struct {
+	ID          int64
+	WatchlistID int64
+	Kind        string
+	Threshold   *float64
+	Notifier    string
+	Target      string
+	CreatedAt   string
+}
+
+// Alert — сработавшее оповещение
+type Alert struct {
+	ID            int64
+	RuleID        int64
+	WatchlistID   int64
+	WatchlistName string
+	Ticker        string
+	DateScraped   string
+	Kind          string
+	Message       string
+	Delivered     bool
+	Error         string
+	CreatedAt     string
+}
+
+// WatchlistRequest — тело запроса на создание списка или замену тикеров
+type WatchlistRequest struct {
+	Name    string   `json:"name"`
+	Tickers []string `json:"tickers"`
+}
+
+// AlertRuleRequest — тело запроса на создание правила
+type AlertRuleRequest struct {
+	Kind      string   `json:"kind"`
+	Threshold *float64 `json:"threshold"`
+	Notifier  string   `json:"notifier"`
+	Target    string   `json:"target"`
+}
+
+// WatchlistResponse представляет список наблюдения в ответе API
+type WatchlistResponse struct {
+	ID        int64               `json:"id"`
+	Name      string              `json:"name"`
+	Tickers   []string            `json:"tickers"`
+	Rules     []AlertRuleResponse `json:"rules"`
+	CreatedAt string              `json:"createdAt"`
+}
+
+// AlertRuleResponse представляет правило в ответе API
+type AlertRuleResponse struct {
+	ID        int64    `json:"id"`
+	Kind      string   `json:"kind"`
+	Threshold *float64 `json:"threshold"`
+	Notifier  string   `json:"notifier"`
+	Target    string   `json:"target,omitempty"`
+	CreatedAt string   `json:"createdAt"`
+}
+
+// AlertResponse представляет оповещение в ответе API и в уведомлениях
+type AlertResponse struct {
+	ID          int64  `json:"id"`
+	RuleID      int64  `json:"ruleId"`
+	Watchlist   string `json:"watchlist"`
+	Ticker      string `json:"ticker"`
+	DateScraped string `json:"dateScraped"`
+	Kind        string `json:"kind"`
+	Message     string `json:"message"`
+	Delivered   bool   `json:"delivered"`
+	Error       string `json:"error,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// NewAlertResponse преобразует оповещение в ответ API
+func NewAlertResponse(a Alert) AlertResponse {
+	return AlertResponse{
+		ID:          a.ID,
+		RuleID:      a.RuleID,
+		Watchlist:   a.WatchlistName,
+		Ticker:      a.Ticker,
+		DateScraped: a.DateScraped,
+		Kind:        a.Kind,
+		Message:     a.Message,
+		Delivered:   a.Delivered,
+		Error:       a.Error,
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+// Методы оценки рыночного изменения СЧА при расчете потоков
+const (
+	FlowMethodYTD   = "ytd"   // по доходности с начала года
+	FlowMethod6M    = "6m"    // по доходности за 6 месяцев, пересчитанной на период
+	FlowMethodNAV   = "nav"   // данных о цене нет: весь прирост СЧА считается потоком
+	FlowMethodMixed = "mixed" // в разных парах сеансов использованы разные методы
+)
+
+// FlowReport — оценка чистых притоков и оттоков средств за период
+type FlowReport struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Periods int    `json:"periods"`
+	// YTDUnavailableYears — годы сеансов периода, для которых на сайте нет колонки
+	// доходности с начала года: по ним метод ytd недоступен
+	YTDUnavailableYears []int       `json:"ytdUnavailableYears,omitempty"`
+	Total               GroupFlow   `json:"total"`
+	Funds               []FundFlow  `json:"funds"`
+	ManagementCompanies []GroupFlow `json:"managementCompanies"`
+	AssetClasses        []GroupFlow `json:"assetClasses"`
+}
+
+// FundFlow — оценка потоков одного фонда; суммы в млн ₽
+type FundFlow struct {
+	Ticker       string   `json:"ticker"`
+	FundName     string   `json:"fundName"`
+	ManagementCo string   `json:"managementCompany"`
+	AssetClass   string   `json:"assetClass"`
+	NAVStart     float64  `json:"navStart"`
+	NAVEnd       float64  `json:"navEnd"`
+	MarketEffect float64  `json:"marketEffect"`
+	NetFlow      float64  `json:"netFlow"`
+	FlowPercent  *float64 `json:"flowPercent"`
+	Method       string   `json:"method"`
+}
+
+// GroupFlow — сумма потоков по группе фондов (управляющая компания, класс активов)
+type GroupFlow struct {
+	Name         string   `json:"name"`
+	Funds        int      `json:"funds"`
+	NAVStart     float64  `json:"navStart"`
+	NAVEnd       float64  `json:"navEnd"`
+	MarketEffect float64  `json:"marketEffect"`
+	NetFlow      float64  `json:"netFlow"`
+	FlowPercent  *float64 `json:"flowPercent"`
+}
+
+// CompanySummary — агрегаты по управляющей компании за сеанс; суммы в млн ₽,
+// доля рынка — в процентах от суммарной СЧА сеанса
+type CompanySummary struct {
+	Rank        int      `json:"rank,omitempty"`
+	Name        string   `json:"name"`
+	Variants    []string `json:"variants"`
+	FundCount   int      `json:"fundCount"`
+	TotalNAV    float64  `json:"totalNAV"`
+	WeightedTER *float64 `json:"weightedTER"`
+	MarketShare float64  `json:"marketShare"`
+}
+
+// CompaniesResponse — список управляющих компаний за сеанс
+type CompaniesResponse struct {
+	DateScraped string           `json:"dateScraped"`
+	RankedBy    string           `json:"rankedBy,omitempty"`
+	Companies   []CompanySummary `json:"companies"`
+}
+
+// CompanyDetail — управляющая компания с фондами и динамикой по сеансам
+type CompanyDetail struct {
+	CompanySummary
+	DateScraped string         `json:"dateScraped"`
+	Funds       []CompanyFund  `json:"funds"`
+	History     []CompanyPoint `json:"history"`
+}
+
+// CompanyFund — фонд управляющей компании
+type CompanyFund struct {
+	Ticker        string   `json:"ticker"`
+	FundName      string   `json:"fundName"`
+	AssetClass    string   `json:"assetClass"`
+	TradeStatus   string   `json:"tradeStatus"`
+	TERPercent    *float64 `json:"terPercent"`
+	NAVMillionRub *float64 `json:"navMillionRub"`
+}
+
+// CompanyPoint — показатели управляющей компании в одном сеансе
+type CompanyPoint struct {
+	DateScraped string   `json:"dateScraped"`
+	FundCount   int      `json:"fundCount"`
+	TotalNAV    float64  `json:"totalNAV"`
+	WeightedTER *float64 `json:"weightedTER"`
+	MarketShare float64  `json:"marketShare"`
+}
+
+// AssetClassSummary — показатели класса активов за сеанс: СЧА в млн ₽, доля СЧА
+// в процентах, средние доходности фондов класса в процентах
+type AssetClassSummary struct {
+	Name       string        `json:"name"`
+	FundCount  int           `json:"fundCount"`
+	TotalNAV   float64       `json:"totalNAV"`
+	NAVShare   float64       `json:"navShare"`
+	AvgTER     *float64      `json:"avgTER"`
+	MedianTER  *float64      `json:"medianTER"`
+	AvgReturns ReturnsByYear `json:"avgReturns"`
+}
+
+// ReturnsByYear — доходности за 6 месяцев и по календарным годам
+type ReturnsByYear struct {
+	PriceChange6M   *float64 `json:"priceChange6M"`
+	PriceChange2024 *float64 `json:"priceChange2024"`
+	PriceChange2023 *float64 `json:"priceChange2023"`
+	PriceChange2022 *float64 `json:"priceChange2022"`
+	PriceChange2021 *float64 `json:"priceChange2021"`
+	PriceChange2020 *float64 `json:"priceChange2020"`
+}
+
+// AssetClassSnapshot — показатели всех классов активов в одном сеансе
+type AssetClassSnapshot struct {
+	DateScraped string              `json:"dateScraped"`
+	TotalNAV    float64             `json:"totalNAV"`
+	Classes     []AssetClassSummary `json:"classes"`
+}
+
+// AssetClassSummaryResponse — разбивка по классам активов за последний сеанс
+// и ее динамика по всем сеансам
+type AssetClassSummaryResponse struct {
+	AssetClassSnapshot
+	Series []AssetClassSnapshot `json:"series,omitempty"`
+}
+
+// FeeImpactResponse — расчет влияния комиссий для набора фондов
+type FeeImpactResponse struct {
+	Amount             float64         `json:"amount"`
+	Years              int             `json:"years"`
+	GrossReturn        float64         `json:"grossReturn"`
+	GrossTerminalValue float64         `json:"grossTerminalValue"`
+	Funds              []FeeImpactFund `json:"funds"`
+	Missing            []MissingTicker `json:"missing"`
+}
+
+// FeeImpactFund — итог расчета для фонда по текущему TER
+type FeeImpactFund struct {
+	Rank           int     `json:"rank"`
+	Ticker         string  `json:"ticker"`
+	FundName       string  `json:"fundName"`
+	TERPercent     float64 `json:"terPercent"`
+	TerminalValue  float64 `json:"terminalValue"`
+	FeesPaid       float64 `json:"feesPaid"`
+	FeeDrag        float64 `json:"feeDrag"`
+	FeeDragPercent float64 `json:"feeDragPercent"`
+	BehindBest     float64 `json:"behindBest"`
+}
+
+// MissingTicker — тикер из запроса, который не удалось обработать, с причиной
+type MissingTicker struct {
+	Ticker string `json:"ticker"`
+	Reason string `json:"reason"`
+}
+
+// CompareResponse — сравнение фондов бок о бок; разницы считаются относительно
+// первого найденного тикера (Base)
+type CompareResponse struct {
+	Base        string              `json:"base"`
+	Funds       []ETFResponse       `json:"funds"`
+	Differences []CompareDifference `json:"differences"`
+	History     CompareHistory      `json:"history"`
+	Missing     []MissingTicker     `json:"missing"`
+}
+
+// CompareDifference — разница показателей фонда и базового фонда (фонд минус база);
+// NAVPercent — разница СЧА в процентах от СЧА базового фонда
+type CompareDifference struct {
+	Ticker        string        `json:"ticker"`
+	TERPercent    *float64      `json:"terPercent"`
+	NAVMillionRub *float64      `json:"navMillionRub"`
+	NAVPercent    *float64      `json:"navPercent"`
+	Returns       ReturnsByYear `json:"returns"`
+}
+
+// CompareHistory — история TER и СЧА на сеансах, общих для всех фондов;
+// значения по тикеру выровнены с Dates
+type CompareHistory struct {
+	Dates []string              `json:"dates"`
+	TER   map[string][]*float64 `json:"ter"`
+	NAV   map[string][]*float64 `json:"nav"`
+}
+
+// IndexAlias сопоставляет написание целевого индекса каноническому названию;
+// AliasKey — нормализованное написание, по которому ищется синоним
+type IndexAlias struct {
+	AliasKey  string
+	Alias     string
+	Canonical string
+	CreatedAt string
+}
+
+// IndexAliasRequest — тело запроса на добавление синонима индекса
+type IndexAliasRequest struct {
+	Alias     string `json:"alias"`
+	Canonical string `json:"canonical"`
+}
+
+// IndexAliasResponse представляет синоним индекса в ответе API
+type IndexAliasResponse struct {
+	Alias     string `json:"alias"`
+	Key       string `json:"key"`
+	Canonical string `json:"canonical"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// AlternativesResponse — фонды с той же экспозицией, что и выбранный фонд.
+// MatchedBy: "index" — тот же (нормализованный) целевой индекс, класс активов и валюта;
+// "asset_class" — у фонда нет индекса, подобраны фонды того же класса активов и валюты.
+type AlternativesResponse struct {
+	Ticker       string        `json:"ticker"`
+	TargetIndex  string        `json:"targetIndex"`
+	IndexKey     string        `json:"indexKey"`
+	AssetClass   string        `json:"assetClass"`
+	Currency     string        `json:"currency"`
+	MatchedBy    string        `json:"matchedBy"`
+	DateScraped  string        `json:"dateScraped"`
+	Alternatives []Alternative `json:"alternatives"`
+}
+
+// Alternative — фонд-альтернатива; список включает и сам фонд (IsBase)
+type Alternative struct {
+	Rank          int      `json:"rank"`
+	Ticker        string   `json:"ticker"`
+	FundName      string   `json:"fundName"`
+	ManagementCo  string   `json:"managementCo"`
+	TargetIndex   string   `json:"targetIndex"`
+	TradeStatus   string   `json:"tradeStatus"`
+	Trading       bool     `json:"trading"`
+	TERPercent    *float64 `json:"terPercent"`
+	NAVMillionRub *float64 `json:"navMillionRub"`
+	TERDifference *float64 `json:"terDifference"`
+	IsBase        bool     `json:"isBase"`
+}
+
+// BacktestRequest — тело запроса на расчет модельного портфеля
+type BacktestRequest struct {
+	Weights   map[string]float64 `json:"weights"`
+	Rebalance string             `json:"rebalance"`
+	Missing   string             `json:"missing"`
+}
+
+// BacktestResponse — результат расчета модельного портфеля; доходности в процентах
+type BacktestResponse struct {
+	Rebalance        string             `json:"rebalance"`
+	Missing          string             `json:"missing"`
+	Weights          map[string]float64 `json:"weights"`
+	Years            []BacktestYear     `json:"years"`
+	YearsCalculated  int                `json:"yearsCalculated"`
+	CumulativeReturn float64            `json:"cumulativeReturn"`
+	AnnualizedReturn *float64           `json:"annualizedReturn"`
+	Volatility       *float64           `json:"volatility"`
+	MaxDrawdown      float64            `json:"maxDrawdown"`
+}
+
+// BacktestYear — результат портфеля за календарный год. Partial — доходность
+// текущего года на дату данных (с начала года), а не за полный год.
+type BacktestYear struct {
+	Year           int      `json:"year"`
+	Return         *float64 `json:"return"`
+	Value          float64  `json:"value"`
+	Drawdown       float64  `json:"drawdown"`
+	MissingTickers []string `json:"missingTickers"`
+	Skipped        bool     `json:"skipped"`
+	Partial        bool     `json:"partial"`
+}
+
+// RiskMetrics — показатели риска фонда в сеансе скрейпинга, рассчитанные
+// по годовым доходностям за завершенные календарные годы (кэш risk_metrics).
+// Observations — число лет с известной доходностью.
+type RiskMetrics struct {
+	DateScraped      string
+	Ticker           string
+	Returns          map[int]float64
+	Observations     int
+	AnnualizedReturn *float64
+	Volatility       *float64
+	MaxDrawdown      *float64
+}
+
+// RiskResponse — показатели риска фондов за сеанс; значения в процентах
+type RiskResponse struct {
+	DateScraped  string          `json:"dateScraped"`
+	RiskFreeRate float64         `json:"riskFreeRate"`
+	Funds        []FundRisk      `json:"funds"`
+	Missing      []MissingTicker `json:"missing"`
+}
+
+// FundRisk — показатели риска фонда. Sharpe — (среднегодовая доходность −
+// безрисковая ставка) / волатильность.
+type FundRisk struct {
+	Ticker           string   `json:"ticker"`
+	FundName         string   `json:"fundName"`
+	AssetClass       string   `json:"assetClass"`
+	Years            []int    `json:"years"`
+	Observations     int      `json:"observations"`
+	AnnualizedReturn *float64 `json:"annualizedReturn"`
+	Volatility       *float64 `json:"volatility"`
+	MaxDrawdown      *float64 `json:"maxDrawdown"`
+	Sharpe           *float64 `json:"sharpe"`
+}
+
+// CorrelationResponse — попарные корреляции годовых доходностей фондов.
+// Matrix[i][j] — корреляция Tickers[i] и Tickers[j] (nil, если общих лет
+// меньше трех или доходность не менялась), Observations[i][j] — число общих лет.
+type CorrelationResponse struct {
+	DateScraped  string          `json:"dateScraped"`
+	Tickers      []string        `json:"tickers"`
+	Matrix       [][]*float64    `json:"matrix"`
+	Observations [][]int         `json:"observations"`
+	Missing      []MissingTicker `json:"missing"`
+}
+
+// Benchmark — индекс-бенчмарк, с которым сравниваются фонды
+type Benchmark struct {
+	Code      string
+	Name      string
+	CreatedAt string
+}
+
+// BenchmarkLevel — значение индекса на дату (YYYY-MM-DD)
+type BenchmarkLevel struct {
+	Date  string
+	Level float64
+}
+
+// FundBenchmark — привязка фонда к бенчмарку, заданная администратором
+type FundBenchmark struct {
+	Ticker    string
+	Code      string
+	CreatedAt string
+}
+
+// BenchmarkRequest — тело запроса на создание или переименование бенчмарка
+type BenchmarkRequest struct {
+	Name string `json:"name"`
+}
+
+// FundBenchmarkRequest — тело запроса на привязку фонда к бенчмарку
+type FundBenchmarkRequest struct {
+	Benchmark string `json:"benchmark"`
+}
+
+// BenchmarkResponse — бенчмарк с охватом загруженных значений и привязанными фондами
+type BenchmarkResponse struct {
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	Levels    int      `json:"levels"`
+	FirstDate string   `json:"firstDate"`
+	LastDate  string   `json:"lastDate"`
+	Funds     []string `json:"funds"`
+	CreatedAt string   `json:"createdAt"`
+}
+
+// TrackingYear — доходности фонда и индекса за год и их разница, %
+type TrackingYear struct {
+	Year               int      `json:"year"`
+	FundReturn         *float64 `json:"fundReturn"`
+	IndexReturn        *float64 `json:"indexReturn"`
+	TrackingDifference *float64 `json:"trackingDifference"`
+}
+
+// TrackingPeriod — показатели за период по годам, где известны обе доходности.
+// FundReturn, IndexReturn и TrackingDifference — среднегодовые, AverageDifference —
+// среднее годовых разниц, TrackingError — их выборочное СКО.
+type TrackingPeriod struct {
+	From               int      `json:"from,omitempty"`
+	To                 int      `json:"to,omitempty"`
+	Years              int      `json:"years"`
+	FundReturn         *float64 `json:"fundReturn"`
+	IndexReturn        *float64 `json:"indexReturn"`
+	TrackingDifference *float64 `json:"trackingDifference"`
+	AverageDifference  *float64 `json:"averageDifference"`
+	TrackingError      *float64 `json:"trackingError"`
+}
+
+// TrackingResponse — сравнение фонда с его бенчмарком
+type TrackingResponse struct {
+	Ticker        string         `json:"ticker"`
+	FundName      string         `json:"fundName"`
+	TargetIndex   string         `json:"targetIndex"`
+	Benchmark     string         `json:"benchmark"`
+	BenchmarkName string         `json:"benchmarkName"`
+	Link          string         `json:"link"`
+	Years         []TrackingYear `json:"years"`
+	Period        TrackingPeriod `json:"period"`
+}
+
+// LeagueResponse — таблица фондов, следующих за бенчмарком
+type LeagueResponse struct {
+	Benchmark   string        `json:"benchmark"`
+	Name        string        `json:"name"`
+	DateScraped string        `json:"dateScraped"`
+	From        int           `json:"from,omitempty"`
+	To          int           `json:"to,omitempty"`
+	Funds       []LeagueEntry `json:"funds"`
+}
+
+// LeagueEntry — место фонда в таблице бенчмарка
+type LeagueEntry struct {
+	Rank          int      `json:"rank"`
+	Ticker        string   `json:"ticker"`
+	FundName      string   `json:"fundName"`
+	ManagementCo  string   `json:"managementCo"`
+	Link          string   `json:"link"`
+	TERPercent    *float64 `json:"terPercent"`
+	NAVMillionRub *float64 `json:"navMillionRub"`
+	TrackingPeriod
+}
+
+// FXRate — официальный курс валюты: рублей за единицу на дату (YYYY-MM-DD)
+type FXRate struct {
+	Date     string  `json:"date"`
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+}
+
+// ConvertedValues — СЧА (млн единиц валюты) и доходности фонда (%), выраженные
+// в валюте Currency; RateDate — дата курса, по которому пересчитана СЧА.
+// Значение nil — курса на нужную дату нет.
+type ConvertedValues struct {
+	Currency   string   `json:"currency"`
+	RateDate   string   `json:"rateDate"`
+	NAVMillion *float64 `json:"navMillion"`
+	ReturnsByYear
+}
+
+// FXImportResponse — итог загрузки курсов по валютам
+type FXImportResponse struct {
+	Imported   int                       `json:"imported"`
+	Currencies map[string]FXRateCoverage `json:"currencies"`
+}
+
+// FXRateCoverage — охват загруженных курсов валюты
+type FXRateCoverage struct {
+	Count     int    `json:"count"`
+	FirstDate string `json:"firstDate"`
+	LastDate  string `json:"lastDate"`
+}
@@ -0,0 +1,321 @@
+// Package numparse разбирает числа в русском и английском форматах:
+// разделители разрядов и дробной части, множители ("тыс.", "млн", "млрд", "bn"),
+// проценты, знак минуса Unicode и маркеры отсутствия данных.
+package numparse
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Locale задает трактовку одиночной запятой или точки
+type Locale int
+
+const (
+	// LocaleRU: запятая — десятичный разделитель, точка и пробел — разряды
+	LocaleRU Locale = iota
+	// LocaleEN: точка — десятичный разделитель, запятая — разряды
+	LocaleEN
+)
+
+var (
+	// ErrEmpty возвращается для пустой строки
+	ErrEmpty = errors.New("пустое значение")
+	// ErrNoData возвращается для явных маркеров отсутствия данных ("—", "н/д", "n/a")
+	ErrNoData = errors.New("нет данных")
+)
+
+// SyntaxError описывает строку, которую не удалось разобрать как число
+type SyntaxError struct {
+	Input  string
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("не удалось распарсить число %q: %s", e.Input, e.Reason)
+}
+
+// Value представляет разобранное число
+type Value struct {
+	// Number — число в том виде, как оно записано (без учета множителя)
+	Number float64
+	// Multiplier — множитель из суффикса ("млрд" = 1e9); 0, если суффикса не было
+	Multiplier float64
+	// Percent — значение было записано со знаком процента
+	Percent bool
+}
+
+// Float возвращает абсолютное значение с учетом множителя
+func (v Value) Float() float64 {
+	if v.Multiplier == 0 {
+		return v.Number
+	}
+	return v.Number * v.Multiplier
+}
+
+// In возвращает значение в заданных единицах (например, 1e6 для миллионов).
+// Если множителя не было, число считается уже записанным в этих единицах.
+func (v Value) In(unit float64) float64 {
+	if v.Multiplier == 0 {
+		return v.Number
+	}
+	return v.Number * v.Multiplier / unit
+}
+
+// noDataMarkers перечисляет значения, означающие отсутствие данных
+var noDataMarkers = []string{"—", "–", "-", "−", "н/д", "нд", "n/a", "na", "нет данных", "no data", "?"}
+
+// noDataSymbols — символы, наличие которых в ячейке означает отсутствие числа
+var noDataSymbols = []string{"⸗", "ℹ"}
+
+// multipliers сопоставляет суффиксы с множителями; сравнение без учета регистра
+var multipliers = []struct {
+	suffix string
+	value  float64
+}{
+	{"трлн", 1e12},
+	{"trillion", 1e12},
+	{"tn", 1e12},
+	{"млрд", 1e9},
+	{"billion", 1e9},
+	{"bln", 1e9},
+	{"bn", 1e9},
+	{"b", 1e9},
+	{"млн", 1e6},
+	{"million", 1e6},
+	{"mln", 1e6},
+	{"mn", 1e6},
+	{"m", 1e6},
+	{"тыс", 1e3},
+	{"thousand", 1e3},
+	{"k", 1e3},
+}
+
+// currencySymbols удаляются из строки перед разбором
+var currencySymbols = []string{"₽", "руб.", "руб", "р.", "$", "€", "¥", "usd", "rub", "eur", "cny"}
+
+// Parse разбирает строку в русской локали
+func Parse(text string) (Value, error) {
+	return ParseLocale(text, LocaleRU)
+}
+
+// ParseLocale разбирает строку с учетом локали
+func ParseLocale(text string, locale Locale) (Value, error) {
+	original := text
+	s := normalizeSpaces(text)
+	s = strings.ReplaceAll(s, "*", "")
+	s = strings.TrimSpace(s)
+
+	if s == "" {
+		return Value{}, ErrEmpty
+	}
+	lower := strings.ToLower(s)
+	for _, marker := range noDataMarkers {
+		if lower == marker {
+			return Value{}, ErrNoData
+		}
+	}
+	for _, symbol := range noDataSymbols {
+		if strings.Contains(s, symbol) {
+			return Value{}, ErrNoData
+		}
+	}
+
+	var v Value
+	negative := false
+
+	// Бухгалтерская запись отрицательных чисел: (12,5)
+	if strings.HasPrefix(lower, "(") && strings.HasSuffix(lower, ")") {
+		negative = true
+		lower = strings.TrimSpace(lower[1 : len(lower)-1])
+	}
+
+	if strings.HasSuffix(lower, "%") {
+		v.Percent = true
+		lower = strings.TrimSpace(strings.TrimSuffix(lower, "%"))
+	}
+
+	for _, symbol := range currencySymbols {
+		lower = strings.TrimSpace(strings.TrimSuffix(lower, symbol))
+		lower = strings.TrimSpace(strings.TrimPrefix(lower, symbol))
+	}
+
+	lower, v.Multiplier = cutMultiplier(lower)
+	if v.Multiplier != 0 {
+		lower = strings.TrimSpace(strings.TrimSuffix(lower, "."))
+		for _, symbol := range currencySymbols {
+			lower = strings.TrimSpace(strings.TrimSuffix(lower, symbol))
+		}
+	}
+
+	if !v.Percent && strings.HasSuffix(lower, "%") {
+		v.Percent = true
+		lower = strings.TrimSpace(strings.TrimSuffix(lower, "%"))
+	}
+
+	lower, signNegative := cutSign(lower)
+	if signNegative {
+		negative = !negative
+	}
+
+	if lower == "" {
+		return Value{}, &SyntaxError{Input: original, Reason: "нет цифр"}
+	}
+
+	digits, err := normalizeSeparators(lower, locale)
+	if err != nil {
+		return Value{}, &SyntaxError{Input: original, Reason: err.Error()}
+	}
+
+	num, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return Value{}, &SyntaxError{Input: original, Reason: "недопустимые символы"}
+	}
+	if negative {
+		num = -num
+	}
+
+	v.Number = num
+	return v, nil
+}
+
+// normalizeSpaces заменяет все виды пробелов обычным пробелом
+func normalizeSpaces(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		// Селектор варианта эмодзи не несет смысла
+		if r == '\ufe0f' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// cutMultiplier отрезает суффикс-множитель
+func cutMultiplier(s string) (string, float64) {
+	trimmed := strings.TrimSuffix(s, ".")
+	for _, m := range multipliers {
+		if !strings.HasSuffix(trimmed, m.suffix) {
+			continue
+		}
+		rest := strings.TrimSuffix(trimmed, m.suffix)
+		// Однобуквенные латинские суффиксы допустимы только сразу после цифры или пробела
+		if rest == "" {
+			continue
+		}
+		last := []rune(rest)[len([]rune(rest))-1]
+		if !unicode.IsDigit(last) && last != ' ' && last != '.' {
+			continue
+		}
+		return strings.TrimSpace(rest), m.value
+	}
+	return s, 0
+}
+
+// cutSign отрезает ведущий знак, включая минус Unicode и тире
+func cutSign(s string) (string, bool) {
+	for _, minus := range []string{"-", "−", "–", "—"} {
+		if strings.HasPrefix(s, minus) {
+			return strings.TrimSpace(strings.TrimPrefix(s, minus)), true
+		}
+	}
+	if strings.HasPrefix(s, "+") {
+		return strings.TrimSpace(strings.TrimPrefix(s, "+")), false
+	}
+	return s, false
+}
+
+// normalizeSeparators приводит число к виду, понятному strconv.ParseFloat
+func normalizeSeparators(s string, locale Locale) (string, error) {
+	// Пробелы и апострофы всегда разделяют разряды
+	if strings.ContainsAny(s, " '’") {
+		groups := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == '\'' || r == '’' })
+		if err := checkGroups(groups); err != nil {
+			return "", err
+		}
+		s = strings.Join(groups, "")
+	}
+
+	for _, r := range s {
+		if !unicode.IsDigit(r) && r != ',' && r != '.' {
+			return "", fmt.Errorf("недопустимый символ %q", r)
+		}
+	}
+
+	commas := strings.Count(s, ",")
+	dots := strings.Count(s, ".")
+
+	switch {
+	case commas > 0 && dots > 0:
+		// Десятичный разделитель — тот, что встречается последним
+		lastComma := strings.LastIndex(s, ",")
+		lastDot := strings.LastIndex(s, ".")
+		thousands, decimal := ",", "."
+		if lastComma > lastDot {
+			thousands, decimal = ".", ","
+		}
+		if strings.Count(s, decimal) > 1 {
+			return "", fmt.Errorf("несколько десятичных разделителей")
+		}
+		intPart, fracPart, _ := strings.Cut(s, decimal)
+		if err := checkGroups(strings.Split(intPart, thousands)); err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(intPart, thousands, "") + "." + fracPart, nil
+
+	case commas > 1:
+		if err := checkGroups(strings.Split(s, ",")); err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(s, ",", ""), nil
+
+	case dots > 1:
+		if err := checkGroups(strings.Split(s, ".")); err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(s, ".", ""), nil
+
+	case commas == 1:
+		if locale == LocaleEN {
+			if err := checkGroups(strings.Split(s, ",")); err != nil {
+				return "", err
+			}
+			return strings.ReplaceAll(s, ",", ""), nil
+		}
+		return strings.Replace(s, ",", ".", 1), nil
+
+	case dots == 1:
+		if s == "." {
+			return "", fmt.Errorf("нет цифр")
+		}
+		return s, nil
+	}
+
+	return s, nil
+}
+
+// checkGroups проверяет, что группы разрядов после первой состоят из трех цифр
+func checkGroups(groups []string) error {
+	for i, g := range groups {
+		if g == "" {
+			return fmt.Errorf("пустая группа разрядов")
+		}
+		if i == 0 {
+			continue
+		}
+		// Последняя группа может содержать десятичную часть: "1 234,5"
+		head := g
+		if idx := strings.IndexAny(g, ",."); idx >= 0 && i == len(groups)-1 {
+			head = g[:idx]
+		}
+		if len(head) != 3 {
+			return fmt.Errorf("неверная группа разрядов %q", g)
+		}
+	}
+	return nil
+}
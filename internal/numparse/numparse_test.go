@@ -0,0 +1,139 @@
+package numparse
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		locale Locale
+		want   Value
+	}{
+		{"integer", "42", LocaleRU, Value{Number: 42}},
+		{"ru decimal comma", "12,5", LocaleRU, Value{Number: 12.5}},
+		{"ru thousands space", "1 234 567", LocaleRU, Value{Number: 1234567}},
+		{"ru thousands nbsp", "1 234,56", LocaleRU, Value{Number: 1234.56}},
+		{"ru thousands dots", "1.234.567", LocaleRU, Value{Number: 1234567}},
+		{"ru mixed separators", "1.234,5", LocaleRU, Value{Number: 1234.5}},
+		{"ru single dot is decimal", "0.75", LocaleRU, Value{Number: 0.75}},
+		{"en decimal dot", "12.5", LocaleEN, Value{Number: 12.5}},
+		{"en thousands comma", "1,234", LocaleEN, Value{Number: 1234}},
+		{"en mixed separators", "1,234,567.89", LocaleEN, Value{Number: 1234567.89}},
+		{"apostrophe thousands", "1'234'567", LocaleEN, Value{Number: 1234567}},
+		{"plus sign", "+3,1", LocaleRU, Value{Number: 3.1}},
+		{"hyphen minus", "-3,1", LocaleRU, Value{Number: -3.1}},
+		{"unicode minus", "−3,1", LocaleRU, Value{Number: -3.1}},
+		{"en dash minus", "–0,5", LocaleRU, Value{Number: -0.5}},
+		{"accounting negative", "(12,5)", LocaleRU, Value{Number: -12.5}},
+		{"percent", "12,5%", LocaleRU, Value{Number: 12.5, Percent: true}},
+		{"percent narrow nbsp", "−0,8\u202f%", LocaleRU, Value{Number: -0.8, Percent: true}},
+		{"thousand ru", "15 тыс.", LocaleRU, Value{Number: 15, Multiplier: 1e3}},
+		{"million ru", "1,5 млн", LocaleRU, Value{Number: 1.5, Multiplier: 1e6}},
+		{"billion ru", "2,3 млрд", LocaleRU, Value{Number: 2.3, Multiplier: 1e9}},
+		{"billion ru with currency", "2,3 млрд руб.", LocaleRU, Value{Number: 2.3, Multiplier: 1e9}},
+		{"trillion ru", "1 трлн", LocaleRU, Value{Number: 1, Multiplier: 1e12}},
+		{"billion en", "4.2bn", LocaleEN, Value{Number: 4.2, Multiplier: 1e9}},
+		{"billion en upper", "4.2 BN", LocaleEN, Value{Number: 4.2, Multiplier: 1e9}},
+		{"thousand en", "850k", LocaleEN, Value{Number: 850, Multiplier: 1e3}},
+		{"currency prefix", "$1,200", LocaleEN, Value{Number: 1200}},
+		{"currency suffix", "1 200 ₽", LocaleRU, Value{Number: 1200}},
+		{"footnote asterisk", "12,5*", LocaleRU, Value{Number: 12.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLocale(tt.input, tt.locale)
+			if err != nil {
+				t.Fatalf("ParseLocale(%q) error: %v", tt.input, err)
+			}
+			if math.Abs(got.Number-tt.want.Number) > 1e-9 ||
+				got.Multiplier != tt.want.Multiplier || got.Percent != tt.want.Percent {
+				t.Errorf("ParseLocale(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValueUnits(t *testing.T) {
+	v, err := Parse("2,5 млрд")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Float(); got != 2.5e9 {
+		t.Errorf("Float() = %v, want 2.5e9", got)
+	}
+	if got := v.In(1e6); got != 2500 {
+		t.Errorf("In(1e6) = %v, want 2500", got)
+	}
+
+	// Без множителя число считается записанным в запрошенных единицах
+	plain, err := Parse("1 234,5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := plain.In(1e6); got != 1234.5 {
+		t.Errorf("In(1e6) without multiplier = %v, want 1234.5", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{"empty", "", ErrEmpty},
+		{"spaces only", "   ", ErrEmpty},
+		{"em dash", "—", ErrNoData},
+		{"hyphen", "-", ErrNoData},
+		{"unicode minus alone", "−", ErrNoData},
+		{"ru marker", "н/д", ErrNoData},
+		{"en marker", "N/A", ErrNoData},
+		{"no data words", "нет данных", ErrNoData},
+		{"info symbol", "ℹ️", ErrNoData},
+		{"question mark", "?", ErrNoData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.input, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSyntaxErrors(t *testing.T) {
+	inputs := []string{
+		"abc",
+		"12x",
+		"1 23",      // неполная группа разрядов
+		"1,2,3",     // группы не по три цифры
+		"1.234.5,6", // неверная группа перед десятичной частью
+		"%",
+		"млрд",
+		".",
+		"1,2.3,4",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			_, err := Parse(input)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse(%q) error = %v, want *SyntaxError", input, err)
+			}
+			if syntaxErr.Input != input {
+				t.Errorf("SyntaxError.Input = %q, want %q", syntaxErr.Input, input)
+			}
+			if errors.Is(err, ErrEmpty) || errors.Is(err, ErrNoData) {
+				t.Errorf("Parse(%q) syntax error must not match ErrEmpty/ErrNoData", input)
+			}
+		})
+	}
+}
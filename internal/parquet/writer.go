@@ -0,0 +1,314 @@
+// Package parquet записывает плоские таблицы в формате Apache Parquet.
+//
+// Поддерживается минимальное подмножество формата, достаточное для выгрузок:
+// одна группа строк на файл, по одной странице данных на колонку,
+// кодирование PLAIN без сжатия, типы UTF8-строка, DOUBLE и INT64.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Type задает физический тип колонки
+type Type int
+
+const (
+	String Type = iota
+	Double
+	Int64
+)
+
+// Column описывает колонку файла
+type Column struct {
+	Name     string
+	Type     Type
+	Optional bool
+}
+
+// Значения перечислений из parquet.thrift
+const (
+	typeInt64     = 2
+	typeDouble    = 5
+	typeByteArray = 6
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	convertedUTF8 = 0
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+	pageTypeData      = 0
+)
+
+const magic = "PAR1"
+
+// Writer накапливает строки и записывает их в Parquet при Close
+type Writer struct {
+	w       io.Writer
+	columns []Column
+	values  [][]interface{}
+	rows    int
+}
+
+// NewWriter создает Writer с заданной схемой
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		w:       w,
+		columns: columns,
+		values:  make([][]interface{}, len(columns)),
+	}
+}
+
+// Write добавляет строку. Допустимые значения: string, float64, *float64, int64, int и nil
+// (nil только для Optional колонок).
+func (pw *Writer) Write(row []interface{}) error {
+	if len(row) != len(pw.columns) {
+		return fmt.Errorf("parquet: ожидалось %d значений, получено %d", len(pw.columns), len(row))
+	}
+
+	for i, v := range row {
+		v, err := normalizeValue(pw.columns[i], v)
+		if err != nil {
+			return err
+		}
+		pw.values[i] = append(pw.values[i], v)
+	}
+	pw.rows++
+	return nil
+}
+
+// normalizeValue проверяет тип значения и разыменовывает указатели
+func normalizeValue(c Column, v interface{}) (interface{}, error) {
+	if p, ok := v.(*float64); ok {
+		if p == nil {
+			v = nil
+		} else {
+			v = *p
+		}
+	}
+	if i, ok := v.(int); ok {
+		v = int64(i)
+	}
+
+	if v == nil {
+		if !c.Optional {
+			return nil, fmt.Errorf("parquet: пустое значение в обязательной колонке %s", c.Name)
+		}
+		return nil, nil
+	}
+
+	ok := false
+	switch c.Type {
+	case String:
+		_, ok = v.(string)
+	case Double:
+		_, ok = v.(float64)
+	case Int64:
+		_, ok = v.(int64)
+	}
+	if !ok {
+		return nil, fmt.Errorf("parquet: неверный тип %T для колонки %s", v, c.Name)
+	}
+	return v, nil
+}
+
+// Close записывает файл целиком
+func (pw *Writer) Close() error {
+	out := &countingWriter{w: pw.w}
+
+	if _, err := out.Write([]byte(magic)); err != nil {
+		return err
+	}
+
+	type chunkInfo struct {
+		offset int64
+		size   int64
+		values int64
+	}
+	chunks := make([]chunkInfo, len(pw.columns))
+
+	for i, c := range pw.columns {
+		data := encodePage(c, pw.values[i])
+
+		header := &thriftWriter{}
+		header.beginStruct()
+		header.i32Field(1, pageTypeData)
+		header.i32Field(2, int32(len(data)))
+		header.i32Field(3, int32(len(data)))
+		header.structField(5)
+		header.i32Field(1, int32(len(pw.values[i])))
+		header.i32Field(2, encodingPlain)
+		header.i32Field(3, encodingRLE)
+		header.i32Field(4, encodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		chunks[i].offset = out.n
+		if _, err := out.Write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+		chunks[i].size = out.n - chunks[i].offset
+		chunks[i].values = int64(len(pw.values[i]))
+	}
+
+	var totalSize int64
+	for _, ch := range chunks {
+		totalSize += ch.size
+	}
+
+	meta := &thriftWriter{}
+	meta.beginStruct()
+	meta.i32Field(1, 1)
+
+	// Схема: корневой элемент и по элементу на колонку
+	meta.listHeader(2, thriftStruct, len(pw.columns)+1)
+	meta.beginStruct()
+	meta.stringField(4, "schema")
+	meta.i32Field(5, int32(len(pw.columns)))
+	meta.endStruct()
+	for _, c := range pw.columns {
+		meta.beginStruct()
+		meta.i32Field(1, physicalType(c.Type))
+		repetition := int32(repetitionRequired)
+		if c.Optional {
+			repetition = repetitionOptional
+		}
+		meta.i32Field(3, repetition)
+		meta.stringField(4, c.Name)
+		if c.Type == String {
+			meta.i32Field(6, convertedUTF8)
+		}
+		meta.endStruct()
+	}
+
+	meta.i64Field(3, int64(pw.rows))
+
+	// Одна группа строк
+	meta.listHeader(4, thriftStruct, 1)
+	meta.beginStruct()
+	meta.listHeader(1, thriftStruct, len(pw.columns))
+	for i, c := range pw.columns {
+		meta.beginStruct()
+		meta.i64Field(2, chunks[i].offset)
+		meta.structField(3)
+		meta.i32Field(1, physicalType(c.Type))
+		meta.listI32(2, []int32{encodingPlain, encodingRLE})
+		meta.listString(3, []string{c.Name})
+		meta.i32Field(4, codecUncompressed)
+		meta.i64Field(5, chunks[i].values)
+		meta.i64Field(6, chunks[i].size)
+		meta.i64Field(7, chunks[i].size)
+		meta.i64Field(9, chunks[i].offset)
+		meta.endStruct()
+		meta.endStruct()
+	}
+	meta.i64Field(2, totalSize)
+	meta.i64Field(3, int64(pw.rows))
+	meta.endStruct()
+
+	meta.stringField(6, "etf-scraper")
+	meta.endStruct()
+
+	if _, err := out.Write(meta.buf.Bytes()); err != nil {
+		return err
+	}
+
+	var footer [4]byte
+	binary.LittleEndian.PutUint32(footer[:], uint32(meta.buf.Len()))
+	if _, err := out.Write(footer[:]); err != nil {
+		return err
+	}
+	_, err := out.Write([]byte(magic))
+	return err
+}
+
+// physicalType возвращает тип Parquet для колонки
+func physicalType(t Type) int32 {
+	switch t {
+	case Double:
+		return typeDouble
+	case Int64:
+		return typeInt64
+	default:
+		return typeByteArray
+	}
+}
+
+// encodePage кодирует страницу данных: уровни определения (для Optional) и значения PLAIN
+func encodePage(c Column, values []interface{}) []byte {
+	var page bytes.Buffer
+
+	if c.Optional {
+		levels := encodeDefinitionLevels(values)
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(levels)))
+		page.Write(length[:])
+		page.Write(levels)
+	}
+
+	var b [8]byte
+	for _, v := range values {
+		switch val := v.(type) {
+		case nil:
+			// Пустые значения представлены только уровнями определения
+		case string:
+			binary.LittleEndian.PutUint32(b[:4], uint32(len(val)))
+			page.Write(b[:4])
+			page.WriteString(val)
+		case float64:
+			binary.LittleEndian.PutUint64(b[:], math.Float64bits(val))
+			page.Write(b[:])
+		case int64:
+			binary.LittleEndian.PutUint64(b[:], uint64(val))
+			page.Write(b[:])
+		}
+	}
+
+	return page.Bytes()
+}
+
+// encodeDefinitionLevels кодирует уровни определения (0 — null, 1 — значение)
+// гибридным RLE с разрядностью 1, используя только RLE-серии
+func encodeDefinitionLevels(values []interface{}) []byte {
+	var buf bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+
+	for i := 0; i < len(values); {
+		level := byte(1)
+		if values[i] == nil {
+			level = 0
+		}
+		j := i + 1
+		for j < len(values) && (values[j] == nil) == (level == 0) {
+			j++
+		}
+		n := binary.PutUvarint(tmp[:], uint64(j-i)<<1)
+		buf.Write(tmp[:n])
+		buf.WriteByte(level)
+		i = j
+	}
+
+	return buf.Bytes()
+}
+
+// countingWriter считает записанные байты для вычисления смещений
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,360 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// thriftReader — минимальный декодер Thrift Compact Protocol для проверки метаданных.
+// Структуры разбираются в map[идентификатор поля]значение.
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		panic(fmt.Sprintf("thrift: неверный varint на позиции %d", r.pos))
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case 3:
+		return int64(int8(r.byte()))
+	case 4, thriftI32, thriftI64:
+		return r.zigzag()
+	case 7:
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.buf[r.pos:]))
+		r.pos += 8
+		return v
+	case thriftBinary:
+		n := int(r.varint())
+		s := string(r.buf[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case thriftList:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("thrift: неподдерживаемый тип %d", typ))
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var lastID int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		id := lastID + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0x0f)
+		lastID = id
+	}
+}
+
+// column — прочитанная колонка: уровни определения и значения без null
+type column struct {
+	name       string
+	physical   int64
+	repetition int64
+	converted  interface{}
+	levels     []int
+	values     []interface{}
+}
+
+// readFile проверяет обрамление файла и читает метаданные и страницы всех колонок
+func readFile(t *testing.T, data []byte) (map[int16]interface{}, []column) {
+	t.Helper()
+
+	if len(data) < 12 || string(data[:4]) != magic || string(data[len(data)-4:]) != magic {
+		t.Fatalf("файл не обрамлен %q", magic)
+	}
+	metaLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	metaStart := len(data) - 8 - metaLen
+	if metaStart < 4 {
+		t.Fatalf("неверная длина метаданных: %d", metaLen)
+	}
+	r := &thriftReader{buf: data[:len(data)-8], pos: metaStart}
+	meta := r.readStruct()
+	if r.pos != len(data)-8 {
+		t.Fatalf("метаданные прочитаны не до конца: %d из %d", r.pos, len(data)-8)
+	}
+
+	schema := meta[2].([]interface{})
+	rowGroups := meta[4].([]interface{})
+	if len(rowGroups) != 1 {
+		t.Fatalf("групп строк: %d, ожидалась 1", len(rowGroups))
+	}
+	chunks := rowGroups[0].(map[int16]interface{})[1].([]interface{})
+	if len(chunks) != len(schema)-1 {
+		t.Fatalf("колонок в группе строк: %d, в схеме: %d", len(chunks), len(schema)-1)
+	}
+
+	var columns []column
+	for i, chunk := range chunks {
+		element := schema[i+1].(map[int16]interface{})
+		c := column{
+			name:       element[4].(string),
+			physical:   element[1].(int64),
+			repetition: element[3].(int64),
+			converted:  element[6],
+		}
+
+		chunkMeta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+		if chunkMeta[1].(int64) != c.physical {
+			t.Errorf("%s: тип в группе строк %d, в схеме %d", c.name, chunkMeta[1], c.physical)
+		}
+		numValues := int(chunkMeta[5].(int64))
+
+		pr := &thriftReader{buf: data, pos: int(chunkMeta[9].(int64))}
+		page := pr.readStruct()
+		if page[1].(int64) != pageTypeData {
+			t.Fatalf("%s: тип страницы %d", c.name, page[1])
+		}
+		if n := page[5].(map[int16]interface{})[1].(int64); int(n) != numValues {
+			t.Errorf("%s: значений в странице %d, в группе строк %d", c.name, n, numValues)
+		}
+		body := data[pr.pos : pr.pos+int(page[3].(int64))]
+
+		nonNull := numValues
+		if c.repetition == repetitionOptional {
+			length := int(binary.LittleEndian.Uint32(body))
+			c.levels = decodeLevels(t, body[4:4+length], numValues)
+			body = body[4+length:]
+			nonNull = 0
+			for _, l := range c.levels {
+				nonNull += l
+			}
+		}
+		c.values = decodePlain(t, c.physical, body, nonNull)
+		columns = append(columns, c)
+	}
+
+	return meta, columns
+}
+
+// decodeLevels разбирает RLE-серии уровней определения разрядности 1
+func decodeLevels(t *testing.T, data []byte, count int) []int {
+	t.Helper()
+	r := &thriftReader{buf: data}
+	var levels []int
+	for r.pos < len(data) {
+		header := r.varint()
+		if header&1 != 0 {
+			t.Fatalf("ожидались только RLE-серии, найдена bit-packed")
+		}
+		level := int(r.byte())
+		for i := uint64(0); i < header>>1; i++ {
+			levels = append(levels, level)
+		}
+	}
+	if len(levels) != count {
+		t.Fatalf("уровней определения %d, ожидалось %d", len(levels), count)
+	}
+	return levels
+}
+
+// decodePlain разбирает значения в кодировке PLAIN
+func decodePlain(t *testing.T, physical int64, data []byte, count int) []interface{} {
+	t.Helper()
+	var values []interface{}
+	pos := 0
+	for i := 0; i < count; i++ {
+		switch physical {
+		case typeByteArray:
+			n := int(binary.LittleEndian.Uint32(data[pos:]))
+			values = append(values, string(data[pos+4:pos+4+n]))
+			pos += 4 + n
+		case typeDouble:
+			values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(data[pos:])))
+			pos += 8
+		case typeInt64:
+			values = append(values, int64(binary.LittleEndian.Uint64(data[pos:])))
+			pos += 8
+		default:
+			t.Fatalf("неизвестный физический тип %d", physical)
+		}
+	}
+	if pos != len(data) {
+		t.Fatalf("страница прочитана не до конца: %d из %d байт", pos, len(data))
+	}
+	return values
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	columns := []Column{
+		{Name: "ticker", Type: String},
+		{Name: "nav", Type: Double, Optional: true},
+		{Name: "funds", Type: Int64},
+		{Name: "note", Type: String, Optional: true},
+	}
+	nav := 3.25
+	rows := [][]interface{}{
+		{"TMOS", 1.5, int64(1), nil},
+		{"SBMX", nil, 2, "закрыт"},
+		{"", &nav, int64(3), nil},
+		{"AKMB", (*float64)(nil), int64(-4), nil},
+		{"Фонд ё", nil, int64(5), ""},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, columns)
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, got := readFile(t, buf.Bytes())
+
+	if meta[3].(int64) != int64(len(rows)) {
+		t.Errorf("num_rows = %d, want %d", meta[3], len(rows))
+	}
+	root := meta[2].([]interface{})[0].(map[int16]interface{})
+	if root[5].(int64) != int64(len(columns)) {
+		t.Errorf("num_children = %d, want %d", root[5], len(columns))
+	}
+	if rg := meta[4].([]interface{})[0].(map[int16]interface{}); rg[3].(int64) != int64(len(rows)) {
+		t.Errorf("row group num_rows = %d, want %d", rg[3], len(rows))
+	}
+
+	want := []struct {
+		physical   int64
+		repetition int64
+		utf8       bool
+		levels     []int
+		values     []interface{}
+	}{
+		{typeByteArray, repetitionRequired, true, nil,
+			[]interface{}{"TMOS", "SBMX", "", "AKMB", "Фонд ё"}},
+		{typeDouble, repetitionOptional, false, []int{1, 0, 1, 0, 0},
+			[]interface{}{1.5, 3.25}},
+		{typeInt64, repetitionRequired, false, nil,
+			[]interface{}{int64(1), int64(2), int64(3), int64(-4), int64(5)}},
+		{typeByteArray, repetitionOptional, true, []int{0, 1, 0, 0, 1},
+			[]interface{}{"закрыт", ""}},
+	}
+
+	for i, c := range got {
+		w := want[i]
+		if c.name != columns[i].Name {
+			t.Errorf("column %d name = %q, want %q", i, c.name, columns[i].Name)
+		}
+		if c.physical != w.physical || c.repetition != w.repetition {
+			t.Errorf("%s: type/repetition = %d/%d, want %d/%d", c.name, c.physical, c.repetition, w.physical, w.repetition)
+		}
+		if utf8 := c.converted == int64(convertedUTF8); utf8 != w.utf8 {
+			t.Errorf("%s: UTF8 annotation = %v, want %v", c.name, utf8, w.utf8)
+		}
+		if fmt.Sprint(c.levels) != fmt.Sprint(w.levels) {
+			t.Errorf("%s: definition levels = %v, want %v", c.name, c.levels, w.levels)
+		}
+		if fmt.Sprint(c.values) != fmt.Sprint(w.values) {
+			t.Errorf("%s: values = %v, want %v", c.name, c.values, w.values)
+		}
+	}
+}
+
+func TestWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, []Column{{Name: "ticker", Type: String}, {Name: "nav", Type: Double, Optional: true}})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, columns := readFile(t, buf.Bytes())
+	if meta[3].(int64) != 0 {
+		t.Errorf("num_rows = %d, want 0", meta[3])
+	}
+	for _, c := range columns {
+		if len(c.values) != 0 || len(c.levels) != 0 {
+			t.Errorf("%s: expected no values, got %v / %v", c.name, c.levels, c.values)
+		}
+	}
+}
+
+func TestWriterManyNulls(t *testing.T) {
+	// Длинные серии проверяют varint-заголовки RLE больше одного байта
+	var buf bytes.Buffer
+	w := NewWriter(&buf, []Column{{Name: "v", Type: Double, Optional: true}})
+	var wantLevels []int
+	for i := 0; i < 300; i++ {
+		var v interface{}
+		level := 0
+		if i >= 200 {
+			v, level = float64(i), 1
+		}
+		wantLevels = append(wantLevels, level)
+		if err := w.Write([]interface{}{v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, columns := readFile(t, buf.Bytes())
+	if fmt.Sprint(columns[0].levels) != fmt.Sprint(wantLevels) {
+		t.Error("definition levels do not match")
+	}
+	if len(columns[0].values) != 100 || columns[0].values[0] != 200.0 {
+		t.Errorf("values = %d starting with %v, want 100 starting with 200", len(columns[0].values), columns[0].values[0])
+	}
+}
+
+func TestWriterRejectsInvalidValues(t *testing.T) {
+	w := NewWriter(&bytes.Buffer{}, []Column{{Name: "ticker", Type: String}, {Name: "nav", Type: Double, Optional: true}})
+
+	tests := []struct {
+		name string
+		row  []interface{}
+	}{
+		{"null in required column", []interface{}{nil, 1.0}},
+		{"wrong type", []interface{}{"TMOS", "1.0"}},
+		{"wrong column count", []interface{}{"TMOS"}},
+	}
+	for _, tt := range tests {
+		if err := w.Write(tt.row); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+// MarkerMapping сопоставляет маркеры из таблицы (эмодзи, текст) с типизированными значениями
+type MarkerMapping struct {
+	TERTrend  map[string]models.TERTrend `json:"terTrend"`
+	InfoFlags map[string]models.InfoFlag `json:"infoFlags"`
+}
+
+// DefaultMarkerMapping возвращает сопоставление маркеров по умолчанию
+func DefaultMarkerMapping() MarkerMapping {
+	return MarkerMapping{
+		TERTrend: map[string]models.TERTrend{
+			"▲":   models.TERTrendUp,
+			"↑":   models.TERTrendUp,
+			"⬆":   models.TERTrendUp,
+			"🔺":   models.TERTrendUp,
+			"📈":   models.TERTrendUp,
+			"▼":   models.TERTrendDown,
+			"↓":   models.TERTrendDown,
+			"⬇":   models.TERTrendDown,
+			"🔻":   models.TERTrendDown,
+			"📉":   models.TERTrendDown,
+			"—":   models.TERTrendUnchanged,
+			"=":   models.TERTrendUnchanged,
+			"●":   models.TERTrendUnchanged,
+			"➖":   models.TERTrendUnchanged,
+			"без": models.TERTrendUnchanged,
+		},
+		InfoFlags: map[string]models.InfoFlag{
+			"🆕":      models.InfoFlagNewFund,
+			"new":    models.InfoFlagNewFund,
+			"нов":    models.InfoFlagNewFund,
+			"🔒":      models.InfoFlagClosed,
+			"закрыт": models.InfoFlagClosed,
+			"🎓":      models.InfoFlagQualifiedOnly,
+			"квал":   models.InfoFlagQualifiedOnly,
+			"⛔":      models.InfoFlagSuspended,
+			"🚫":      models.InfoFlagSuspended,
+			"приост": models.InfoFlagSuspended,
+		},
+	}
+}
+
+// LoadMarkerMapping загружает сопоставление из JSON файла поверх значений по умолчанию.
+// Пустой путь означает использование значений по умолчанию.
+func LoadMarkerMapping(path string) (MarkerMapping, error) {
+	mapping := DefaultMarkerMapping()
+	if path == "" {
+		return mapping, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return mapping, fmt.Errorf("ошибка чтения таблицы маркеров: %w", err)
+	}
+
+	var custom MarkerMapping
+	if err := json.Unmarshal(content, &custom); err != nil {
+		return mapping, fmt.Errorf("ошибка разбора таблицы маркеров: %w", err)
+	}
+
+	for marker, trend := range custom.TERTrend {
+		mapping.TERTrend[marker] = trend
+	}
+	for marker, flag := range custom.InfoFlags {
+		mapping.InfoFlags[marker] = flag
+	}
+
+	return mapping, nil
+}
+
+// BackfillMarkers классифицирует маркеры записей, сохраненных до появления колонок
+// ter_trend и info_flags, чтобы фильтры terTrend и infoFlag работали и по истории.
+// Вызывается один раз при запуске команды; если таких записей нет, историю не читает.
+func BackfillMarkers(repo *database.Repository, m MarkerMapping) {
+	pending, err := repo.HasUnclassifiedMarkers()
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: ошибка проверки маркеров истории: %v", err)
+		return
+	}
+	if !pending {
+		return
+	}
+
+	n, err := repo.BackfillMarkers(m.ClassifyTER, m.ClassifyInfo)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: ошибка заполнения маркеров истории: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("✓ Маркеры TER и отметки фондов заполнены для %d исторических записей", n)
+	}
+}
+
+// ClassifyTER определяет направление изменения TER по содержимому ячейки
+func (m MarkerMapping) ClassifyTER(raw string) models.TERTrend {
+	text := normalizeMarker(raw)
+	if text == "" {
+		return models.TERTrendUnknown
+	}
+
+	for _, marker := range sortedMarkers(m.TERTrend) {
+		if strings.Contains(text, normalizeMarker(marker)) {
+			return m.TERTrend[marker]
+		}
+	}
+	return models.TERTrendUnknown
+}
+
+// ClassifyInfo определяет информационные отметки по содержимому ячейки
+func (m MarkerMapping) ClassifyInfo(raw string) []models.InfoFlag {
+	text := normalizeMarker(raw)
+	flags := []models.InfoFlag{}
+	if text == "" {
+		return flags
+	}
+
+	seen := make(map[models.InfoFlag]bool)
+	for _, marker := range sortedMarkers(m.InfoFlags) {
+		needle := normalizeMarker(marker)
+		if !strings.Contains(text, needle) {
+			continue
+		}
+		flag := m.InfoFlags[marker]
+		if !seen[flag] {
+			seen[flag] = true
+			flags = append(flags, flag)
+		}
+		text = strings.ReplaceAll(text, needle, "")
+	}
+
+	// Нераспознанный остаток тоже отмечаем, чтобы новые маркеры были заметны
+	if strings.TrimSpace(text) != "" {
+		flags = append(flags, models.InfoFlagUnknown)
+	}
+
+	return flags
+}
+
+// normalizeMarker приводит текст к нижнему регистру и убирает селекторы вариантов эмодзи
+func normalizeMarker(text string) string {
+	return strings.ToLower(strings.ReplaceAll(cleanText(text), "\ufe0f", ""))
+}
+
+// sortedMarkers возвращает маркеры от длинных к коротким, чтобы сравнение было детерминированным
+func sortedMarkers[T any](markers map[string]T) []string {
+	keys := make([]string, 0, len(markers))
+	for k := range markers {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -1,10 +1,12 @@
 package scraper
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
+
+	"etf-scraper/internal/numparse"
 )
 
 // cleanText очищает текст от лишних пробелов
@@ -14,82 +16,37 @@ func cleanText(text string) string {
 	return space.ReplaceAllString(text, " ")
 }
 
-// parseNumber парсит строку в число, обрабатывая различные форматы
-func parseNumber(text string) *float64 {
-	text = cleanText(text)
-
-	if text == "" || text == "—" || text == "*—*" || text == "—*" ||
-		strings.Contains(text, "⸗️") || strings.Contains(text, "ℹ️") {
-		return nil
-	}
-
-	original := text
-
-	text = strings.ReplaceAll(text, "*", "")
-	text = strings.ReplaceAll(text, "'", "")
-	text = strings.ReplaceAll(text, "'", "")
-	text = strings.ReplaceAll(text, " ", "")
-	text = strings.ReplaceAll(text, " ", "")
-	text = strings.ReplaceAll(text, "\u00a0", "")
-	text = strings.ReplaceAll(text, "%", "")
-	text = strings.ReplaceAll(text, "₽", "")
-	text = strings.ReplaceAll(text, ",", ".")
-
-	re := regexp.MustCompile(`[^\d.\-]`)
-	text = re.ReplaceAllString(text, "")
-
-	if strings.Count(text, ".") > 1 {
-		parts := strings.Split(text, ".")
-		text = parts[0] + "." + strings.Join(parts[1:], "")
+// parsePercent парсит процентное значение (TER, доходность).
+// Для пустых ячеек и маркеров отсутствия данных возвращает nil без ошибки.
+func parsePercent(text string) (*float64, error) {
+	v, err := numparse.Parse(text)
+	if errors.Is(err, numparse.ErrEmpty) || errors.Is(err, numparse.ErrNoData) {
+		return nil, nil
 	}
-
-	text = strings.TrimSpace(text)
-
-	if text == "" || text == "." || text == "-" {
-		return nil
-	}
-
-	val, err := strconv.ParseFloat(text, 64)
 	if err != nil {
-		log.Printf("Не удалось распарсить число: '%s' -> '%s', ошибка: %v", original, text, err)
-		return nil
+		return nil, err
+	}
+	if v.Multiplier != 0 {
+		return nil, fmt.Errorf("неожиданный множитель в процентном значении %q", cleanText(text))
 	}
-	return &val
+	return &v.Number, nil
 }
 
-// parseRussianMonth конвертирует название месяца на русском в номер
-func parseRussianMonth(month string) string {
-	months := map[string]string{
-		"января":   "01",
-		"февраля":  "02",
-		"марта":    "03",
-		"апреля":   "04",
-		"мая":      "05",
-		"июня":     "06",
-		"июля":     "07",
-		"августа":  "08",
-		"сентября": "09",
-		"октября":  "10",
-		"ноября":   "11",
-		"декабря":  "12",
+// parseMillions парсит денежную сумму в миллионах рублей.
+// Значения с множителем ("1,2 млрд") пересчитываются в миллионы.
+func parseMillions(text string) (*float64, error) {
+	v, err := numparse.Parse(text)
+	if errors.Is(err, numparse.ErrEmpty) || errors.Is(err, numparse.ErrNoData) {
+		return nil, nil
 	}
-
-	monthLower := strings.ToLower(month)
-	if num, ok := months[monthLower]; ok {
-		return num
+	if err != nil {
+		return nil, err
 	}
-	return "00"
-}
-
-// formatRussianDate форматирует русскую дату в формат YYYY-MM-DD
-func formatRussianDate(day, month, year string) string {
-	monthNum := parseRussianMonth(month)
-
-	if len(day) == 1 {
-		day = "0" + day
+	if v.Percent {
+		return nil, fmt.Errorf("процент вместо суммы: %q", cleanText(text))
 	}
-
-	return year + "-" + monthNum + "-" + day
+	val := v.In(1e6)
+	return &val, nil
 }
 
 // min возвращает минимум из двух чисел
@@ -0,0 +1,67 @@
+package scraper
+
+import (
+	"strings"
+
+	"etf-scraper/internal/models"
+)
+
+// columnNames сопоставляет номер колонки таблицы с полем в БД
+var columnNames = map[int]string{
+	0:  "ticker",
+	1:  "trade_status",
+	2:  "management_company",
+	4:  "asset_class",
+	5:  "ter_percent",
+	6:  "ter_direction",
+	7:  "fund_name",
+	8:  "management_style",
+	9:  "target_index",
+	10: "currency",
+	11: "start_date",
+	12: "info_icon",
+	13: "price_change_6m",
+	14: "price_change_2024",
+	15: "price_change_2023",
+	16: "price_change_2022",
+	17: "price_change_2021",
+	18: "price_change_2020",
+	19: "nav_million_rub",
+}
+
+// ParseReport собирает ошибки парсинга за один запуск
+type ParseReport struct {
+	Errors []models.ParseError
+}
+
+// addCellError регистрирует ячейку, которую не удалось разобрать
+func (p *ParseReport) addCellError(row int, column, raw string, err error) {
+	p.Errors = append(p.Errors, models.ParseError{
+		Row:     row,
+		Kind:    models.ParseErrorCell,
+		Column:  column,
+		RawText: raw,
+		Reason:  err.Error(),
+	})
+}
+
+// RejectRow регистрирует отклоненную строку таблицы (cols — сырые ячейки)
+func (p *ParseReport) RejectRow(row int, cols []string, err error) {
+	p.Errors = append(p.Errors, models.ParseError{
+		Row:     row,
+		Kind:    models.ParseErrorRow,
+		RawText: strings.Join(cols, " | "),
+		Reason:  err.Error(),
+	})
+}
+
+// RejectedRows возвращает количество отклоненных строк
+func (p *ParseReport) RejectedRows() int {
+	count := 0
+	for _, pe := range p.Errors {
+		if pe.Kind == models.ParseErrorRow {
+			count++
+		}
+	}
+	return count
+}
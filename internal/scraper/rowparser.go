@@ -0,0 +1,99 @@
+package scraper
+
+import (
+	"fmt"
+	"log"
+
+	"etf-scraper/internal/dateparse"
+	"etf-scraper/internal/models"
+)
+
+// RowParser преобразует сырые значения ячеек в ETFData по правилам скрейпера.
+// Используется скрейпером и импортом исторических таблиц.
+type RowParser struct {
+	Markers     MarkerMapping
+	Report      *ParseReport
+	DateScraped string
+	Verbose     bool
+}
+
+// Parse разбирает строку; values содержит сырые значения по именам колонок БД
+// (ticker, ter_percent, start_date, ...). Отсутствующие колонки считаются пустыми.
+// Ошибки отдельных ячеек попадают в Report, ошибка возвращается только для отклоненной строки.
+func (p *RowParser) Parse(row int, values map[string]string) (*models.ETFData, error) {
+	ticker := cleanText(values["ticker"])
+	if ticker == "" {
+		return nil, fmt.Errorf("пустой тикер")
+	}
+
+	// number разбирает числовую ячейку и регистрирует ошибку в отчете
+	number := func(column string, parse func(string) (*float64, error)) *float64 {
+		val, err := parse(values[column])
+		if err != nil {
+			p.cellError(row, column, values[column], err)
+		}
+		return val
+	}
+
+	// date разбирает ячейку с датой в ISO формат; некорректные значения
+	// попадают в отчет и сохраняются пустой строкой
+	date := func(column string) string {
+		val, err := dateparse.ParseISO(values[column])
+		if err != nil {
+			p.cellError(row, column, values[column], err)
+			return ""
+		}
+		return val
+	}
+
+	return &models.ETFData{
+		DateScraped:     p.DateScraped,
+		Ticker:          ticker,
+		TradeStatus:     cleanText(values["trade_status"]),
+		ManagementCo:    cleanText(values["management_company"]),
+		AssetClass:      cleanText(values["asset_class"]),
+		TERPercent:      number("ter_percent", parsePercent),
+		TERDirection:    cleanText(values["ter_direction"]),
+		FundName:        cleanText(values["fund_name"]),
+		ManagementStyle: cleanText(values["management_style"]),
+		TargetIndex:     cleanText(values["target_index"]),
+		Currency:        cleanText(values["currency"]),
+		StartDate:       date("start_date"),
+		InfoIcon:        cleanText(values["info_icon"]),
+		TERTrend:        p.Markers.ClassifyTER(values["ter_direction"]),
+		InfoFlags:       p.Markers.ClassifyInfo(values["info_icon"]),
+		PriceChange6M:   number("price_change_6m", parsePercent),
+		PriceChange2024: number("price_change_2024", parsePercent),
+		PriceChange2023: number("price_change_2023", parsePercent),
+		PriceChange2022: number("price_change_2022", parsePercent),
+		PriceChange2021: number("price_change_2021", parsePercent),
+		PriceChange2020: number("price_change_2020", parsePercent),
+		NAVMillionRub:   number("nav_million_rub", parseMillions),
+		LastUpdateDate:  date("last_update_date"),
+	}, nil
+}
+
+// cellError регистрирует ошибку ячейки в отчете
+func (p *RowParser) cellError(row int, column, raw string, err error) {
+	if p.Report != nil {
+		p.Report.addCellError(row, column, raw, err)
+	}
+	if p.Verbose {
+		log.Printf("Строка %d, колонка %s: %v", row, column, err)
+	}
+}
+
+// Columns возвращает имена колонок, которые понимает Parse
+func Columns() []string {
+	return append([]string{}, fieldColumns...)
+}
+
+// fieldColumns перечисляет колонки в порядке etf_data
+var fieldColumns = []string{
+	"ticker", "trade_status", "management_company", "asset_class",
+	"ter_percent", "ter_direction", "fund_name", "management_style",
+	"target_index", "currency", "start_date", "info_icon",
+	"price_change_6m", "price_change_2024", "price_change_2023",
+	"price_change_2022", "price_change_2021", "price_change_2020",
+	"nav_million_rub", "last_update_date",
+}
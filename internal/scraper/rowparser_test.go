@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"testing"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+func TestRowParserReportsMalformedRows(t *testing.T) {
+	report := &ParseReport{}
+	parser := &RowParser{
+		Markers:     DefaultMarkerMapping(),
+		Report:      report,
+		DateScraped: "2024-03-15 10:00:00",
+	}
+
+	rows := []map[string]string{
+		// Строка 1 корректна
+		{"ticker": "SBMX", "ter_percent": "0,95%", "nav_million_rub": "12 345", "start_date": "01.02.2021"},
+		// Строка 2 без тикера отклоняется целиком
+		{"ticker": "  ", "fund_name": "Без тикера", "ter_percent": "1%"},
+		// Строка 3 сохраняется, но три ячейки не разобраны
+		{"ticker": "TMOS", "ter_percent": "abc", "nav_million_rub": "5%", "start_date": "32.13.2020"},
+		// Строка 4: процент с множителем
+		{"ticker": "TGLD", "price_change_2024": "1,5 млрд"},
+	}
+
+	var data []models.ETFData
+	for i, values := range rows {
+		row := i + 1
+		etf, err := parser.Parse(row, values)
+		if err != nil {
+			report.RejectRow(row, []string{values["ticker"], values["fund_name"], values["ter_percent"]}, err)
+			continue
+		}
+		data = append(data, *etf)
+	}
+
+	if len(data) != 3 {
+		t.Fatalf("parsed %d rows, want 3", len(data))
+	}
+	if data[1].TERPercent != nil || data[1].NAVMillionRub != nil || data[1].StartDate != "" {
+		t.Errorf("malformed cells must be empty: %+v", data[1])
+	}
+	if report.RejectedRows() != 1 {
+		t.Errorf("RejectedRows() = %d, want 1", report.RejectedRows())
+	}
+
+	db, err := database.NewDatabase(t.TempDir() + "/rowparser.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := database.NewRepository(db)
+
+	runID, err := repo.SaveScrapeRun(models.ScrapeRun{
+		StartedAt:   "2024-03-15 10:00:00",
+		FinishedAt:  "2024-03-15 10:00:05",
+		DateScraped: parser.DateScraped,
+		Status:      models.RunStatusSuccess,
+		RowsTotal:   len(rows),
+		RowsSaved:   len(data),
+		ErrorCount:  len(report.Errors),
+	}, report.Errors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.GetParseErrors(runID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []models.ParseError{
+		{Row: 2, Kind: models.ParseErrorRow, RawText: "   | Без тикера | 1%", Reason: "пустой тикер"},
+		{Row: 3, Kind: models.ParseErrorCell, Column: "ter_percent", RawText: "abc", Reason: `не удалось распарсить число "abc": недопустимый символ 'a'`},
+		{Row: 3, Kind: models.ParseErrorCell, Column: "start_date", RawText: "32.13.2020", Reason: `не удалось распарсить дату "32.13.2020": месяц вне диапазона: 13`},
+		{Row: 3, Kind: models.ParseErrorCell, Column: "nav_million_rub", RawText: "5%", Reason: `процент вместо суммы: "5%"`},
+		{Row: 4, Kind: models.ParseErrorCell, Column: "price_change_2024", RawText: "1,5 млрд", Reason: `неожиданный множитель в процентном значении "1,5 млрд"`},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d parse errors, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		w.RunID = runID
+		if g != w {
+			t.Errorf("parse error %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
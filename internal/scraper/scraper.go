@@ -1,51 +1,99 @@
 package scraper
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"net/http"
 	"regexp"
 	"strings"
 	"time"
 
+	"etf-scraper/internal/alerts"
+	"etf-scraper/internal/analytics"
 	"etf-scraper/internal/config"
 	"etf-scraper/internal/database"
+	"etf-scraper/internal/dateparse"
+	"etf-scraper/internal/events"
 	"etf-scraper/internal/models"
+	"etf-scraper/internal/webhook"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gocolly/colly/v2"
 )
 
+// ErrNotModified возвращается, когда страница не изменилась с прошлого запуска (HTTP 304)
+var ErrNotModified = errors.New("страница не изменилась")
+
 // Scraper представляет скрейпер ETF данных
 type Scraper struct {
-	config *config.Config
-	repo   *database.Repository
+	config   *config.Config
+	repo     *database.Repository
+	markers  MarkerMapping
+	webhooks *webhook.Dispatcher
+
+	// pendingCache хранит валидаторы последнего ответа до успешного сохранения данных
+	pendingCache *models.HTTPCacheEntry
+
+	// report и dateScraped относятся к последнему вызову ScrapeData
+	report      *ParseReport
+	dateScraped string
+	rowsTotal   int
 }
 
 // NewScraper создает новый скрейпер
 func NewScraper(cfg *config.Config, repo *database.Repository) *Scraper {
+	markers, err := LoadMarkerMapping(cfg.MarkersPath)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: %v, используются маркеры по умолчанию", err)
+	}
+
 	return &Scraper{
-		config: cfg,
-		repo:   repo,
+		config:   cfg,
+		repo:     repo,
+		markers:  markers,
+		webhooks: webhook.NewDispatcher(repo),
 	}
 }
 
+// Wait ждет, пока уйдут уведомления вебхуков, отправляемые Run в фоне
+func (s *Scraper) Wait() {
+	s.webhooks.Wait()
+}
+
 // Run выполняет скрейпинг и сохранение данных
 func (s *Scraper) Run() error {
 	log.Println("==================================================")
 	log.Printf("Запуск скрейпера: %s", time.Now().Format("2006-01-02 15:04:05"))
 	log.Println("==================================================")
 
+	startedAt := time.Now()
+
 	data, err := s.ScrapeData()
+	if errors.Is(err, ErrNotModified) {
+		log.Println("✓ Данные на сайте не изменились, сохранение пропущено")
+		return nil
+	}
 	if err != nil {
 		log.Printf("✗ Ошибка при скрейпинге: %v", err)
+		s.notifyFailure(s.recordRun(startedAt, 0, err))
 		return err
 	}
 
 	if err := s.repo.SaveETFs(data); err != nil {
 		log.Printf("✗ Ошибка при сохранении: %v", err)
+		s.notifyFailure(s.recordRun(startedAt, 0, err))
 		return err
 	}
 
+	s.recordRun(startedAt, len(data), nil)
+	s.savePendingCache()
+	newEvents := events.GenerateLogged(s.repo, s.dateScraped, s.config.NAVThresholds)
+	s.webhooks.NotifyEvents(newEvents)
+	alerts.NewEngine(s.repo, s.config).EvaluateLogged(s.dateScraped)
+	analytics.UpdateRiskLogged(s.repo, s.dateScraped)
+
 	log.Println("✓ Скрейпинг успешно завершен")
 	return nil
 }
@@ -59,12 +107,33 @@ func (s *Scraper) ScrapeData() ([]models.ETFData, error) {
 	var rowCount int
 	var errorCount int
 
+	s.report = &ParseReport{}
+	s.dateScraped = time.Now().Format("2006-01-02 15:04:05")
+	s.rowsTotal = 0
+
 	c := colly.NewCollector(
 		colly.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"),
 	)
 
 	c.SetRequestTimeout(30 * time.Second)
 
+	// Условный GET: отправляем валидаторы, сохраненные при прошлом запуске
+	cached := s.loadHTTPCache()
+	notModified := false
+	s.pendingCache = nil
+
+	c.OnRequest(func(r *colly.Request) {
+		if cached == nil {
+			return
+		}
+		if cached.ETag != "" {
+			r.Headers.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			r.Headers.Set("If-Modified-Since", cached.LastModified)
+		}
+	})
+
 	// Парсим дату обновления
 	c.OnHTML("body", func(e *colly.HTMLElement) {
 		lastUpdateDate = s.parseUpdateDate(e.Text)
@@ -90,6 +159,7 @@ func (s *Scraper) ScrapeData() ([]models.ETFData, error) {
 			// Извлекаем данные из строки
 			etf, err := s.parseTableRow(row, i, lastUpdateDate)
 			if err != nil {
+				s.report.RejectRow(i, rowCells(row), err)
 				if s.config.Verbose {
 					log.Printf("Строка %d: %v", i, err)
 				}
@@ -102,20 +172,32 @@ func (s *Scraper) ScrapeData() ([]models.ETFData, error) {
 	})
 
 	c.OnError(func(r *colly.Response, err error) {
+		if r.StatusCode == http.StatusNotModified {
+			notModified = true
+			return
+		}
 		log.Printf("Ошибка при запросе: %v", err)
 	})
 
 	c.OnResponse(func(r *colly.Response) {
 		log.Printf("Получен ответ: %d байт, статус: %d", len(r.Body), r.StatusCode)
+		s.rememberValidators(r)
 	})
 
 	err := c.Visit(s.config.ScraperURL)
+	if notModified {
+		log.Printf("Сервер ответил 304 Not Modified")
+		return nil, ErrNotModified
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	s.rowsTotal = rowCount
+
 	log.Printf("Обработано строк: %d", rowCount)
-	log.Printf("Ошибок парсинга: %d", errorCount)
+	log.Printf("Отклонено строк: %d", errorCount)
+	log.Printf("Ошибок парсинга ячеек: %d", len(s.report.Errors)-s.report.RejectedRows())
 	log.Printf("Успешно извлечено записей: %d", len(data))
 	log.Printf("Дата обновления с сайта: %s", lastUpdateDate)
 
@@ -130,6 +212,93 @@ func (s *Scraper) ScrapeData() ([]models.ETFData, error) {
 	return data, nil
 }
 
+// loadHTTPCache загружает валидаторы прошлого ответа, если кеширование включено
+func (s *Scraper) loadHTTPCache() *models.HTTPCacheEntry {
+	if !s.config.HTTPCache {
+		return nil
+	}
+
+	entry, err := s.repo.GetHTTPCache(s.config.ScraperURL)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Не удалось прочитать HTTP кеш: %v", err)
+		return nil
+	}
+	if entry != nil && s.config.Verbose {
+		log.Printf("HTTP кеш: ETag=%q, Last-Modified=%q", entry.ETag, entry.LastModified)
+	}
+	return entry
+}
+
+// rememberValidators запоминает ETag и Last-Modified из ответа
+func (s *Scraper) rememberValidators(r *colly.Response) {
+	if !s.config.HTTPCache || r.Headers == nil {
+		return
+	}
+
+	etag := r.Headers.Get("ETag")
+	lastModified := r.Headers.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return
+	}
+
+	s.pendingCache = &models.HTTPCacheEntry{
+		URL:          s.config.ScraperURL,
+		ETag:         etag,
+		LastModified: lastModified,
+		UpdatedAt:    time.Now().Format("2006-01-02 15:04:05"),
+	}
+}
+
+// savePendingCache сохраняет валидаторы после успешной записи данных
+func (s *Scraper) savePendingCache() {
+	if s.pendingCache == nil {
+		return
+	}
+
+	if err := s.repo.SaveHTTPCache(*s.pendingCache); err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Не удалось сохранить HTTP кеш: %v", err)
+	}
+	s.pendingCache = nil
+}
+
+// recordRun сохраняет запуск и отчет об ошибках парсинга
+func (s *Scraper) recordRun(startedAt time.Time, rowsSaved int, runErr error) models.ScrapeRun {
+	run := models.ScrapeRun{
+		StartedAt:   startedAt.Format("2006-01-02 15:04:05"),
+		FinishedAt:  time.Now().Format("2006-01-02 15:04:05"),
+		DateScraped: s.dateScraped,
+		Status:      models.RunStatusSuccess,
+		RowsTotal:   s.rowsTotal,
+		RowsSaved:   rowsSaved,
+	}
+
+	var parseErrors []models.ParseError
+	if s.report != nil {
+		parseErrors = s.report.Errors
+	}
+	run.ErrorCount = len(parseErrors)
+
+	if runErr != nil {
+		run.Status = models.RunStatusFailed
+		run.Message = runErr.Error()
+	}
+
+	runID, err := s.repo.SaveScrapeRun(run, parseErrors)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Не удалось сохранить отчет о запуске: %v", err)
+		return run
+	}
+	run.ID = runID
+
+	log.Printf("Запуск #%d сохранен, ошибок парсинга: %d", runID, run.ErrorCount)
+	return run
+}
+
+// notifyFailure отправляет подписчикам уведомление о неудачном запуске
+func (s *Scraper) notifyFailure(run models.ScrapeRun) {
+	s.webhooks.NotifyScrapeFailure(run)
+}
+
 // parseUpdateDate извлекает дату обновления из текста страницы
 func (s *Scraper) parseUpdateDate(text string) string {
 	idx := strings.Index(text, "Последнее обновление:")
@@ -145,30 +314,29 @@ func (s *Scraper) parseUpdateDate(text string) string {
 
 	fragment = strings.TrimSpace(fragment)
 
-	re := regexp.MustCompile(`(\d{1,2})\s+(\p{L}+)\s+(\d{4})`)
-	matches := re.FindStringSubmatch(fragment)
-
-	if len(matches) >= 4 {
-		day := matches[1]
-		month := matches[2]
-		year := matches[3]
+	re := regexp.MustCompile(`\d{1,2}\s+\p{L}+\.?\s+\d{4}`)
+	match := re.FindString(fragment)
+	if match == "" {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Не удалось распарсить дату")
+		log.Printf("Фрагмент: '%s'", fragment[:min(50, len(fragment))])
+		return ""
+	}
 
-		dateStr := formatRussianDate(day, month, year)
-		log.Printf("Найдена дата обновления: '%s' (исходная: %s %s %s)", dateStr, day, month, year)
-		return dateStr
+	date, err := dateparse.Parse(match)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: %v", err)
+		s.report.addCellError(0, "last_update_date", match, err)
+		return ""
 	}
 
-	log.Printf("ПРЕДУПРЕЖДЕНИЕ: Не удалось распарсить дату")
-	log.Printf("Фрагмент: '%s'", fragment[:min(50, len(fragment))])
-	return ""
+	dateStr := date.ISO()
+	log.Printf("Найдена дата обновления: '%s' (исходная: %s)", dateStr, match)
+	return dateStr
 }
 
 // parseTableRow парсит одну строку таблицы
 func (s *Scraper) parseTableRow(row *goquery.Selection, index int, lastUpdateDate string) (*models.ETFData, error) {
-	var cols []string
-	row.Find("td").Each(func(j int, cell *goquery.Selection) {
-		cols = append(cols, cell.Text())
-	})
+	cols := rowCells(row)
 
 	if len(cols) < 20 {
 		return nil, fmt.Errorf("недостаточно колонок (%d)", len(cols))
@@ -184,30 +352,21 @@ func (s *Scraper) parseTableRow(row *goquery.Selection, index int, lastUpdateDat
 		}
 	}
 
-	now := time.Now().Format("2006-01-02 15:04:05")
-
-	etf := &models.ETFData{
-		DateScraped:     now,
-		Ticker:          cleanText(cols[0]),
-		TradeStatus:     cleanText(cols[1]),
-		ManagementCo:    cleanText(cols[2]),
-		AssetClass:      cleanText(cols[4]),
-		TERPercent:      parseNumber(cols[5]),
-		TERDirection:    cleanText(cols[6]),
-		FundName:        cleanText(cols[7]),
-		ManagementStyle: cleanText(cols[8]),
-		TargetIndex:     cleanText(cols[9]),
-		Currency:        cleanText(cols[10]),
-		StartDate:       cleanText(cols[11]),
-		InfoIcon:        cleanText(cols[12]),
-		PriceChange6M:   parseNumber(cols[13]),
-		PriceChange2024: parseNumber(cols[14]),
-		PriceChange2023: parseNumber(cols[15]),
-		PriceChange2022: parseNumber(cols[16]),
-		PriceChange2021: parseNumber(cols[17]),
-		PriceChange2020: parseNumber(cols[18]),
-		NAVMillionRub:   parseNumber(cols[19]),
-		LastUpdateDate:  lastUpdateDate,
+	values := map[string]string{"last_update_date": lastUpdateDate}
+	for col, name := range columnNames {
+		values[name] = cols[col]
+	}
+
+	parser := &RowParser{
+		Markers:     s.markers,
+		Report:      s.report,
+		DateScraped: s.dateScraped,
+		Verbose:     s.config.Verbose,
+	}
+
+	etf, err := parser.Parse(index, values)
+	if err != nil {
+		return nil, err
 	}
 
 	if s.config.Verbose {
@@ -226,6 +385,15 @@ func (s *Scraper) parseTableRow(row *goquery.Selection, index int, lastUpdateDat
 	return etf, nil
 }
 
+// rowCells возвращает текст всех ячеек строки таблицы
+func rowCells(row *goquery.Selection) []string {
+	var cols []string
+	row.Find("td").Each(func(j int, cell *goquery.Selection) {
+		cols = append(cols, cell.Text())
+	})
+	return cols
+}
+
 // PrintStats выводит статистику БД
 func (s *Scraper) PrintStats() error {
 	totalRecords, uniqueTickers, scrapeSessions, err := s.repo.GetStats()
@@ -254,6 +422,14 @@ func (s *Scraper) PrintTopFunds(limit int) error {
 		return err
 	}
 
+	// Оценка потоков с прошлого сеанса; при первом запуске ее нет
+	flows := make(map[string]float64)
+	if report, err := analytics.Flows(s.repo, "", ""); err == nil {
+		for _, f := range report.Funds {
+			flows[f.Ticker] = f.NetFlow
+		}
+	}
+
 	for i, etf := range topFunds {
 		terVal := 0.0
 		if etf.TERPercent != nil {
@@ -264,8 +440,16 @@ func (s *Scraper) PrintTopFunds(limit int) error {
 			navVal = *etf.NAVMillionRub
 		}
 
-		fmt.Printf("%2d. %-8s | %-40s | TER: %5.2f%% | СЧА: %10.0f млн ₽\n",
-			i+1, etf.Ticker, truncate(etf.FundName, 40), terVal, navVal)
+		flowVal := "—"
+		if flow, ok := flows[etf.Ticker]; ok {
+			if flow = math.Round(flow); flow == 0 {
+				flow = 0 // без «-0»
+			}
+			flowVal = fmt.Sprintf("%+.0f", flow)
+		}
+
+		fmt.Printf("%2d. %-8s | %-40s | TER: %5.2f%% | СЧА: %10.0f млн ₽ | Поток: %8s млн ₽\n",
+			i+1, etf.Ticker, truncate(etf.FundName, 40), terVal, navVal, flowVal)
 	}
 
 	return nil
@@ -0,0 +1,214 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"etf-scraper/internal/config"
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+// tableRow собирает строку таблицы из 20 ячеек в порядке колонок сайта
+func tableRow(ticker, ter, nav string) string {
+	cells := make([]string, 20)
+	cells[0] = ticker
+	cells[1] = "Торгуется"
+	cells[2] = "Тестовая УК"
+	cells[4] = "Акции"
+	cells[5] = ter
+	cells[7] = "Фонд " + ticker
+	cells[10] = "RUB"
+	cells[11] = "01.02.2021"
+	cells[19] = nav
+
+	var b strings.Builder
+	b.WriteString("<tr>")
+	for _, c := range cells {
+		fmt.Fprintf(&b, "<td>%s</td>", c)
+	}
+	b.WriteString("</tr>")
+	return b.String()
+}
+
+// testPage — страница с датой обновления и двумя фондами
+var testPage = `<html><body>
+<p>Последнее обновление: 15 марта 2024</p>
+<table><tr><th>Тикер</th></tr>` +
+	tableRow("SBMX", "0,95%", "12 345") +
+	tableRow("TMOS", "0,79%", "1,2 млрд") +
+	`</table></body></html>`
+
+// conditionalServer отдает testPage с ETag и отвечает 304 на совпавший If-None-Match
+type conditionalServer struct {
+	mu          sync.Mutex
+	etag        string
+	requests    int
+	notModified int
+	ifNoneMatch []string
+}
+
+func (cs *conditionalServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.requests++
+	cs.ifNoneMatch = append(cs.ifNoneMatch, r.Header.Get("If-None-Match"))
+	if r.Header.Get("If-None-Match") == cs.etag {
+		cs.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("ETag", cs.etag)
+	w.Header().Set("Last-Modified", "Fri, 15 Mar 2024 10:00:00 GMT")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, testPage)
+}
+
+func newTestScraper(t *testing.T, url string) (*Scraper, *database.Repository, *database.Database) {
+	t.Helper()
+	db, err := database.NewDatabase(t.TempDir() + "/scraper.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := database.NewRepository(db)
+	cfg := &config.Config{ScraperURL: url, HTTPCache: true}
+	s := NewScraper(cfg, repo)
+	t.Cleanup(s.Wait)
+	return s, repo, db
+}
+
+func countRows(t *testing.T, db *database.Database, table string) int {
+	t.Helper()
+	var n int
+	if err := db.DB.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestRunConditionalGet(t *testing.T) {
+	cs := &conditionalServer{etag: `"v1"`}
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+
+	s, repo, db := newTestScraper(t, srv.URL)
+
+	if err := s.Run(); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if got := countRows(t, db, "etf_data"); got != 2 {
+		t.Fatalf("etf_data after first run = %d, want 2", got)
+	}
+	if got := countRows(t, db, "scrape_runs"); got != 1 {
+		t.Fatalf("scrape_runs after first run = %d, want 1", got)
+	}
+
+	entry, err := repo.GetHTTPCache(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry == nil || entry.ETag != `"v1"` || entry.LastModified != "Fri, 15 Mar 2024 10:00:00 GMT" {
+		t.Fatalf("cached validators = %+v, want ETag \"v1\" and Last-Modified", entry)
+	}
+
+	// Второй запуск отправляет ETag и получает 304: ничего не сохраняется
+	if err := s.Run(); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if cs.requests != 2 || cs.notModified != 1 {
+		t.Fatalf("requests = %d, 304 = %d, want 2 and 1", cs.requests, cs.notModified)
+	}
+	if cs.ifNoneMatch[0] != "" || cs.ifNoneMatch[1] != `"v1"` {
+		t.Errorf("If-None-Match = %q, want [\"\" \"v1\"]", cs.ifNoneMatch)
+	}
+	if got := countRows(t, db, "etf_data"); got != 2 {
+		t.Errorf("etf_data after 304 = %d, want 2", got)
+	}
+	if got := countRows(t, db, "scrape_runs"); got != 1 {
+		t.Errorf("scrape_runs after 304 = %d, want 1", got)
+	}
+
+	// Страница изменилась: новый ETag загружается и сохраняется
+	cs.etag = `"v2"`
+	if err := s.Run(); err != nil {
+		t.Fatalf("third run: %v", err)
+	}
+	if got := countRows(t, db, "scrape_runs"); got != 2 {
+		t.Errorf("scrape_runs after change = %d, want 2", got)
+	}
+	entry, err = repo.GetHTTPCache(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry == nil || entry.ETag != `"v2"` {
+		t.Errorf("cached validators = %+v, want ETag \"v2\"", entry)
+	}
+}
+
+func TestRunKeepsValidatorsUntilSaved(t *testing.T) {
+	cs := &conditionalServer{etag: `"v1"`}
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+
+	s, repo, db := newTestScraper(t, srv.URL)
+
+	// Сохранение данных падает: валидаторы не должны попасть в кеш,
+	// иначе следующий запуск получит 304 и данные будут потеряны
+	if _, err := db.DB.Exec("DROP TABLE etf_data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Run(); err == nil {
+		t.Fatal("expected save error")
+	}
+
+	entry, err := repo.GetHTTPCache(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry != nil {
+		t.Fatalf("validators saved after failed run: %+v", entry)
+	}
+
+	runs, err := repo.GetScrapeRuns(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Status != models.RunStatusFailed {
+		t.Fatalf("runs = %+v, want one failed run", runs)
+	}
+
+	// Следующий запуск идет без If-None-Match
+	s.Run()
+	if cs.ifNoneMatch[1] != "" {
+		t.Errorf("If-None-Match after failed run = %q, want empty", cs.ifNoneMatch[1])
+	}
+}
+
+func TestRunEmptyPageSkipsValidators(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"empty"`)
+		fmt.Fprint(w, "<html><body>Технические работы</body></html>")
+	}))
+	defer srv.Close()
+
+	s, repo, _ := newTestScraper(t, srv.URL)
+
+	if err := s.Run(); err == nil {
+		t.Fatal("expected error for a page without a table")
+	}
+	entry, err := repo.GetHTTPCache(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry != nil {
+		t.Errorf("validators saved for a page without data: %+v", entry)
+	}
+}
@@ -0,0 +1,205 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// maxTerms ограничивает число слов запроса
+const maxTerms = 10
+
+// Веса совпадений слова при оценке без FTS5
+const (
+	exactMatch  = 1.0
+	prefixMatch = 0.7
+	fuzzyMatch  = 0.4
+)
+
+// Term — слово запроса. Фонд подходит под запрос, если каждое слово совпало
+// с началом какого-либо слова фонда (в исходном виде или в транслитерации)
+// либо с одним из слов Fuzzy.
+type Term struct {
+	Text  string
+	Forms []string // префиксы: слово и его латинская транслитерация
+	Fuzzy []string // слова словаря, отличающиеся опечаткой
+}
+
+// Parse разбирает запрос на слова; повторы отбрасываются
+func Parse(query string) []Term {
+	var terms []Term
+	seen := make(map[string]bool)
+	for _, w := range Words(query) {
+		if seen[w] || len(terms) == maxTerms {
+			continue
+		}
+		seen[w] = true
+
+		t := Term{Text: w, Forms: []string{w}}
+		if l := ToLatin(w); l != w {
+			t.Forms = append(t.Forms, l)
+		}
+		terms = append(terms, t)
+	}
+	return terms
+}
+
+// Expand подбирает слова с опечатками по словарю индекса — только для слов запроса,
+// которые не совпали ни с одним словом словаря
+func Expand(terms []Term, vocabulary []string) {
+	for i := range terms {
+		t := &terms[i]
+		if t.hasPrefixIn(vocabulary) {
+			continue
+		}
+
+		query := ToLatin(t.Text)
+		limit := maxTypos(query)
+		if limit == 0 {
+			continue
+		}
+		n := len([]rune(query))
+		for _, word := range vocabulary {
+			w := []rune(ToLatin(word))
+			if len(w) < n-limit {
+				continue
+			}
+			// Слово словаря сравнивается целиком и его начало той же длины,
+			// чтобы опечатка в начале длинного слова тоже находилась
+			head := w
+			if len(head) > n {
+				head = head[:n]
+			}
+			if distance(query, string(head)) <= limit || distance(query, string(w)) <= limit {
+				t.Fuzzy = append(t.Fuzzy, word)
+			}
+		}
+	}
+}
+
+func (t Term) hasPrefixIn(vocabulary []string) bool {
+	for _, word := range vocabulary {
+		for _, f := range t.Forms {
+			if strings.HasPrefix(word, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// match оценивает совпадение слова текста со словом запроса; 0 — не совпало
+func (t Term) match(word string) float64 {
+	best := 0.0
+	for _, w := range []string{Normalize(word), ToLatin(word)} {
+		for _, f := range t.Forms {
+			switch {
+			case w == f:
+				return exactMatch
+			case strings.HasPrefix(w, f):
+				best = max(best, prefixMatch)
+			}
+		}
+		for _, z := range t.Fuzzy {
+			if w == z {
+				best = max(best, fuzzyMatch)
+			}
+		}
+	}
+	return best
+}
+
+// MatchExpression строит выражение MATCH для FTS5: слова запроса через AND,
+// формы слова — префиксные запросы, слова с опечатками — точные
+func MatchExpression(terms []Term) string {
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		var alts []string
+		for _, f := range t.Forms {
+			alts = append(alts, quote(f)+"*")
+		}
+		for _, z := range t.Fuzzy {
+			alts = append(alts, quote(z))
+		}
+		parts = append(parts, "("+strings.Join(alts, " OR ")+")")
+	}
+	return strings.Join(parts, " AND ")
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// Field — текстовое поле фонда и его вес в оценке релевантности
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Score оценивает релевантность фонда без FTS5: сумма лучших совпадений слов запроса
+// с учетом веса полей; 0 — какое-то слово не найдено
+func Score(terms []Term, fields []Field) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, t := range terms {
+		best := 0.0
+		for _, f := range fields {
+			for _, w := range Words(f.Text) {
+				best = max(best, t.match(w)*f.Weight)
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total
+}
+
+// Highlight выделяет в тексте слова, совпавшие с запросом, тегами <mark>;
+// остальной текст экранируется для HTML. false — совпадений нет.
+func Highlight(text string, terms []Term) (string, bool) {
+	var b strings.Builder
+	found := false
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		if j == i {
+			for j < len(runes) && !isWordRune(runes[j]) {
+				j++
+			}
+			b.WriteString(html.EscapeString(string(runes[i:j])))
+			i = j
+			continue
+		}
+
+		word := string(runes[i:j])
+		if matched(word, terms) {
+			found = true
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		i = j
+	}
+	return b.String(), found
+}
+
+func matched(word string, terms []Term) bool {
+	for _, t := range terms {
+		if t.match(word) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,134 @@
+package search
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	terms := Parse("Сбер, СБЕР sbmx Ёлка 2024")
+	want := []Term{
+		{Text: "сбер", Forms: []string{"сбер", "sber"}},
+		{Text: "sbmx", Forms: []string{"sbmx"}},
+		{Text: "елка", Forms: []string{"елка", "elka"}},
+		{Text: "2024", Forms: []string{"2024"}},
+	}
+	if len(terms) != len(want) {
+		t.Fatalf("Parse() = %+v, want %+v", terms, want)
+	}
+	for i, w := range want {
+		if terms[i].Text != w.Text || !slices.Equal(terms[i].Forms, w.Forms) {
+			t.Errorf("term %d = %+v, want %+v", i, terms[i], w)
+		}
+	}
+
+	if n := len(Parse(strings.Repeat("a b c d e f g h i j k l ", 2))); n != maxTerms {
+		t.Errorf("Parse() kept %d terms, want %d", n, maxTerms)
+	}
+	if terms := Parse(" ,.- "); len(terms) != 0 {
+		t.Errorf("Parse() of punctuation = %+v", terms)
+	}
+}
+
+func TestToLatin(t *testing.T) {
+	for input, want := range map[string]string{
+		"Сбер":      "sber",
+		"Тинькофф":  "tinkoff",
+		"Щука-2024": "shchuka-2024",
+		"Ёж":        "ezh",
+		"SBMX":      "sbmx",
+	} {
+		if got := ToLatin(input); got != want {
+			t.Errorf("ToLatin(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	vocabulary := []string{"sber", "sberbank", "сбербанк", "tinkoff", "тинькофф", "zoloto", "золото", "indeks"}
+
+	tests := []struct {
+		query string
+		fuzzy []string
+	}{
+		// Совпадение с началом слова словаря — опечатки не ищутся
+		{"sber", nil},
+		{"тинь", nil},
+		// Кириллица находит латинскую транслитерацию словаря
+		{"сбер", nil},
+		// Одна опечатка в слове из 4–7 букв
+		{"tinkpff", []string{"tinkoff", "тинькофф"}},
+		{"злото", []string{"zoloto", "золото"}},
+		// Опечатка в начале длинного слова: сравнивается начало слова словаря той же длины
+		{"sbrebank", []string{"sberbank", "сбербанк"}},
+		// Короткие слова должны совпадать точно
+		{"sbr", nil},
+		// Две опечатки в слове короче 8 букв — слишком много
+		{"zlotp", nil},
+	}
+
+	for _, tt := range tests {
+		terms := Parse(tt.query)
+		Expand(terms, vocabulary)
+		if !slices.Equal(terms[0].Fuzzy, tt.fuzzy) {
+			t.Errorf("Expand(%q) fuzzy = %q, want %q", tt.query, terms[0].Fuzzy, tt.fuzzy)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	fields := []Field{
+		{Text: "SBMX", Weight: 10},
+		{Text: "Сбербанк Индекс МосБиржи", Weight: 4},
+		{Text: "Сбер Управление Активами", Weight: 2},
+		{Text: IndexText("SBMX", "Сбербанк Индекс МосБиржи", "Сбер Управление Активами"), Weight: 1},
+	}
+
+	tests := []struct {
+		query string
+		want  float64
+	}{
+		{"sbmx", 10},
+		// Начало слова «Сбербанк» в названии (0,7 × 4) весит больше точного «Сбер» в УК (1 × 2)
+		{"сбер", 2.8},
+		{"sber", 2.8},
+		{"sbm", 7},
+		// Оценки слов складываются
+		{"sbmx индекс", 14},
+		{"индекс indeks", 8},
+		// Опечатка: 0,4 × 4
+		{"мосбирди", 1.6},
+		// Каждое слово запроса должно найтись
+		{"sbmx золото", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		terms := Parse(tt.query)
+		Expand(terms, vocabularyOf(fields))
+		if got := Score(terms, fields); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Score(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	terms := Parse("sber моск")
+	got, ok := Highlight("Сбербанк <Москва> & Сибирь", terms)
+	if !ok || got != "<mark>Сбербанк</mark> &lt;<mark>Москва</mark>&gt; &amp; Сибирь" {
+		t.Errorf("Highlight() = %q, %v", got, ok)
+	}
+	if got, ok := Highlight("Золото", terms); ok || got != "Золото" {
+		t.Errorf("Highlight() without matches = %q, %v", got, ok)
+	}
+}
+
+func vocabularyOf(fields []Field) []string {
+	var words []string
+	for _, f := range fields {
+		words = append(words, Words(f.Text)...)
+	}
+	return words
+}
@@ -0,0 +1,95 @@
+// Package search разбирает поисковые запросы по фондам: нормализация и транслитерация
+// слов, допуск опечаток, оценка релевантности и подсветка совпадений. Полнотекстовый
+// индекс SQLite FTS5 и запасной поиск без него используют одни и те же правила.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// latin — транслитерация кириллицы латиницей (упрощенная паспортная)
+var latin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// Normalize приводит слово к нижнему регистру и заменяет «ё» на «е»
+func Normalize(word string) string {
+	return strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+}
+
+// ToLatin транслитерирует кириллицу латиницей, остальные символы не меняет:
+// «Сбер» → «sber», «Тинькофф» → «tinkoff»
+func ToLatin(s string) string {
+	var b strings.Builder
+	for _, r := range Normalize(s) {
+		if l, ok := latin[r]; ok {
+			b.WriteString(l)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Words разбивает текст на нормализованные слова из букв и цифр
+// (так же, как токенизатор unicode61 индекса FTS5)
+func Words(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, f := range fields {
+		fields[i] = Normalize(f)
+	}
+	return fields
+}
+
+// IndexText собирает латинскую транслитерацию полей фонда: по ней находятся
+// кириллические названия, набранные латиницей, и наоборот
+func IndexText(fields ...string) string {
+	var words []string
+	for _, f := range fields {
+		for _, w := range Words(f) {
+			words = append(words, ToLatin(w))
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// maxTypos — допустимое число опечаток в слове запроса: короткие слова
+// должны совпадать точно
+func maxTypos(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	}
+	return 2
+}
+
+// distance — расстояние Левенштейна между строками (по символам)
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -4,9 +4,15 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/dump"
+	"etf-scraper/internal/models"
 	"etf-scraper/internal/scraper"
+
+	"github.com/gorilla/mux"
 )
 
 // HandleAdminScrape запускает скрейпинг (только для администраторов)
@@ -56,6 +62,8 @@ func (h *Handlers) HandleAdminStatus(w http.ResponseWriter, r *http.Request) {
 		"totalRecords":   totalRecords,
 		"uniqueTickers":  uniqueTickers,
 		"scrapeSessions": scrapeSessions,
+		"dumpRunning":    h.dumpRunning.Load(),
+		"fullTextSearch": database.FullTextSearch,
 		"timestamp":      time.Now().Format(time.RFC3339),
 	}
 
@@ -80,3 +88,116 @@ func (h *Handlers) HandleAdminInfo(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(clientInfo)
 }
+
+// HandleAdminRuns возвращает список последних запусков скрейпера
+func (h *Handlers) HandleAdminRuns(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	runs, err := h.repo.GetScrapeRuns(limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := []models.ScrapeRunResponse{}
+	for _, run := range runs {
+		response = append(response, models.NewScrapeRunResponse(run))
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleAdminRunErrors возвращает отчет об ошибках парсинга за запуск
+func (h *Handlers) HandleAdminRunErrors(w http.ResponseWriter, r *http.Request) {
+	runID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid run id", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.repo.GetScrapeRun(runID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if run == nil {
+		http.Error(w, "Run not found", http.StatusNotFound)
+		return
+	}
+
+	parseErrors, err := h.repo.GetParseErrors(runID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report := models.ParseErrorReportResponse{
+		Run:          models.NewScrapeRunResponse(*run),
+		CellErrors:   []models.ParseErrorResponse{},
+		RejectedRows: []models.ParseErrorResponse{},
+	}
+	for _, pe := range parseErrors {
+		item := models.ParseErrorResponse{
+			Row:     pe.Row,
+			Column:  pe.Column,
+			RawText: pe.RawText,
+			Reason:  pe.Reason,
+		}
+		if pe.Kind == models.ParseErrorRow {
+			report.RejectedRows = append(report.RejectedRows, item)
+		} else {
+			report.CellErrors = append(report.CellErrors, item)
+		}
+	}
+
+	respondJSON(w, report)
+}
+
+// HandleAdminDump запускает выгрузку истории в Parquet/NDJSON в каталог DUMP_DIR.
+// Полная выгрузка может идти дольше таймаута ответа, поэтому она выполняется в фоне;
+// результат — manifest.json в каталоге выгрузки.
+func (h *Handlers) HandleAdminDump(w http.ResponseWriter, r *http.Request) {
+	formats, err := dump.ParseFormats(r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts := dump.Options{
+		Dir:         h.config.DumpDir,
+		Formats:     formats,
+		Incremental: r.URL.Query().Get("incremental") == "true",
+	}
+
+	if !h.dumpRunning.CompareAndSwap(false, true) {
+		http.Error(w, "dump already running", http.StatusConflict)
+		return
+	}
+
+	go func() {
+		defer h.dumpRunning.Store(false)
+		startTime := time.Now()
+		manifest, err := dump.Run(h.repo, opts)
+		if err != nil {
+			log.Printf("❌ Dump error: %v", err)
+			return
+		}
+		log.Printf("✅ Dump completed: %d files, %d rows (duration: %s)",
+			len(manifest.Files), manifest.TotalRows, time.Since(startTime))
+	}()
+
+	response := map[string]interface{}{
+		"status":      "started",
+		"message":     "Dump started in background, see " + dump.ManifestFile + " in the dump directory",
+		"dir":         opts.Dir,
+		"formats":     opts.Formats,
+		"incremental": opts.Incremental,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
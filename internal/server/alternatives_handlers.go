@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"etf-scraper/internal/analytics"
+	"etf-scraper/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// HandleGetAlternatives возвращает фонды с тем же целевым индексом (с учетом синонимов),
+// классом активов и валютой, ранжированные по статусу торгов, TER и СЧА
+func (h *Handlers) HandleGetAlternatives(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.ToUpper(mux.Vars(r)["ticker"])
+
+	response, err := analytics.Alternatives(h.repo, ticker)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if response == nil {
+		http.Error(w, "ETF not found", http.StatusNotFound)
+		return
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleAdminListIndexAliases возвращает таблицу синонимов индексов
+func (h *Handlers) HandleAdminListIndexAliases(w http.ResponseWriter, r *http.Request) {
+	aliases, err := h.repo.GetIndexAliases()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := []models.IndexAliasResponse{}
+	for _, a := range aliases {
+		response = append(response, toIndexAliasResponse(a))
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleAdminSaveIndexAlias добавляет синоним индекса или заменяет существующий
+func (h *Handlers) HandleAdminSaveIndexAlias(w http.ResponseWriter, r *http.Request) {
+	var req models.IndexAliasRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	alias := models.IndexAlias{
+		AliasKey:  analytics.IndexKey(req.Alias),
+		Alias:     strings.TrimSpace(req.Alias),
+		Canonical: strings.TrimSpace(req.Canonical),
+		CreatedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if alias.AliasKey == "" || analytics.IndexKey(alias.Canonical) == "" {
+		http.Error(w, "alias and canonical are required", http.StatusBadRequest)
+		return
+	}
+	if alias.AliasKey == analytics.IndexKey(alias.Canonical) {
+		http.Error(w, "alias already normalizes to canonical", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.SaveIndexAlias(alias); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("📇 Index alias saved: %q → %q", alias.Alias, alias.Canonical)
+
+	respondJSON(w, toIndexAliasResponse(alias))
+}
+
+// HandleAdminDeleteIndexAlias удаляет синоним; {alias} принимает любое написание
+func (h *Handlers) HandleAdminDeleteIndexAlias(w http.ResponseWriter, r *http.Request) {
+	key := analytics.IndexKey(mux.Vars(r)["alias"])
+
+	found, err := h.repo.DeleteIndexAlias(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Alias not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("🗑️ Index alias deleted: %q", key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toIndexAliasResponse(a models.IndexAlias) models.IndexAliasResponse {
+	return models.IndexAliasResponse{
+		Alias:     a.Alias,
+		Key:       a.AliasKey,
+		Canonical: a.Canonical,
+		CreatedAt: a.CreatedAt,
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"etf-scraper/internal/analytics"
+	"etf-scraper/internal/database"
+)
+
+// HandleAnalyticsFlows возвращает оценку чистых потоков средств по фондам,
+// управляющим компаниям и классам активов за период from..to
+func (h *Handlers) HandleAnalyticsFlows(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	report, err := analytics.Flows(h.repo, params.Get("from"), params.Get("to"))
+	if errors.Is(err, database.ErrUnknownSession) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, report)
+}
+
+// HandleGetAssetClassSummary возвращает показатели классов активов за последний сеанс
+// и их динамику по всем сеансам (series=false отключает динамику)
+func (h *Handlers) HandleGetAssetClassSummary(w http.ResponseWriter, r *http.Request) {
+	withSeries := r.URL.Query().Get("series") != "false"
+
+	response, err := analytics.AssetClasses(h.repo, withSeries)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleAnalyticsRisk возвращает волатильность, максимальную просадку и коэффициент
+// Шарпа фондов (tickers=A,B — выборка, riskFree — безрисковая ставка, % годовых)
+func (h *Handlers) HandleAnalyticsRisk(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	tickers := normalizeTickers(strings.Split(params.Get("tickers"), ","))
+
+	riskFree := h.config.RiskFreeRate
+	if v := params.Get("riskFree"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(rate) || math.IsInf(rate, 0) {
+			http.Error(w, "riskFree must be a number", http.StatusBadRequest)
+			return
+		}
+		riskFree = rate
+	}
+
+	response, err := analytics.Risk(h.repo, tickers, riskFree)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleAnalyticsCorrelation возвращает матрицу корреляций годовых доходностей
+// фондов tickers=A,B,C
+func (h *Handlers) HandleAnalyticsCorrelation(w http.ResponseWriter, r *http.Request) {
+	tickers := normalizeTickers(strings.Split(r.URL.Query().Get("tickers"), ","))
+	if len(tickers) < 2 {
+		http.Error(w, "at least two tickers required", http.StatusBadRequest)
+		return
+	}
+	if len(tickers) > maxToolTickers {
+		http.Error(w, "too many tickers (max "+strconv.Itoa(maxToolTickers)+")", http.StatusBadRequest)
+		return
+	}
+
+	response, err := analytics.Correlation(h.repo, tickers)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, response)
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"etf-scraper/internal/benchmark"
+	"etf-scraper/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// HandleGetBenchmarks возвращает бенчмарки с охватом значений и привязанными фондами
+func (h *Handlers) HandleGetBenchmarks(w http.ResponseWriter, r *http.Request) {
+	response, err := benchmark.List(h.repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleGetBenchmarkLeague возвращает таблицу фондов бенчмарка по отклонению
+// от индекса и ошибке слежения за годы from..to
+func (h *Handlers) HandleGetBenchmarkLeague(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := yearRange(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := benchmark.League(h.repo, benchmark.NormalizeCode(mux.Vars(r)["code"]), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if response == nil {
+		http.Error(w, "Benchmark not found", http.StatusNotFound)
+		return
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleGetETFTracking сравнивает доходность фонда с его бенчмарком по годам
+// и за период from..to
+func (h *Handlers) HandleGetETFTracking(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := yearRange(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := benchmark.FundTracking(h.repo, strings.ToUpper(mux.Vars(r)["ticker"]), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if response == nil {
+		http.Error(w, "ETF not found", http.StatusNotFound)
+		return
+	}
+	if response.Benchmark == "" {
+		http.Error(w, "No benchmark linked to ETF", http.StatusNotFound)
+		return
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleAdminSaveBenchmark создает бенчмарк или меняет его название
+func (h *Handlers) HandleAdminSaveBenchmark(w http.ResponseWriter, r *http.Request) {
+	var req models.BenchmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	b := models.Benchmark{
+		Code:      benchmark.NormalizeCode(mux.Vars(r)["code"]),
+		Name:      strings.TrimSpace(req.Name),
+		CreatedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if b.Code == "" || b.Name == "" {
+		http.Error(w, "code and name are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.SaveBenchmark(b); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("📏 Benchmark saved: %s (%s)", b.Code, b.Name)
+	h.respondBenchmark(w, b.Code)
+}
+
+// HandleAdminDeleteBenchmark удаляет бенчмарк со значениями и привязками фондов
+func (h *Handlers) HandleAdminDeleteBenchmark(w http.ResponseWriter, r *http.Request) {
+	code := benchmark.NormalizeCode(mux.Vars(r)["code"])
+
+	found, err := h.repo.DeleteBenchmark(code)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Benchmark not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("🗑️ Benchmark deleted: %s", code)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleAdminImportBenchmarkLevels загружает значения индекса из тела запроса:
+// JSON-массив {date, level} (Content-Type: application/json) или CSV «дата, значение»
+func (h *Handlers) HandleAdminImportBenchmarkLevels(w http.ResponseWriter, r *http.Request) {
+	code := benchmark.NormalizeCode(mux.Vars(r)["code"])
+	b, err := h.repo.GetBenchmark(code)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if b == nil {
+		http.Error(w, "Benchmark not found", http.StatusNotFound)
+		return
+	}
+
+	var levels []models.BenchmarkLevel
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		levels, err = benchmark.ParseJSON(r.Body)
+	} else {
+		levels, err = benchmark.ParseCSV(r.Body)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.SaveBenchmarkLevels(code, levels); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("📏 Benchmark %s: %d levels imported (%s - %s)", code, len(levels), levels[0].Date, levels[len(levels)-1].Date)
+	h.respondBenchmark(w, code)
+}
+
+// HandleAdminSetFundBenchmark привязывает фонд к бенчмарку вместо сопоставления
+// по целевому индексу
+func (h *Handlers) HandleAdminSetFundBenchmark(w http.ResponseWriter, r *http.Request) {
+	var req models.FundBenchmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	link := models.FundBenchmark{
+		Ticker:    strings.ToUpper(strings.TrimSpace(mux.Vars(r)["ticker"])),
+		Code:      benchmark.NormalizeCode(req.Benchmark),
+		CreatedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if link.Code == "" {
+		http.Error(w, "benchmark is required", http.StatusBadRequest)
+		return
+	}
+
+	b, err := h.repo.GetBenchmark(link.Code)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if b == nil {
+		http.Error(w, "Benchmark not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.repo.SetFundBenchmark(link); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("📏 %s linked to benchmark %s", link.Ticker, link.Code)
+	h.respondBenchmark(w, link.Code)
+}
+
+// HandleAdminDeleteFundBenchmark удаляет привязку фонда; фонд снова сопоставляется
+// с бенчмарком по целевому индексу
+func (h *Handlers) HandleAdminDeleteFundBenchmark(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.ToUpper(strings.TrimSpace(mux.Vars(r)["ticker"]))
+
+	found, err := h.repo.DeleteFundBenchmark(ticker)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Link not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("🗑️ Benchmark link deleted: %s", ticker)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondBenchmark отправляет бенчмарк в виде BenchmarkResponse
+func (h *Handlers) respondBenchmark(w http.ResponseWriter, code string) {
+	list, err := benchmark.List(h.repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, b := range list {
+		if b.Code == code {
+			respondJSON(w, b)
+			return
+		}
+	}
+	http.Error(w, "Benchmark not found", http.StatusNotFound)
+}
+
+// yearRange разбирает параметры from и to (годы, пусто — без ограничения);
+// при ошибке ответ уже отправлен
+func yearRange(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	var years [2]int
+	for i, name := range []string{"from", "to"} {
+		v := r.URL.Query().Get(name)
+		if v == "" {
+			continue
+		}
+		year, err := strconv.Atoi(v)
+		if err != nil || year < 1900 || year > 2100 {
+			http.Error(w, name+" must be a year", http.StatusBadRequest)
+			return 0, 0, false
+		}
+		years[i] = year
+	}
+	if years[0] != 0 && years[1] != 0 && years[0] > years[1] {
+		http.Error(w, "from must not be after to", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return years[0], years[1], true
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"etf-scraper/internal/analytics"
+
+	"github.com/gorilla/mux"
+)
+
+// HandleGetCompanies возвращает агрегаты по управляющим компаниям за последний сеанс
+func (h *Handlers) HandleGetCompanies(w http.ResponseWriter, r *http.Request) {
+	response, err := analytics.Companies(h.repo, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleGetCompanyRanking возвращает рейтинг управляющих компаний
+// (by=nav|funds|ter, limit)
+func (h *Handlers) HandleGetCompanyRanking(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	by := params.Get("by")
+	if by == "" {
+		by = analytics.RankByNAV
+	}
+
+	response, err := analytics.Companies(h.repo, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !analytics.Rank(response.Companies, by) {
+		http.Error(w, "by must be one of: "+strings.Join(analytics.Rankings, ", "), http.StatusBadRequest)
+		return
+	}
+	response.RankedBy = by
+
+	if l, err := strconv.Atoi(params.Get("limit")); err == nil && l > 0 && l < len(response.Companies) {
+		response.Companies = response.Companies[:l]
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleGetCompany возвращает управляющую компанию с фондами и динамикой доли рынка;
+// {name} принимает любое написание названия
+func (h *Handlers) HandleGetCompany(w http.ResponseWriter, r *http.Request) {
+	detail, err := analytics.Company(h.repo, mux.Vars(r)["name"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if detail == nil {
+		http.Error(w, "Management company not found", http.StatusNotFound)
+		return
+	}
+
+	respondJSON(w, detail)
+}
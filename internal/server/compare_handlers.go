@@ -0,0 +1,151 @@
+package server
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"etf-scraper/internal/models"
+)
+
+// HandleCompare сравнивает фонды бок о бок (tickers=A,B,C): все атрибуты, разницы
+// относительно первого тикера и история TER и СЧА на общих сеансах.
+// Неизвестные тикеры перечисляются в missing и не прерывают запрос.
+func (h *Handlers) HandleCompare(w http.ResponseWriter, r *http.Request) {
+	tickers := normalizeTickers(strings.Split(r.URL.Query().Get("tickers"), ","))
+	if len(tickers) == 0 {
+		http.Error(w, "tickers required", http.StatusBadRequest)
+		return
+	}
+	if len(tickers) > maxToolTickers {
+		http.Error(w, "too many tickers (max "+strconv.Itoa(maxToolTickers)+")", http.StatusBadRequest)
+		return
+	}
+
+	response := models.CompareResponse{
+		Funds:       []models.ETFResponse{},
+		Differences: []models.CompareDifference{},
+		History: models.CompareHistory{
+			Dates: []string{},
+			TER:   make(map[string][]*float64),
+			NAV:   make(map[string][]*float64),
+		},
+		Missing: []models.MissingTicker{},
+	}
+
+	seen := make(map[string]bool)
+	for _, ticker := range tickers {
+		if seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+
+		etf, err := h.latestETF(ticker)
+		if err == sql.ErrNoRows {
+			response.Missing = append(response.Missing, models.MissingTicker{Ticker: ticker, Reason: "not found"})
+			continue
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response.Funds = append(response.Funds, etf)
+	}
+
+	if !h.convertETFs(w, r, response.Funds) {
+		return
+	}
+
+	if len(response.Funds) > 0 {
+		base := response.Funds[0]
+		response.Base = base.Ticker
+		for _, etf := range response.Funds[1:] {
+			response.Differences = append(response.Differences, compareDifference(base, etf))
+		}
+
+		if err := h.fillCompareHistory(&response); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	respondJSON(w, response)
+}
+
+// fillCompareHistory выравнивает историю TER и СЧА фондов по сеансам, где есть все фонды
+func (h *Handlers) fillCompareHistory(response *models.CompareResponse) error {
+	var sessions []string // сеансы первого фонда по возрастанию даты
+	histories := make(map[string]map[string]models.ETFData, len(response.Funds))
+	counts := make(map[string]int)
+
+	for i, etf := range response.Funds {
+		history, err := h.repo.GetTickerHistory(etf.Ticker)
+		if err != nil {
+			return err
+		}
+		bySession := make(map[string]models.ETFData, len(history))
+		for _, rec := range history {
+			if _, ok := bySession[rec.DateScraped]; ok {
+				continue
+			}
+			bySession[rec.DateScraped] = rec
+			counts[rec.DateScraped]++
+			if i == 0 {
+				sessions = append(sessions, rec.DateScraped)
+			}
+		}
+		histories[etf.Ticker] = bySession
+	}
+
+	for _, date := range sessions {
+		if counts[date] != len(response.Funds) {
+			continue
+		}
+		response.History.Dates = append(response.History.Dates, date)
+		for _, etf := range response.Funds {
+			rec := histories[etf.Ticker][date]
+			response.History.TER[etf.Ticker] = append(response.History.TER[etf.Ticker], rec.TERPercent)
+			response.History.NAV[etf.Ticker] = append(response.History.NAV[etf.Ticker], rec.NAVMillionRub)
+		}
+	}
+
+	return nil
+}
+
+// compareDifference считает разницу показателей фонда и базового фонда
+func compareDifference(base, etf models.ETFResponse) models.CompareDifference {
+	d := models.CompareDifference{
+		Ticker:        etf.Ticker,
+		TERPercent:    subtract(etf.TERPercent, base.TERPercent),
+		NAVMillionRub: subtract(etf.NAVMillionRub, base.NAVMillionRub),
+		Returns: models.ReturnsByYear{
+			PriceChange6M:   subtract(etf.PriceChange6M, base.PriceChange6M),
+			PriceChange2024: subtract(etf.PriceChange2024, base.PriceChange2024),
+			PriceChange2023: subtract(etf.PriceChange2023, base.PriceChange2023),
+			PriceChange2022: subtract(etf.PriceChange2022, base.PriceChange2022),
+			PriceChange2021: subtract(etf.PriceChange2021, base.PriceChange2021),
+			PriceChange2020: subtract(etf.PriceChange2020, base.PriceChange2020),
+		},
+	}
+	if d.NAVMillionRub != nil && *base.NAVMillionRub > 0 {
+		p := math.Round(*d.NAVMillionRub / *base.NAVMillionRub * 10000) / 100
+		d.NAVPercent = &p
+	}
+	return d
+}
+
+// subtract возвращает a - b; nil, если одно из значений неизвестно
+func subtract(a, b *float64) *float64 {
+	if a == nil || b == nil {
+		return nil
+	}
+	v := roundTo(*a - *b)
+	return &v
+}
+
+// roundTo убирает погрешность вычитания чисел с плавающей точкой
+func roundTo(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"etf-scraper/internal/export"
+	"etf-scraper/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// HandleExportETFs выгружает список ETF сеанса asOf (фильтры и сортировка как в /api/etfs)
+func (h *Handlers) HandleExportETFs(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.asOfSession(w, r)
+	if !ok {
+		return
+	}
+	filter, err := etfFilterFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := h.repo.ListETFs(session, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeExport(w, r, "etfs_"+time.Now().Format("2006-01-02"), data)
+}
+
+// HandleExportHistory выгружает историю одного тикера
+func (h *Handlers) HandleExportHistory(w http.ResponseWriter, r *http.Request) {
+	ticker := mux.Vars(r)["ticker"]
+
+	data, err := h.repo.GetTickerHistory(ticker)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(data) == 0 {
+		http.Error(w, "ETF not found", http.StatusNotFound)
+		return
+	}
+
+	h.writeExport(w, r, ticker+"_history", data)
+}
+
+// writeExport разбирает параметры выгрузки и отдает файл
+func (h *Handlers) writeExport(w http.ResponseWriter, r *http.Request, name string, data []models.ETFData) {
+	params := r.URL.Query()
+
+	format := params.Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if format != export.FormatCSV && format != export.FormatXLSX {
+		http.Error(w, "unknown format: "+format, http.StatusBadRequest)
+		return
+	}
+
+	opts, err := export.NewOptions(params.Get("columns"), params.Get("lang"), params.Get("sep"), params.Get("bom") != "false")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+
+	if err := export.Write(w, format, data, opts); err != nil {
+		log.Printf("Ошибка выгрузки %s: %v", name, err)
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"etf-scraper/internal/config"
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+func TestExportETFsAsOf(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.DBPath = t.TempDir() + "/export.db"
+	db, err := database.NewDatabase(cfg.DBPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := database.NewRepository(db)
+
+	nav := func(v float64) *float64 { return &v }
+	for _, snapshot := range [][]models.ETFData{
+		{{DateScraped: "2024-03-15 00:00:00", Ticker: "TMOS", NAVMillionRub: nav(100)}},
+		{
+			{DateScraped: "2024-05-01 00:00:00", Ticker: "TMOS", NAVMillionRub: nav(150)},
+			{DateScraped: "2024-05-01 00:00:00", Ticker: "SBMX", NAVMillionRub: nav(200)},
+		},
+	} {
+		if err := repo.SaveETFs(snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewServer(cfg, db, repo)
+
+	tests := []struct {
+		query string
+		code  int
+		want  string
+	}{
+		{"", http.StatusOK, "2024-05-01 00:00:00 SBMX 200|2024-05-01 00:00:00 TMOS 150"},
+		{"&asOf=2024-04-30", http.StatusOK, "2024-03-15 00:00:00 TMOS 100"},
+		{"&asOf=2024-03-15%2000:00:00", http.StatusOK, "2024-03-15 00:00:00 TMOS 100"},
+		{"&asOf=2024-01-01", http.StatusBadRequest, ""},
+		{"&asOf=garbage", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			url := "/api/export/etfs?columns=dateScraped,ticker,navMillionRub&bom=false" + tt.query
+			s.router.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+			if rec.Code != tt.code {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.code, rec.Body)
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			records, err := csv.NewReader(rec.Body).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rows []string
+			for _, r := range records[1:] {
+				rows = append(rows, strings.Join(r, " "))
+			}
+			if got := strings.Join(rows, "|"); got != tt.want {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
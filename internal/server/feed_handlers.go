@@ -0,0 +1,92 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"etf-scraper/internal/analytics"
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/feed"
+	"etf-scraper/internal/models"
+)
+
+// HandleFeedAtom отдает ленту событий фондов в формате Atom
+func (h *Handlers) HandleFeedAtom(w http.ResponseWriter, r *http.Request) {
+	h.writeFeed(w, r, "application/atom+xml; charset=utf-8", feed.WriteAtom)
+}
+
+// HandleFeedRSS отдает ленту событий фондов в формате RSS 2.0
+func (h *Handlers) HandleFeedRSS(w http.ResponseWriter, r *http.Request) {
+	h.writeFeed(w, r, "application/rss+xml; charset=utf-8", feed.WriteRSS)
+}
+
+// writeFeed выбирает события по фильтрам ticker, managementCo, kind и limit и пишет ленту
+func (h *Handlers) writeFeed(w http.ResponseWriter, r *http.Request, contentType string,
+	write func(io.Writer, feed.Meta, []models.Event) error) {
+	params := r.URL.Query()
+
+	limit := 50
+	if l := params.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 500 {
+			limit = parsed
+		}
+	}
+
+	filter := database.EventFilter{
+		Ticker: params.Get("ticker"),
+		Kind:   params.Get("kind"),
+		Limit:  limit,
+	}
+	managementCo := params.Get("managementCo")
+
+	events, err := analytics.FilterEvents(h.repo, filter, managementCo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	title := "ETF Scraper: события фондов"
+	switch {
+	case filter.Ticker != "":
+		title += " — " + filter.Ticker
+	case managementCo != "":
+		title += " — " + managementCo
+	}
+
+	// Фильтры в идентификаторе ленты: компания — по ключу NormalizeCompany,
+	// чтобы разные написания одной УК давали ту же ленту
+	idFilter := url.Values{}
+	for key, value := range map[string]string{
+		"ticker":       filter.Ticker,
+		"managementCo": analytics.NormalizeCompany(managementCo),
+		"kind":         filter.Kind,
+	} {
+		if value != "" {
+			idFilter.Set(key, value)
+		}
+	}
+
+	base := baseURL(r)
+	meta := feed.Meta{
+		Title:   title,
+		BaseURL: base,
+		SelfURL: base + r.URL.RequestURI(),
+		Filter:  idFilter,
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if err := write(w, meta, events); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// baseURL восстанавливает адрес сервера из запроса
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"etf-scraper/internal/dateparse"
+	"etf-scraper/internal/fxrates"
+	"etf-scraper/internal/models"
+)
+
+// HandleGetFXRates возвращает курсы валют к рублю (currency, from, to — необязательные фильтры)
+func (h *Handlers) HandleGetFXRates(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	currency := ""
+	if v := params.Get("currency"); v != "" {
+		currency = fxrates.NormalizeCurrency(v)
+	}
+
+	var bounds [2]string
+	for i, name := range []string{"from", "to"} {
+		v := params.Get(name)
+		if v == "" {
+			continue
+		}
+		d, err := dateparse.Parse(v)
+		if err != nil || d.Precision != dateparse.PrecisionDay {
+			http.Error(w, name+" must be a date (YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		bounds[i] = d.ISO()
+	}
+
+	rates, err := h.repo.GetFXRates(currency, bounds[0], bounds[1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if rates == nil {
+		rates = []models.FXRate{}
+	}
+
+	respondJSON(w, rates)
+}
+
+// HandleAdminImportFXRates загружает курсы валют из тела запроса: XML Банка России
+// (ежедневные курсы или динамика курса) или CSV «дата, валюта, курс[, номинал]»
+func (h *Handlers) HandleAdminImportFXRates(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rates, err := fxrates.Parse(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.SaveFXRates(rates); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := models.FXImportResponse{
+		Imported:   len(rates),
+		Currencies: make(map[string]models.FXRateCoverage),
+	}
+	for _, rate := range rates {
+		c := response.Currencies[rate.Currency]
+		if c.Count == 0 {
+			c.FirstDate = rate.Date
+		}
+		c.Count++
+		c.LastDate = rate.Date
+		response.Currencies[rate.Currency] = c
+	}
+
+	log.Printf("💱 FX rates imported: %d", len(rates))
+	respondJSON(w, response)
+}
+
+// convertETFs выражает СЧА и доходности фондов в валюте из параметра currency
+// (поле converted); без параметра ничего не делает. При ошибке ответ уже отправлен.
+func (h *Handlers) convertETFs(w http.ResponseWriter, r *http.Request, etfs []models.ETFResponse) bool {
+	v := r.URL.Query().Get("currency")
+	if v == "" {
+		return true
+	}
+
+	currency := fxrates.NormalizeCurrency(v)
+	if !fxrates.IsSupported(currency) {
+		http.Error(w, "currency must be one of: "+strings.Join(fxrates.Supported, ", "), http.StatusBadRequest)
+		return false
+	}
+
+	converter, err := fxrates.NewConverter(h.repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	for i := range etfs {
+		etfs[i].Converted = converter.Convert(etfs[i], currency)
+	}
+	return true
+}
@@ -3,13 +3,20 @@ package server
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"etf-scraper/internal/config"
 	"etf-scraper/internal/database"
+	"etf-scraper/internal/diff"
 	"etf-scraper/internal/models"
+	"etf-scraper/internal/search"
 
 	"github.com/gorilla/mux"
 )
@@ -19,6 +26,9 @@ type Handlers struct {
 	config *config.Config
 	db     *database.Database
 	repo   *database.Repository
+
+	// dumpRunning не дает запустить вторую выгрузку в тот же каталог
+	dumpRunning atomic.Bool
 }
 
 // NewHandlers создает новый набор обработчиков
@@ -32,36 +42,23 @@ func NewHandlers(cfg *config.Config, db *database.Database, repo *database.Repos
 
 // HandleGetAllETFs возвращает все ETF с возможностью фильтрации и сортировки
 func (h *Handlers) HandleGetAllETFs(w http.ResponseWriter, r *http.Request) {
-	queryParams := r.URL.Query()
-	assetClass := queryParams.Get("assetClass")
-	sortBy := queryParams.Get("sortBy")
-	order := queryParams.Get("order")
-
-	if sortBy == "" {
-		sortBy = "nav_million_rub"
+	session, ok := h.asOfSession(w, r)
+	if !ok {
+		return
 	}
-	if order == "" {
-		order = "DESC"
+	filter, err := etfFilterFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	clause, args := filter.Clause()
 
-	query := `
-		SELECT 
-			id, date_scraped, ticker, trade_status, management_company, 
-			asset_class, ter_percent, ter_direction, fund_name, management_style, 
-			target_index, currency, start_date, info_icon, price_change_6m, 
-			price_change_2024, price_change_2023, price_change_2022, 
-			price_change_2021, price_change_2020, nav_million_rub, last_update_date
+	query := `SELECT ` + database.ETFColumns + `
 		FROM etf_data 
-		WHERE date_scraped = (SELECT MAX(date_scraped) FROM etf_data)
-	`
+		WHERE date_scraped = ?
+	` + clause
 
-	if assetClass != "" && assetClass != "Все" {
-		query += fmt.Sprintf(" AND asset_class = '%s'", assetClass)
-	}
-
-	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, order)
-
-	rows, err := h.db.DB.Query(query)
+	rows, err := h.db.DB.Query(query, append([]interface{}{session}, args...)...)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -70,15 +67,7 @@ func (h *Handlers) HandleGetAllETFs(w http.ResponseWriter, r *http.Request) {
 
 	etfs := []models.ETFResponse{}
 	for rows.Next() {
-		var etf models.ETFResponse
-		err := rows.Scan(
-			&etf.ID, &etf.DateScraped, &etf.Ticker, &etf.TradeStatus,
-			&etf.ManagementCo, &etf.AssetClass, &etf.TERPercent, &etf.TERDirection,
-			&etf.FundName, &etf.ManagementStyle, &etf.TargetIndex, &etf.Currency,
-			&etf.StartDate, &etf.InfoIcon, &etf.PriceChange6M, &etf.PriceChange2024,
-			&etf.PriceChange2023, &etf.PriceChange2022, &etf.PriceChange2021,
-			&etf.PriceChange2020, &etf.NAVMillionRub, &etf.LastUpdateDate,
-		)
+		etf, err := scanETFResponse(rows)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -86,6 +75,10 @@ func (h *Handlers) HandleGetAllETFs(w http.ResponseWriter, r *http.Request) {
 		etfs = append(etfs, etf)
 	}
 
+	if !h.convertETFs(w, r, etfs) {
+		return
+	}
+
 	respondJSON(w, etfs)
 }
 
@@ -94,28 +87,7 @@ func (h *Handlers) HandleGetETFByTicker(w http.ResponseWriter, r *http.Request)
 	vars := mux.Vars(r)
 	ticker := vars["ticker"]
 
-	query := `
-		SELECT 
-			id, date_scraped, ticker, trade_status, management_company, 
-			asset_class, ter_percent, ter_direction, fund_name, management_style, 
-			target_index, currency, start_date, info_icon, price_change_6m, 
-			price_change_2024, price_change_2023, price_change_2022, 
-			price_change_2021, price_change_2020, nav_million_rub, last_update_date
-		FROM etf_data 
-		WHERE ticker = ? 
-		ORDER BY date_scraped DESC 
-		LIMIT 1
-	`
-
-	var etf models.ETFResponse
-	err := h.db.DB.QueryRow(query, ticker).Scan(
-		&etf.ID, &etf.DateScraped, &etf.Ticker, &etf.TradeStatus,
-		&etf.ManagementCo, &etf.AssetClass, &etf.TERPercent, &etf.TERDirection,
-		&etf.FundName, &etf.ManagementStyle, &etf.TargetIndex, &etf.Currency,
-		&etf.StartDate, &etf.InfoIcon, &etf.PriceChange6M, &etf.PriceChange2024,
-		&etf.PriceChange2023, &etf.PriceChange2022, &etf.PriceChange2021,
-		&etf.PriceChange2020, &etf.NAVMillionRub, &etf.LastUpdateDate,
-	)
+	etf, err := h.latestETF(ticker)
 
 	if err == sql.ErrNoRows {
 		http.Error(w, "ETF not found", http.StatusNotFound)
@@ -126,26 +98,48 @@ func (h *Handlers) HandleGetETFByTicker(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	respondJSON(w, etf)
+	etfs := []models.ETFResponse{etf}
+	if !h.convertETFs(w, r, etfs) {
+		return
+	}
+
+	respondJSON(w, etfs[0])
+}
+
+// latestETF возвращает последнюю запись тикера; sql.ErrNoRows, если тикера нет
+func (h *Handlers) latestETF(ticker string) (models.ETFResponse, error) {
+	query := `SELECT ` + database.ETFColumns + `
+		FROM etf_data 
+		WHERE ticker = ? 
+		ORDER BY date_scraped DESC 
+		LIMIT 1
+	`
+
+	return scanETFResponse(h.db.DB.QueryRow(query, ticker))
 }
 
 // HandleGetStats возвращает статистику по ETF
 func (h *Handlers) HandleGetStats(w http.ResponseWriter, r *http.Request) {
-	var stats models.StatsResponse
+	session, ok := h.asOfSession(w, r)
+	if !ok {
+		return
+	}
+	stats := models.StatsResponse{DateScraped: session}
 
-	err := h.db.DB.QueryRow("SELECT COUNT(*) FROM etf_data").Scan(&stats.TotalRecords)
+	// Накопленные показатели — по всем сеансам до выбранного включительно
+	err := h.db.DB.QueryRow("SELECT COUNT(*) FROM etf_data WHERE date_scraped <= ?", session).Scan(&stats.TotalRecords)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = h.db.DB.QueryRow("SELECT COUNT(DISTINCT ticker) FROM etf_data").Scan(&stats.UniqueTickers)
+	err = h.db.DB.QueryRow("SELECT COUNT(DISTINCT ticker) FROM etf_data WHERE date_scraped <= ?", session).Scan(&stats.UniqueTickers)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = h.db.DB.QueryRow("SELECT COUNT(DISTINCT date_scraped) FROM etf_data").Scan(&stats.ScrapeSessions)
+	err = h.db.DB.QueryRow("SELECT COUNT(DISTINCT date_scraped) FROM etf_data WHERE date_scraped <= ?", session).Scan(&stats.ScrapeSessions)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -156,8 +150,8 @@ func (h *Handlers) HandleGetStats(w http.ResponseWriter, r *http.Request) {
 			COALESCE(SUM(nav_million_rub), 0),
 			COALESCE(AVG(ter_percent), 0)
 		FROM etf_data 
-		WHERE date_scraped = (SELECT MAX(date_scraped) FROM etf_data)
-	`).Scan(&stats.TotalNAV, &stats.AvgTER)
+		WHERE date_scraped = ?
+	`, session).Scan(&stats.TotalNAV, &stats.AvgTER)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -167,9 +161,9 @@ func (h *Handlers) HandleGetStats(w http.ResponseWriter, r *http.Request) {
 	err = h.db.DB.QueryRow(`
 		SELECT last_update_date 
 		FROM etf_data 
-		WHERE date_scraped = (SELECT MAX(date_scraped) FROM etf_data)
+		WHERE date_scraped = ?
 		LIMIT 1
-	`).Scan(&rawDate)
+	`, session).Scan(&rawDate)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -186,14 +180,19 @@ func (h *Handlers) HandleGetStats(w http.ResponseWriter, r *http.Request) {
 
 // HandleGetAssetClasses возвращает список классов активов
 func (h *Handlers) HandleGetAssetClasses(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.asOfSession(w, r)
+	if !ok {
+		return
+	}
+
 	query := `
 		SELECT DISTINCT asset_class 
 		FROM etf_data 
-		WHERE date_scraped = (SELECT MAX(date_scraped) FROM etf_data)
+		WHERE date_scraped = ?
 		ORDER BY asset_class
 	`
 
-	rows, err := h.db.DB.Query(query)
+	rows, err := h.db.DB.Query(query, session)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -222,21 +221,20 @@ func (h *Handlers) HandleGetTopByNAV(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	query := `
-		SELECT 
-			id, date_scraped, ticker, trade_status, management_company, 
-			asset_class, ter_percent, ter_direction, fund_name, management_style, 
-			target_index, currency, start_date, info_icon, price_change_6m, 
-			price_change_2024, price_change_2023, price_change_2022, 
-			price_change_2021, price_change_2020, nav_million_rub, last_update_date
+	session, ok := h.asOfSession(w, r)
+	if !ok {
+		return
+	}
+
+	query := `SELECT ` + database.ETFColumns + `
 		FROM etf_data 
-		WHERE date_scraped = (SELECT MAX(date_scraped) FROM etf_data)
+		WHERE date_scraped = ?
 		AND nav_million_rub IS NOT NULL
 		ORDER BY nav_million_rub DESC 
 		LIMIT ?
 	`
 
-	rows, err := h.db.DB.Query(query, limit)
+	rows, err := h.db.DB.Query(query, session, limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -245,25 +243,22 @@ func (h *Handlers) HandleGetTopByNAV(w http.ResponseWriter, r *http.Request) {
 
 	etfs := []models.ETFResponse{}
 	for rows.Next() {
-		var etf models.ETFResponse
-		err := rows.Scan(
-			&etf.ID, &etf.DateScraped, &etf.Ticker, &etf.TradeStatus,
-			&etf.ManagementCo, &etf.AssetClass, &etf.TERPercent, &etf.TERDirection,
-			&etf.FundName, &etf.ManagementStyle, &etf.TargetIndex, &etf.Currency,
-			&etf.StartDate, &etf.InfoIcon, &etf.PriceChange6M, &etf.PriceChange2024,
-			&etf.PriceChange2023, &etf.PriceChange2022, &etf.PriceChange2021,
-			&etf.PriceChange2020, &etf.NAVMillionRub, &etf.LastUpdateDate,
-		)
+		etf, err := scanETFResponse(rows)
 		if err != nil {
 			continue
 		}
 		etfs = append(etfs, etf)
 	}
 
+	if !h.convertETFs(w, r, etfs) {
+		return
+	}
+
 	respondJSON(w, etfs)
 }
 
-// HandleSearch выполняет поиск ETF
+// HandleSearch выполняет полнотекстовый поиск ETF по тикеру, названию, УК и целевому
+// индексу: префиксы слов, транслитерация, опечатки; результаты — по релевантности
 func (h *Handlers) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	searchTerm := r.URL.Query().Get("q")
 	if searchTerm == "" {
@@ -271,49 +266,178 @@ func (h *Handlers) HandleSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := `
-		SELECT 
-			id, date_scraped, ticker, trade_status, management_company, 
-			asset_class, ter_percent, ter_direction, fund_name, management_style, 
-			target_index, currency, start_date, info_icon, price_change_6m, 
-			price_change_2024, price_change_2023, price_change_2022, 
-			price_change_2021, price_change_2020, nav_million_rub, last_update_date
-		FROM etf_data 
-		WHERE date_scraped = (SELECT MAX(date_scraped) FROM etf_data)
-		AND (
-			ticker LIKE ? OR 
-			fund_name LIKE ? OR 
-			management_company LIKE ?
-		)
-		ORDER BY nav_million_rub DESC
-	`
+	session, ok := h.asOfSession(w, r)
+	if !ok {
+		return
+	}
 
-	searchPattern := "%" + searchTerm + "%"
-	rows, err := h.db.DB.Query(query, searchPattern, searchPattern, searchPattern)
+	hits, terms, err := h.repo.SearchETFs(session, searchTerm)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	ids := make([]int64, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	byID, err := h.etfsByID(ids)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	etfs := make([]models.ETFResponse, 0, len(hits))
+	scores := make([]float64, 0, len(hits))
+	for _, hit := range hits {
+		if etf, ok := byID[hit.ID]; ok {
+			etfs = append(etfs, etf)
+			scores = append(scores, hit.Score)
+		}
+	}
+
+	if !h.convertETFs(w, r, etfs) {
+		return
+	}
+
+	results := make([]models.SearchResult, len(etfs))
+	for i, etf := range etfs {
+		results[i] = models.SearchResult{
+			ETFResponse: etf,
+			Score:       scores[i],
+			Highlights:  highlightETF(etf, terms),
+		}
+	}
+
+	respondJSON(w, results)
+}
+
+// etfsByID загружает записи etf_data по ID
+func (h *Handlers) etfsByID(ids []int64) (map[int64]models.ETFResponse, error) {
+	result := make(map[int64]models.ETFResponse, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := `SELECT ` + database.ETFColumns + `
+		FROM etf_data
+		WHERE id IN (?` + strings.Repeat(", ?", len(ids)-1) + `)`
+
+	rows, err := h.db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	etfs := []models.ETFResponse{}
 	for rows.Next() {
-		var etf models.ETFResponse
-		err := rows.Scan(
-			&etf.ID, &etf.DateScraped, &etf.Ticker, &etf.TradeStatus,
-			&etf.ManagementCo, &etf.AssetClass, &etf.TERPercent, &etf.TERDirection,
-			&etf.FundName, &etf.ManagementStyle, &etf.TargetIndex, &etf.Currency,
-			&etf.StartDate, &etf.InfoIcon, &etf.PriceChange6M, &etf.PriceChange2024,
-			&etf.PriceChange2023, &etf.PriceChange2022, &etf.PriceChange2021,
-			&etf.PriceChange2020, &etf.NAVMillionRub, &etf.LastUpdateDate,
-		)
+		etf, err := scanETFResponse(rows)
 		if err != nil {
-			continue
+			return nil, err
 		}
-		etfs = append(etfs, etf)
+		result[int64(etf.ID)] = etf
 	}
 
-	respondJSON(w, etfs)
+	return result, rows.Err()
+}
+
+// highlightETF подсвечивает совпадения с запросом в текстовых полях фонда
+func highlightETF(etf models.ETFResponse, terms []search.Term) map[string]string {
+	highlights := make(map[string]string)
+	for field, text := range map[string]string{
+		"ticker":       etf.Ticker,
+		"fundName":     etf.FundName,
+		"managementCo": etf.ManagementCo,
+		"targetIndex":  etf.TargetIndex,
+	} {
+		if marked, ok := search.Highlight(text, terms); ok {
+			highlights[field] = marked
+		}
+	}
+	return highlights
+}
+
+// HandleDiff сравнивает два сеанса скрейпинга (from/to — ID запуска или дата)
+func (h *Handlers) HandleDiff(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	d, err := diff.Run(h.repo, params.Get("from"), params.Get("to"))
+	if errors.Is(err, database.ErrUnknownSession) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, d)
+}
+
+// asOfSession возвращает сеанс, по состоянию на который строится ответ: параметр asOf —
+// ID запуска, date_scraped или дата (см. database.Repository.ResolveAsOf); без параметра — последний
+// сеанс. При ошибке ответ уже отправлен.
+func (h *Handlers) asOfSession(w http.ResponseWriter, r *http.Request) (string, bool) {
+	session, err := h.repo.ResolveAsOf(r.URL.Query().Get("asOf"))
+	if errors.Is(err, database.ErrUnknownSession) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return "", false
+	}
+	return session, true
+}
+
+// etfFilterFromQuery собирает фильтр списка ETF из параметров запроса;
+// неизвестные значения terTrend и infoFlag — ошибка
+func etfFilterFromQuery(params url.Values) (database.ETFFilter, error) {
+	filter := database.ETFFilter{
+		AssetClass: params.Get("assetClass"),
+		TERTrend:   params.Get("terTrend"),
+		InfoFlag:   params.Get("infoFlag"),
+		SortBy:     params.Get("sortBy"),
+		Order:      params.Get("order"),
+	}
+	if filter.TERTrend != "" && !slices.Contains(models.TERTrends, models.TERTrend(filter.TERTrend)) {
+		return filter, fmt.Errorf("unknown terTrend %q", filter.TERTrend)
+	}
+	if filter.InfoFlag != "" && !slices.Contains(models.InfoFlags, models.InfoFlag(filter.InfoFlag)) {
+		return filter, fmt.Errorf("unknown infoFlag %q", filter.InfoFlag)
+	}
+	return filter, nil
+}
+
+// rowScanner обобщает *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanETFResponse сканирует строку etf_data, выбранную через database.ETFColumns
+func scanETFResponse(row rowScanner) (models.ETFResponse, error) {
+	var etf models.ETFResponse
+	var terTrend, infoFlags string
+
+	err := row.Scan(
+		&etf.ID, &etf.DateScraped, &etf.Ticker, &etf.TradeStatus,
+		&etf.ManagementCo, &etf.AssetClass, &etf.TERPercent, &etf.TERDirection,
+		&etf.FundName, &etf.ManagementStyle, &etf.TargetIndex, &etf.Currency,
+		&etf.StartDate, &etf.InfoIcon, &terTrend, &infoFlags,
+		&etf.PriceChange6M, &etf.PriceChange2024,
+		&etf.PriceChange2023, &etf.PriceChange2022, &etf.PriceChange2021,
+		&etf.PriceChange2020, &etf.NAVMillionRub, &etf.LastUpdateDate,
+	)
+	if err != nil {
+		return etf, err
+	}
+
+	etf.TERTrend = models.TERTrend(terTrend)
+	etf.InfoFlags = models.SplitInfoFlags(infoFlags)
+	return etf, nil
 }
 
 // respondJSON отправляет JSON ответ
@@ -0,0 +1,574 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"etf-scraper/internal/alerts"
+	"etf-scraper/internal/analytics"
+	"etf-scraper/internal/config"
+	"etf-scraper/internal/fxrates"
+	"etf-scraper/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// docsPage — страница просмотра документа OpenAPI (Swagger UI)
+//
+//go:embed docs.html
+var docsPage []byte
+
+// apiParam описывает параметр запроса
+type apiParam struct {
+	name        string
+	in          string // query (по умолчанию) или path
+	typ         string // string (по умолчанию), integer, number, boolean
+	description string
+	required    bool
+	enum        []string
+}
+
+// apiOperation описывает маршрут API для документа OpenAPI
+type apiOperation struct {
+	method  string
+	path    string // шаблон пути как в mux, например /api/etfs/{ticker}
+	admin   bool
+	tag     string
+	summary string
+	params  []apiParam
+
+	body      interface{} // значение типа тела JSON; nil — тела нет
+	bodyTypes []string    // типы содержимого тела-файла (text/csv и т.п.)
+
+	status       int         // код успешного ответа (по умолчанию 200)
+	response     interface{} // значение типа ответа JSON; nil — ответ без тела или файл
+	contentTypes []string    // типы содержимого ответа-файла
+	errors       []int       // коды ошибок (text/plain)
+}
+
+// Общие параметры
+var (
+	asOfParam = apiParam{name: "asOf",
+		description: "State as of a past scrape: run ID, exact date_scraped or date (latest session on or before that day)"}
+	currencyParam = apiParam{name: "currency", enum: fxrates.Supported,
+		description: "Express NAV and returns in this currency (field converted)"}
+	tickerPath   = apiParam{name: "ticker", in: "path", description: "Fund ticker"}
+	tickersParam = apiParam{name: "tickers", required: true,
+		description: "Comma-separated tickers (max 50)"}
+	yearFromParam = apiParam{name: "from", typ: "integer", description: "First year of the period"}
+	yearToParam   = apiParam{name: "to", typ: "integer", description: "Last year of the period"}
+)
+
+// etfFilterParams — фильтры и сортировка списка ETF (etfFilterFromQuery)
+var etfFilterParams = []apiParam{
+	{name: "assetClass", description: "Asset class filter"},
+	{name: "terTrend", description: "TER direction filter", enum: []string{
+		string(models.TERTrendUp), string(models.TERTrendDown),
+		string(models.TERTrendUnchanged), string(models.TERTrendUnknown)}},
+	{name: "infoFlag", description: "Fund flag filter", enum: []string{
+		string(models.InfoFlagNewFund), string(models.InfoFlagClosed), string(models.InfoFlagQualifiedOnly),
+		string(models.InfoFlagSuspended), string(models.InfoFlagUnknown)}},
+	{name: "sortBy", description: "Sort column, e.g. nav_million_rub, ter_percent, ticker, price_change_2024"},
+	{name: "order", description: "Sort order", enum: []string{"ASC", "DESC"}},
+}
+
+// exportParams — параметры выгрузки (writeExport)
+var exportParams = []apiParam{
+	{name: "format", enum: []string{"csv", "xlsx"}, description: "File format (default csv)"},
+	{name: "columns", description: "Comma-separated column keys"},
+	{name: "lang", enum: []string{"ru", "en"}, description: "Header language"},
+	{name: "sep", enum: []string{"comma", "semicolon"}, description: "CSV separator; semicolon also uses decimal commas"},
+	{name: "bom", typ: "boolean", description: "Write UTF-8 BOM (default true)"},
+}
+
+// feedParams — фильтры ленты событий (writeFeed)
+var feedParams = []apiParam{
+	{name: "ticker", description: "Only events of this ticker"},
+	{name: "managementCo", description: "Only events of this management company (matched after name normalization)"},
+	{name: "kind", description: "Event kind", enum: []string{
+		models.EventListed, models.EventDelisted, models.EventSuspended, models.EventResumed,
+		models.EventTERChanged, models.EventRenamed, models.EventNAVThreshold}},
+	{name: "limit", typ: "integer", description: "Number of entries (default 50, max 500)"},
+}
+
+func params(groups ...[]apiParam) []apiParam {
+	var result []apiParam
+	for _, g := range groups {
+		result = append(result, g...)
+	}
+	return result
+}
+
+// apiOperations перечисляет все маршруты публичного и админского серверов.
+// При добавлении маршрута в setupRoutes или setupAdminRoutes его нужно описать здесь:
+// расхождения показывает checkOpenAPI (etfscraper openapi -check).
+var apiOperations = []apiOperation{
+	// ETF
+	{method: "GET", path: "/api/etfs", tag: "ETF", summary: "All ETFs of a scrape session",
+		params:   params(etfFilterParams, []apiParam{currencyParam, asOfParam}),
+		response: []models.ETFResponse{}, errors: []int{400}},
+	{method: "GET", path: "/api/etfs/{ticker}", tag: "ETF", summary: "Latest record of an ETF",
+		params:   []apiParam{tickerPath, currencyParam},
+		response: models.ETFResponse{}, errors: []int{400, 404}},
+	{method: "GET", path: "/api/etfs/{ticker}/alternatives", tag: "ETF", summary: "Funds with the same exposure",
+		params:   []apiParam{tickerPath},
+		response: models.AlternativesResponse{}, errors: []int{404}},
+	{method: "GET", path: "/api/etfs/{ticker}/tracking", tag: "Benchmarks", summary: "Tracking difference and error against the fund's benchmark",
+		params:   []apiParam{tickerPath, yearFromParam, yearToParam},
+		response: models.TrackingResponse{}, errors: []int{400, 404}},
+	{method: "GET", path: "/api/stats", tag: "ETF", summary: "Statistics",
+		params:   []apiParam{asOfParam},
+		response: models.StatsResponse{}, errors: []int{400}},
+	{method: "GET", path: "/api/asset-classes", tag: "ETF", summary: "Asset classes",
+		params:   []apiParam{asOfParam},
+		response: models.AssetClassResponse{}, errors: []int{400}},
+	{method: "GET", path: "/api/asset-classes/summary", tag: "Analytics", summary: "Asset class breakdown and time series",
+		params:   []apiParam{{name: "series", typ: "boolean", description: "Include per-session series (default true)"}},
+		response: models.AssetClassSummaryResponse{}},
+	{method: "GET", path: "/api/top-by-nav", tag: "ETF", summary: "Top ETFs by NAV",
+		params:   []apiParam{{name: "limit", typ: "integer", description: "Number of funds (default 10)"}, currencyParam, asOfParam},
+		response: []models.ETFResponse{}, errors: []int{400}},
+	{method: "GET", path: "/api/search", tag: "ETF", summary: "Ranked full-text search with highlights",
+		params: []apiParam{
+			{name: "q", required: true, description: "Words to find in ticker, fund name, management company and target index"},
+			asOfParam, currencyParam,
+		},
+		response: []models.SearchResult{}, errors: []int{400}},
+	{method: "GET", path: "/api/compare", tag: "ETF", summary: "Side-by-side comparison",
+		params:   []apiParam{tickersParam, currencyParam},
+		response: models.CompareResponse{}, errors: []int{400}},
+	{method: "GET", path: "/api/diff", tag: "ETF", summary: "Diff between scrape sessions",
+		params: []apiParam{
+			{name: "from", description: "Run ID or date; default — session before to"},
+			{name: "to", description: "Run ID or date; default — latest session"},
+		},
+		response: models.SnapshotDiff{}, errors: []int{400}},
+	{method: "GET", path: "/api/fx-rates", tag: "FX", summary: "Exchange rates to RUB",
+		params: []apiParam{
+			{name: "currency", description: "Currency code"},
+			{name: "from", description: "First date (YYYY-MM-DD)"},
+			{name: "to", description: "Last date (YYYY-MM-DD)"},
+		},
+		response: []models.FXRate{}, errors: []int{400}},
+	{method: "GET", path: "/api/benchmarks", tag: "Benchmarks", summary: "Benchmark indexes",
+		response: []models.BenchmarkResponse{}},
+	{method: "GET", path: "/api/benchmarks/{code}/league", tag: "Benchmarks", summary: "Tracking league table",
+		params:   []apiParam{{name: "code", in: "path", description: "Benchmark code"}, yearFromParam, yearToParam},
+		response: models.LeagueResponse{}, errors: []int{400, 404}},
+	{method: "GET", path: "/api/management-companies", tag: "Companies", summary: "Management company aggregates",
+		response: models.CompaniesResponse{}},
+	{method: "GET", path: "/api/management-companies/ranking", tag: "Companies", summary: "Company ranking",
+		params: []apiParam{
+			{name: "by", enum: analytics.Rankings, description: "Ranking criterion (default nav)"},
+			{name: "limit", typ: "integer", description: "Number of companies"},
+		},
+		response: models.CompaniesResponse{}, errors: []int{400}},
+	{method: "GET", path: "/api/management-companies/{name}", tag: "Companies", summary: "Company funds and share history",
+		params:   []apiParam{{name: "name", in: "path", description: "Management company name"}},
+		response: models.CompanyDetail{}, errors: []int{404}},
+	{method: "POST", path: "/api/portfolio/backtest", tag: "Tools", summary: "Model portfolio backtest",
+		body:     models.BacktestRequest{},
+		response: models.BacktestResponse{}, errors: []int{400}},
+	{method: "GET", path: "/api/tools/fee-impact", tag: "Tools", summary: "Fee impact",
+		params: []apiParam{
+			tickersParam,
+			{name: "amount", typ: "number", description: "Initial amount (default 100000)"},
+			{name: "years", typ: "integer", description: "Horizon in years (default 10)"},
+			{name: "return", typ: "number", description: "Gross annual return, % (default 10)"},
+		},
+		response: models.FeeImpactResponse{}, errors: []int{400}},
+	{method: "GET", path: "/api/analytics/flows", tag: "Analytics", summary: "Estimated fund flows",
+		params: []apiParam{
+			{name: "from", description: "Run ID or date"},
+			{name: "to", description: "Run ID or date"},
+		},
+		response: models.FlowReport{}, errors: []int{400}},
+	{method: "GET", path: "/api/analytics/risk", tag: "Analytics", summary: "Volatility, drawdown, Sharpe",
+		params: []apiParam{
+			{name: "tickers", description: "Comma-separated tickers; default — all funds"},
+			{name: "riskFree", typ: "number", description: "Risk-free rate, % (default RISK_FREE_RATE)"},
+		},
+		response: models.RiskResponse{}, errors: []int{400}},
+	{method: "GET", path: "/api/analytics/correlation", tag: "Analytics", summary: "Return correlation matrix",
+		params:   []apiParam{tickersParam},
+		response: models.CorrelationResponse{}, errors: []int{400}},
+	{method: "GET", path: "/api/feed.atom", tag: "Feeds", summary: "Fund events feed (Atom)",
+		params: feedParams, contentTypes: []string{"application/atom+xml"}},
+	{method: "GET", path: "/api/feed.rss", tag: "Feeds", summary: "Fund events feed (RSS)",
+		params: feedParams, contentTypes: []string{"application/rss+xml"}},
+	{method: "GET", path: "/api/export/etfs", tag: "Export", summary: "Export ETFs",
+		params:       params(etfFilterParams, []apiParam{asOfParam}, exportParams),
+		contentTypes: []string{"text/csv", xlsxContentType}, errors: []int{400}},
+	{method: "GET", path: "/api/export/etfs/{ticker}/history", tag: "Export", summary: "Export ticker history",
+		params:       params([]apiParam{tickerPath}, exportParams),
+		contentTypes: []string{"text/csv", xlsxContentType}, errors: []int{400, 404}},
+	{method: "GET", path: "/api/openapi.json", tag: "Docs", summary: "This OpenAPI document",
+		response: map[string]interface{}{}},
+	{method: "GET", path: "/api/docs", tag: "Docs", summary: "API documentation viewer",
+		contentTypes: []string{"text/html"}},
+
+	// Админский API
+	{method: "POST", path: "/admin/scrape", admin: true, tag: "Admin", summary: "Start scraping in background",
+		response: map[string]interface{}{}},
+	{method: "GET", path: "/admin/status", admin: true, tag: "Admin", summary: "System status",
+		response: map[string]interface{}{}},
+	{method: "GET", path: "/admin/info", admin: true, tag: "Admin", summary: "Client certificate info",
+		response: map[string]string{}},
+	{method: "GET", path: "/admin/runs", admin: true, tag: "Admin", summary: "Scrape runs",
+		params:   []apiParam{{name: "limit", typ: "integer", description: "Number of runs"}},
+		response: []models.ScrapeRunResponse{}},
+	{method: "GET", path: "/admin/runs/{id}/errors", admin: true, tag: "Admin", summary: "Parse error report",
+		params:   []apiParam{{name: "id", in: "path", typ: "integer", description: "Run ID"}},
+		response: models.ParseErrorReportResponse{}, errors: []int{400, 404}},
+	{method: "POST", path: "/admin/dump", admin: true, tag: "Admin", summary: "Start Parquet/NDJSON dump in background",
+		params: []apiParam{
+			{name: "format", description: "Comma-separated formats: parquet, ndjson (default both)"},
+			{name: "incremental", typ: "boolean", description: "Only sessions not yet in the manifest"},
+		},
+		status: http.StatusAccepted, response: map[string]interface{}{}, errors: []int{400, 409}},
+	{method: "GET", path: "/admin/webhooks", admin: true, tag: "Webhooks", summary: "Webhook subscriptions",
+		response: []models.WebhookResponse{}},
+	{method: "POST", path: "/admin/webhooks", admin: true, tag: "Webhooks", summary: "Create webhook",
+		body: models.WebhookRequest{}, status: http.StatusCreated,
+		response: models.WebhookResponse{}, errors: []int{400}},
+	{method: "DELETE", path: "/admin/webhooks/{id}", admin: true, tag: "Webhooks", summary: "Delete webhook",
+		params: []apiParam{{name: "id", in: "path", typ: "integer", description: "Webhook ID"}},
+		status: http.StatusNoContent, errors: []int{400, 404}},
+	{method: "GET", path: "/admin/webhooks/{id}/deliveries", admin: true, tag: "Webhooks", summary: "Delivery log",
+		params: []apiParam{
+			{name: "id", in: "path", typ: "integer", description: "Webhook ID"},
+			{name: "limit", typ: "integer", description: "Number of deliveries"},
+		},
+		response: []models.WebhookDeliveryResponse{}, errors: []int{400, 404}},
+	{method: "POST", path: "/admin/webhooks/{id}/test", admin: true, tag: "Webhooks", summary: "Send test ping",
+		params:   []apiParam{{name: "id", in: "path", typ: "integer", description: "Webhook ID"}},
+		response: models.WebhookDeliveryResponse{}, errors: []int{400, 404}},
+	{method: "GET", path: "/admin/watchlists", admin: true, tag: "Watchlists", summary: "Watchlists",
+		response: []models.WatchlistResponse{}},
+	{method: "POST", path: "/admin/watchlists", admin: true, tag: "Watchlists", summary: "Create watchlist",
+		body: models.WatchlistRequest{}, status: http.StatusCreated,
+		response: models.WatchlistResponse{}, errors: []int{400, 409}},
+	{method: "GET", path: "/admin/watchlists/{id}", admin: true, tag: "Watchlists", summary: "Watchlist by ID",
+		params:   []apiParam{{name: "id", in: "path", typ: "integer", description: "Watchlist ID"}},
+		response: models.WatchlistResponse{}, errors: []int{400, 404}},
+	{method: "DELETE", path: "/admin/watchlists/{id}", admin: true, tag: "Watchlists", summary: "Delete watchlist",
+		params: []apiParam{{name: "id", in: "path", typ: "integer", description: "Watchlist ID"}},
+		status: http.StatusNoContent, errors: []int{400, 404}},
+	{method: "PUT", path: "/admin/watchlists/{id}/tickers", admin: true, tag: "Watchlists", summary: "Replace tickers",
+		params: []apiParam{{name: "id", in: "path", typ: "integer", description: "Watchlist ID"}},
+		body:   models.WatchlistRequest{}, response: models.WatchlistResponse{}, errors: []int{400, 404}},
+	{method: "POST", path: "/admin/watchlists/{id}/rules", admin: true, tag: "Watchlists", summary: "Add alert rule",
+		params: []apiParam{{name: "id", in: "path", typ: "integer", description: "Watchlist ID"}},
+		body:   models.AlertRuleRequest{}, status: http.StatusCreated,
+		response: models.WatchlistResponse{}, errors: []int{400, 404}},
+	{method: "DELETE", path: "/admin/watchlists/{id}/rules/{ruleId}", admin: true, tag: "Watchlists", summary: "Delete rule",
+		params: []apiParam{
+			{name: "id", in: "path", typ: "integer", description: "Watchlist ID"},
+			{name: "ruleId", in: "path", typ: "integer", description: "Rule ID"},
+		},
+		status: http.StatusNoContent, errors: []int{400, 404}},
+	{method: "GET", path: "/admin/alerts", admin: true, tag: "Watchlists", summary: "Fired alerts",
+		params:   []apiParam{{name: "limit", typ: "integer", description: "Number of alerts"}},
+		response: []models.AlertResponse{}},
+	{method: "GET", path: "/admin/index-aliases", admin: true, tag: "Benchmarks", summary: "Index name normalization table",
+		response: []models.IndexAliasResponse{}},
+	{method: "PUT", path: "/admin/index-aliases", admin: true, tag: "Benchmarks", summary: "Add or replace index alias",
+		body: models.IndexAliasRequest{}, response: models.IndexAliasResponse{}, errors: []int{400}},
+	{method: "DELETE", path: "/admin/index-aliases/{alias}", admin: true, tag: "Benchmarks", summary: "Delete index alias",
+		params: []apiParam{{name: "alias", in: "path", description: "Alias"}},
+		status: http.StatusNoContent, errors: []int{404}},
+	{method: "PUT", path: "/admin/benchmarks/{code}", admin: true, tag: "Benchmarks", summary: "Create or rename benchmark",
+		params: []apiParam{{name: "code", in: "path", description: "Benchmark code"}},
+		body:   models.BenchmarkRequest{}, response: models.BenchmarkResponse{}, errors: []int{400}},
+	{method: "DELETE", path: "/admin/benchmarks/{code}", admin: true, tag: "Benchmarks", summary: "Delete benchmark with levels and links",
+		params: []apiParam{{name: "code", in: "path", description: "Benchmark code"}},
+		status: http.StatusNoContent, errors: []int{404}},
+	{method: "POST", path: "/admin/benchmarks/{code}/levels", admin: true, tag: "Benchmarks", summary: "Import index levels (CSV or JSON)",
+		params:    []apiParam{{name: "code", in: "path", description: "Benchmark code"}},
+		body:      []models.BenchmarkLevel{},
+		bodyTypes: []string{"text/csv"},
+		response:  models.BenchmarkResponse{}, errors: []int{400, 404}},
+	{method: "PUT", path: "/admin/funds/{ticker}/benchmark", admin: true, tag: "Benchmarks", summary: "Link fund to benchmark",
+		params: []apiParam{tickerPath},
+		body:   models.FundBenchmarkRequest{}, response: models.BenchmarkResponse{}, errors: []int{400, 404}},
+	{method: "DELETE", path: "/admin/funds/{ticker}/benchmark", admin: true, tag: "Benchmarks", summary: "Remove fund benchmark link",
+		params: []apiParam{tickerPath},
+		status: http.StatusNoContent, errors: []int{404}},
+	{method: "POST", path: "/admin/fx-rates", admin: true, tag: "FX", summary: "Import exchange rates (CBR XML or CSV)",
+		bodyTypes: []string{"application/xml", "text/csv"},
+		response:  models.FXImportResponse{}, errors: []int{400}},
+}
+
+// xlsxContentType — тип содержимого выгрузки XLSX
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// alertKindsDescription дополняет описание правил допустимыми видами
+var alertKindsDescription = "Alert rule kinds: " + strings.Join(alerts.Kinds, ", ")
+
+// HandleOpenAPI отдает документ OpenAPI со всеми маршрутами и моделями ответов
+func (h *Handlers) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, OpenAPIDocument(h.config))
+}
+
+// HandleDocs отдает страницу просмотра документа OpenAPI
+func (h *Handlers) HandleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docsPage)
+}
+
+// OpenAPIDocument строит документ OpenAPI 3.1 по apiOperations; схемы моделей
+// выводятся из типов models по тегам json
+func OpenAPIDocument(cfg *config.Config) map[string]interface{} {
+	b := &schemaBuilder{schemas: make(map[string]interface{})}
+	adminServers := []map[string]string{{
+		"url":         "https://localhost:" + cfg.AdminPort,
+		"description": "Admin server (mTLS)",
+	}}
+
+	paths := make(map[string]map[string]interface{})
+	for _, op := range apiOperations {
+		item, ok := paths[op.path]
+		if !ok {
+			item = make(map[string]interface{})
+			if op.admin {
+				item["servers"] = adminServers
+			}
+			paths[op.path] = item
+		}
+		item[strings.ToLower(op.method)] = b.operation(op)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       "ETF Scraper API",
+			"version":     "1.0.0",
+			"description": "Russian ETF data scraped from assetallocation.ru: funds, history, analytics and admin tools. Errors are returned as text/plain. " + alertKindsDescription + ".",
+		},
+		"servers": []map[string]string{{
+			"url":         "http://localhost:" + cfg.ServerPort,
+			"description": "Public server",
+		}},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": b.schemas,
+			"securitySchemes": map[string]interface{}{
+				"mutualTLS": map[string]string{
+					"type":        "mutualTLS",
+					"description": "Client certificate signed by the admin CA with an allowed DN",
+				},
+			},
+		},
+	}
+}
+
+// operation описывает маршрут в формате OpenAPI
+func (b *schemaBuilder) operation(op apiOperation) map[string]interface{} {
+	result := map[string]interface{}{
+		"summary":     op.summary,
+		"tags":        []string{op.tag},
+		"operationId": operationID(op),
+	}
+	if op.admin {
+		result["security"] = []map[string][]string{{"mutualTLS": {}}}
+	}
+
+	parameters := []map[string]interface{}{}
+	for _, p := range op.params {
+		in := p.in
+		if in == "" {
+			in = "query"
+		}
+		typ := p.typ
+		if typ == "" {
+			typ = "string"
+		}
+		schema := map[string]interface{}{"type": typ}
+		if len(p.enum) > 0 {
+			schema["enum"] = p.enum
+		}
+		parameters = append(parameters, map[string]interface{}{
+			"name":        p.name,
+			"in":          in,
+			"required":    p.required || in == "path",
+			"description": p.description,
+			"schema":      schema,
+		})
+	}
+	if len(parameters) > 0 {
+		result["parameters"] = parameters
+	}
+
+	if op.body != nil || len(op.bodyTypes) > 0 {
+		content := make(map[string]interface{})
+		if op.body != nil {
+			content["application/json"] = map[string]interface{}{"schema": b.schema(reflect.TypeOf(op.body))}
+		}
+		for _, ct := range op.bodyTypes {
+			content[ct] = map[string]interface{}{"schema": map[string]string{"type": "string"}}
+		}
+		result["requestBody"] = map[string]interface{}{"required": true, "content": content}
+	}
+
+	status := op.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	content := make(map[string]interface{})
+	if op.response != nil {
+		content["application/json"] = map[string]interface{}{"schema": b.schema(reflect.TypeOf(op.response))}
+	}
+	for _, ct := range op.contentTypes {
+		content[ct] = map[string]interface{}{"schema": map[string]string{"type": "string"}}
+	}
+	if len(content) > 0 {
+		success["content"] = content
+	}
+
+	responses := map[string]interface{}{fmt.Sprint(status): success}
+	for _, code := range append(op.errors, http.StatusInternalServerError) {
+		responses[fmt.Sprint(code)] = map[string]interface{}{
+			"description": http.StatusText(code),
+			"content": map[string]interface{}{
+				"text/plain": map[string]interface{}{"schema": map[string]string{"type": "string"}},
+			},
+		}
+	}
+	result["responses"] = responses
+
+	return result
+}
+
+var nonWord = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// operationID строит идентификатор операции из метода и пути: get_api_etfs_ticker
+func operationID(op apiOperation) string {
+	return strings.ToLower(op.method) + strings.TrimRight(nonWord.ReplaceAllString(op.path, "_"), "_")
+}
+
+// schemaBuilder собирает схемы JSON по типам Go так же, как их кодирует encoding/json
+type schemaBuilder struct {
+	schemas map[string]interface{}
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		inner := b.schema(t.Elem())
+		if typ, ok := inner["type"].(string); ok {
+			nullable := make(map[string]interface{}, len(inner))
+			for k, v := range inner {
+				nullable[k] = v
+			}
+			nullable["type"] = []string{typ, "null"}
+			return nullable
+		}
+		return map[string]interface{}{"anyOf": []interface{}{inner, map[string]string{"type": "null"}}}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := b.schemas[name]; !ok {
+			b.schemas[name] = nil // защита от рекурсии
+			b.schemas[name] = b.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{} // interface{} — любое значение
+}
+
+// object описывает структуру; поля встроенных структур без тега json поднимаются наверх
+func (b *schemaBuilder) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	b.fields(t, properties)
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+func (b *schemaBuilder) fields(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			b.fields(f.Type, properties)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = b.schema(f.Type)
+	}
+}
+
+// checkOpenAPI сравнивает маршруты роутеров с apiOperations и возвращает расхождения
+func (s *Server) checkOpenAPI() []string {
+	registered := make(map[string]bool)
+	for _, router := range []*mux.Router{s.router, s.adminRouter} {
+		router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			path, errPath := route.GetPathTemplate()
+			methods, errMethods := route.GetMethods()
+			if errPath != nil || errMethods != nil {
+				return nil // статические файлы и подроутеры без методов
+			}
+			for _, m := range methods {
+				if m != http.MethodOptions {
+					registered[m+" "+path] = true
+				}
+			}
+			return nil
+		})
+	}
+
+	documented := make(map[string]bool, len(apiOperations))
+	for _, op := range apiOperations {
+		documented[op.method+" "+op.path] = true
+	}
+
+	var problems []string
+	for route := range registered {
+		if !documented[route] {
+			problems = append(problems, "not documented: "+route)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			problems = append(problems, "documented but not registered: "+route)
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// CheckOpenAPI возвращает расхождения между маршрутами сервера и документом OpenAPI
+func CheckOpenAPI(cfg *config.Config) []string {
+	return NewServer(cfg, nil, nil).checkOpenAPI()
+}
+
+// MarshalOpenAPI возвращает документ OpenAPI в виде JSON с отступами
+func MarshalOpenAPI(cfg *config.Config) ([]byte, error) {
+	return json.MarshalIndent(OpenAPIDocument(cfg), "", "  ")
+}
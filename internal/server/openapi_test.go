@@ -0,0 +1,62 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"etf-scraper/internal/config"
+)
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	s := NewServer(config.NewConfig(), nil, nil)
+	for _, problem := range s.checkOpenAPI() {
+		t.Error(problem)
+	}
+}
+
+func TestOpenAPICheckDetectsDrift(t *testing.T) {
+	s := NewServer(config.NewConfig(), nil, nil)
+	s.router.HandleFunc("/api/undocumented", func(http.ResponseWriter, *http.Request) {}).Methods("GET")
+	s.adminRouter.HandleFunc("/admin/undocumented", func(http.ResponseWriter, *http.Request) {}).Methods("POST", "OPTIONS")
+
+	saved := apiOperations
+	apiOperations = append(apiOperations[:len(apiOperations):len(apiOperations)],
+		apiOperation{method: "DELETE", path: "/api/etfs/{ticker}"})
+	defer func() { apiOperations = saved }()
+
+	problems := s.checkOpenAPI()
+	want := []string{
+		"documented but not registered: DELETE /api/etfs/{ticker}",
+		"not documented: GET /api/undocumented",
+		"not documented: POST /admin/undocumented",
+	}
+	if strings.Join(problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("checkOpenAPI() = %q, want %q", problems, want)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	data, err := MarshalOpenAPI(config.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.1") {
+		t.Errorf("openapi = %q, want 3.1.x", doc.OpenAPI)
+	}
+
+	for _, op := range apiOperations {
+		if _, ok := doc.Paths[op.path][strings.ToLower(op.method)]; !ok {
+			t.Errorf("document has no operation %s %s", op.method, op.path)
+		}
+	}
+}
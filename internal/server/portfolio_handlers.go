@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"etf-scraper/internal/backtest"
+	"etf-scraper/internal/models"
+)
+
+// HandlePortfolioBacktest рассчитывает модельный портфель по сохраненным
+// годовым доходностям фондов (последние данные каждого тикера)
+func (h *Handlers) HandlePortfolioBacktest(w http.ResponseWriter, r *http.Request) {
+	var req models.BacktestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Weights) == 0 {
+		http.Error(w, "weights required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Weights) > maxToolTickers {
+		http.Error(w, "too many tickers (max "+strconv.Itoa(maxToolTickers)+")", http.StatusBadRequest)
+		return
+	}
+
+	tickers := make([]string, 0, len(req.Weights))
+	for ticker := range req.Weights {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+
+	var assets []backtest.Asset
+	var unknown []string
+	partial := make(map[int]bool)
+	for _, key := range tickers {
+		ticker := strings.ToUpper(strings.TrimSpace(key))
+		data, err := h.repo.GetLatestETFs(ticker)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(data) == 0 {
+			unknown = append(unknown, ticker)
+			continue
+		}
+
+		etf := data[0]
+		asset := backtest.Asset{Ticker: etf.Ticker, Weight: req.Weights[key], Returns: make(map[int]float64)}
+		for _, year := range models.ReturnYears {
+			if v := etf.PriceChangeYear(year); v != nil {
+				asset.Returns[year] = *v
+			}
+		}
+		if len(etf.DateScraped) >= 4 {
+			if year, err := strconv.Atoi(etf.DateScraped[:4]); err == nil {
+				partial[year] = true
+			}
+		}
+		assets = append(assets, asset)
+	}
+	if len(unknown) > 0 {
+		http.Error(w, "unknown tickers: "+strings.Join(unknown, ", "), http.StatusBadRequest)
+		return
+	}
+
+	result, err := backtest.Run(assets, backtest.Options{
+		Years:     models.ReturnYears,
+		Rebalance: backtest.Rebalance(req.Rebalance),
+		Missing:   backtest.Missing(req.Missing),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := models.BacktestResponse{
+		Rebalance:        string(backtest.RebalanceAnnual),
+		Missing:          string(backtest.MissingReweight),
+		Weights:          result.Weights,
+		Years:            make([]models.BacktestYear, 0, len(result.Years)),
+		YearsCalculated:  result.YearsCalculated,
+		CumulativeReturn: result.CumulativeReturn,
+		AnnualizedReturn: result.AnnualizedReturn,
+		Volatility:       result.Volatility,
+		MaxDrawdown:      result.MaxDrawdown,
+	}
+	if req.Rebalance != "" {
+		response.Rebalance = req.Rebalance
+	}
+	if req.Missing != "" {
+		response.Missing = req.Missing
+	}
+	for _, y := range result.Years {
+		missing := y.MissingTickers
+		if missing == nil {
+			missing = []string{}
+		}
+		response.Years = append(response.Years, models.BacktestYear{
+			Year:           y.Year,
+			Return:         y.Return,
+			Value:          y.Value,
+			Drawdown:       y.Drawdown,
+			MissingTickers: missing,
+			Skipped:        y.Skipped,
+			Partial:        partial[y.Year],
+		})
+	}
+
+	respondJSON(w, response)
+}
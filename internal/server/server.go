@@ -52,10 +52,32 @@ func (s *Server) setupRoutes() {
 
 	api.HandleFunc("/etfs", s.handlers.HandleGetAllETFs).Methods("GET", "OPTIONS")
 	api.HandleFunc("/etfs/{ticker}", s.handlers.HandleGetETFByTicker).Methods("GET", "OPTIONS")
+	api.HandleFunc("/etfs/{ticker}/alternatives", s.handlers.HandleGetAlternatives).Methods("GET", "OPTIONS")
+	api.HandleFunc("/etfs/{ticker}/tracking", s.handlers.HandleGetETFTracking).Methods("GET", "OPTIONS")
 	api.HandleFunc("/stats", s.handlers.HandleGetStats).Methods("GET", "OPTIONS")
 	api.HandleFunc("/asset-classes", s.handlers.HandleGetAssetClasses).Methods("GET", "OPTIONS")
+	api.HandleFunc("/asset-classes/summary", s.handlers.HandleGetAssetClassSummary).Methods("GET", "OPTIONS")
 	api.HandleFunc("/top-by-nav", s.handlers.HandleGetTopByNAV).Methods("GET", "OPTIONS")
 	api.HandleFunc("/search", s.handlers.HandleSearch).Methods("GET", "OPTIONS")
+	api.HandleFunc("/compare", s.handlers.HandleCompare).Methods("GET", "OPTIONS")
+	api.HandleFunc("/diff", s.handlers.HandleDiff).Methods("GET", "OPTIONS")
+	api.HandleFunc("/fx-rates", s.handlers.HandleGetFXRates).Methods("GET", "OPTIONS")
+	api.HandleFunc("/benchmarks", s.handlers.HandleGetBenchmarks).Methods("GET", "OPTIONS")
+	api.HandleFunc("/benchmarks/{code}/league", s.handlers.HandleGetBenchmarkLeague).Methods("GET", "OPTIONS")
+	api.HandleFunc("/management-companies", s.handlers.HandleGetCompanies).Methods("GET", "OPTIONS")
+	api.HandleFunc("/management-companies/ranking", s.handlers.HandleGetCompanyRanking).Methods("GET", "OPTIONS")
+	api.HandleFunc("/management-companies/{name}", s.handlers.HandleGetCompany).Methods("GET", "OPTIONS")
+	api.HandleFunc("/portfolio/backtest", s.handlers.HandlePortfolioBacktest).Methods("POST", "OPTIONS")
+	api.HandleFunc("/tools/fee-impact", s.handlers.HandleFeeImpact).Methods("GET", "OPTIONS")
+	api.HandleFunc("/analytics/flows", s.handlers.HandleAnalyticsFlows).Methods("GET", "OPTIONS")
+	api.HandleFunc("/analytics/risk", s.handlers.HandleAnalyticsRisk).Methods("GET", "OPTIONS")
+	api.HandleFunc("/analytics/correlation", s.handlers.HandleAnalyticsCorrelation).Methods("GET", "OPTIONS")
+	api.HandleFunc("/feed.atom", s.handlers.HandleFeedAtom).Methods("GET", "OPTIONS")
+	api.HandleFunc("/feed.rss", s.handlers.HandleFeedRSS).Methods("GET", "OPTIONS")
+	api.HandleFunc("/export/etfs", s.handlers.HandleExportETFs).Methods("GET", "OPTIONS")
+	api.HandleFunc("/export/etfs/{ticker}/history", s.handlers.HandleExportHistory).Methods("GET", "OPTIONS")
+	api.HandleFunc("/openapi.json", s.handlers.HandleOpenAPI).Methods("GET", "OPTIONS")
+	api.HandleFunc("/docs", s.handlers.HandleDocs).Methods("GET", "OPTIONS")
 
 	// Статические файлы
 	s.router.PathPrefix("/").Handler(http.FileServer(http.Dir(s.config.StaticDir)))
@@ -72,6 +94,31 @@ func (s *Server) setupAdminRoutes() {
 	admin.HandleFunc("/scrape", s.handlers.HandleAdminScrape).Methods("POST")
 	admin.HandleFunc("/status", s.handlers.HandleAdminStatus).Methods("GET")
 	admin.HandleFunc("/info", s.handlers.HandleAdminInfo).Methods("GET")
+	admin.HandleFunc("/runs", s.handlers.HandleAdminRuns).Methods("GET")
+	admin.HandleFunc("/runs/{id}/errors", s.handlers.HandleAdminRunErrors).Methods("GET")
+	admin.HandleFunc("/dump", s.handlers.HandleAdminDump).Methods("POST")
+	admin.HandleFunc("/webhooks", s.handlers.HandleAdminListWebhooks).Methods("GET")
+	admin.HandleFunc("/webhooks", s.handlers.HandleAdminCreateWebhook).Methods("POST")
+	admin.HandleFunc("/webhooks/{id}", s.handlers.HandleAdminDeleteWebhook).Methods("DELETE")
+	admin.HandleFunc("/webhooks/{id}/deliveries", s.handlers.HandleAdminWebhookDeliveries).Methods("GET")
+	admin.HandleFunc("/webhooks/{id}/test", s.handlers.HandleAdminTestWebhook).Methods("POST")
+	admin.HandleFunc("/watchlists", s.handlers.HandleAdminListWatchlists).Methods("GET")
+	admin.HandleFunc("/watchlists", s.handlers.HandleAdminCreateWatchlist).Methods("POST")
+	admin.HandleFunc("/watchlists/{id}", s.handlers.HandleAdminGetWatchlist).Methods("GET")
+	admin.HandleFunc("/watchlists/{id}", s.handlers.HandleAdminDeleteWatchlist).Methods("DELETE")
+	admin.HandleFunc("/watchlists/{id}/tickers", s.handlers.HandleAdminSetWatchlistTickers).Methods("PUT")
+	admin.HandleFunc("/watchlists/{id}/rules", s.handlers.HandleAdminCreateAlertRule).Methods("POST")
+	admin.HandleFunc("/watchlists/{id}/rules/{ruleId}", s.handlers.HandleAdminDeleteAlertRule).Methods("DELETE")
+	admin.HandleFunc("/alerts", s.handlers.HandleAdminAlerts).Methods("GET")
+	admin.HandleFunc("/index-aliases", s.handlers.HandleAdminListIndexAliases).Methods("GET")
+	admin.HandleFunc("/index-aliases", s.handlers.HandleAdminSaveIndexAlias).Methods("PUT")
+	admin.HandleFunc("/index-aliases/{alias}", s.handlers.HandleAdminDeleteIndexAlias).Methods("DELETE")
+	admin.HandleFunc("/benchmarks/{code}", s.handlers.HandleAdminSaveBenchmark).Methods("PUT")
+	admin.HandleFunc("/benchmarks/{code}", s.handlers.HandleAdminDeleteBenchmark).Methods("DELETE")
+	admin.HandleFunc("/benchmarks/{code}/levels", s.handlers.HandleAdminImportBenchmarkLevels).Methods("POST")
+	admin.HandleFunc("/funds/{ticker}/benchmark", s.handlers.HandleAdminSetFundBenchmark).Methods("PUT")
+	admin.HandleFunc("/funds/{ticker}/benchmark", s.handlers.HandleAdminDeleteFundBenchmark).Methods("DELETE")
+	admin.HandleFunc("/fx-rates", s.handlers.HandleAdminImportFXRates).Methods("POST")
 
 	// Статическая страница админки
 	s.adminRouter.PathPrefix("/").Handler(http.FileServer(http.Dir(s.config.StaticDir + "/admin")))
@@ -80,6 +127,9 @@ func (s *Server) setupAdminRoutes() {
 // Start запускает HTTP серверы
 func (s *Server) Start() error {
 	s.printServerInfo()
+	for _, problem := range s.checkOpenAPI() {
+		log.Printf("⚠️  OpenAPI: %s", problem)
+	}
 
 	// Запускаем публичный сервер (HTTP)
 	go func() {
@@ -122,17 +172,57 @@ func (s *Server) printServerInfo() {
 	log.Println("🚀 ETF Scraper Server")
 	log.Println("==================================================")
 	log.Printf("📊 Public API: http://localhost:%s", s.config.ServerPort)
-	log.Printf("   GET  /api/etfs                - All ETFs")
+	log.Printf("   GET  /api/etfs                - All ETFs (?currency=USD|EUR|CNY, ?asOf=date|run ID)")
 	log.Printf("   GET  /api/etfs/{ticker}       - ETF by ticker")
-	log.Printf("   GET  /api/stats               - Statistics")
+	log.Printf("   GET  /api/etfs/{ticker}/alternatives - Funds with the same exposure")
+	log.Printf("   GET  /api/etfs/{ticker}/tracking?from=&to= - Tracking difference and error")
+	log.Printf("   GET  /api/stats?asOf=         - Statistics")
 	log.Printf("   GET  /api/asset-classes       - Asset classes")
+	log.Printf("   GET  /api/asset-classes/summary - Asset class breakdown and time series")
 	log.Printf("   GET  /api/top-by-nav?limit=10 - Top by NAV")
-	log.Printf("   GET  /api/search?q=term       - Search")
+	log.Printf("   GET  /api/search?q=term       - Ranked search with highlights (FTS5: %v)", database.FullTextSearch)
+	log.Printf("   GET  /api/compare?tickers=A,B - Side-by-side comparison")
+	log.Printf("   GET  /api/diff?from=&to=      - Diff between scrape runs")
+	log.Printf("   GET  /api/fx-rates?currency=&from=&to= - Exchange rates to RUB")
+	log.Printf("   GET  /api/benchmarks          - Benchmark indexes")
+	log.Printf("   GET  /api/benchmarks/{code}/league?from=&to= - Tracking league table")
+	log.Printf("   GET  /api/management-companies       - Management company aggregates")
+	log.Printf("   GET  /api/management-companies/ranking?by=nav|funds|ter - Company ranking")
+	log.Printf("   GET  /api/management-companies/{name} - Company funds and share history")
+	log.Printf("   POST /api/portfolio/backtest  - Model portfolio backtest")
+	log.Printf("   GET  /api/tools/fee-impact?tickers=&amount=&years=&return= - Fee impact")
+	log.Printf("   GET  /api/analytics/flows?from=&to= - Estimated fund flows")
+	log.Printf("   GET  /api/analytics/risk?tickers=&riskFree= - Volatility, drawdown, Sharpe")
+	log.Printf("   GET  /api/analytics/correlation?tickers=A,B - Return correlation matrix")
+	log.Printf("   GET  /api/feed.atom, /api/feed.rss - Fund events feed")
+	log.Printf("   GET  /api/export/etfs?format=csv|xlsx          - Export ETFs")
+	log.Printf("   GET  /api/export/etfs/{ticker}/history         - Export ticker history")
+	log.Printf("   GET  /api/openapi.json        - OpenAPI 3 document")
+	log.Printf("   GET  /api/docs                - API documentation viewer")
 	log.Println()
 	log.Printf("🔒 Admin API: https://localhost:%s (mTLS)", s.config.AdminPort)
 	log.Printf("   POST /admin/scrape            - Start scraping")
 	log.Printf("   GET  /admin/status            - System status")
 	log.Printf("   GET  /admin/info              - Certificate info")
+	log.Printf("   GET  /admin/runs              - Scrape runs")
+	log.Printf("   GET  /admin/runs/{id}/errors  - Parse error report")
+	log.Printf("   POST /admin/dump?incremental=true - Parquet/NDJSON dump (background)")
+	log.Printf("   GET|POST /admin/webhooks      - Webhook subscriptions")
+	log.Printf("   DELETE /admin/webhooks/{id}   - Delete webhook")
+	log.Printf("   GET  /admin/webhooks/{id}/deliveries - Delivery log")
+	log.Printf("   POST /admin/webhooks/{id}/test       - Send test ping")
+	log.Printf("   GET|POST /admin/watchlists    - Watchlists")
+	log.Printf("   GET|DELETE /admin/watchlists/{id}    - Watchlist by ID")
+	log.Printf("   PUT  /admin/watchlists/{id}/tickers  - Replace tickers")
+	log.Printf("   POST /admin/watchlists/{id}/rules    - Add alert rule")
+	log.Printf("   DELETE /admin/watchlists/{id}/rules/{ruleId} - Delete rule")
+	log.Printf("   GET  /admin/alerts            - Fired alerts")
+	log.Printf("   GET|PUT /admin/index-aliases  - Index name normalization table")
+	log.Printf("   DELETE /admin/index-aliases/{alias} - Delete index alias")
+	log.Printf("   PUT|DELETE /admin/benchmarks/{code}  - Create, rename or delete benchmark")
+	log.Printf("   POST /admin/benchmarks/{code}/levels - Import index levels (CSV or JSON)")
+	log.Printf("   PUT|DELETE /admin/funds/{ticker}/benchmark - Link fund to benchmark")
+	log.Printf("   POST /admin/fx-rates          - Import exchange rates (CBR XML or CSV)")
 	log.Println()
 	log.Printf("📝 Allowed admin DNs:")
 	if len(s.config.AdminAllowedDNs) == 0 {
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"etf-scraper/internal/models"
+	"etf-scraper/pkg/feeimpact"
+)
+
+// maxToolTickers ограничивает число тикеров в одном запросе к инструментам
+const maxToolTickers = 50
+
+// HandleFeeImpact рассчитывает итоговую стоимость вложения и удержанные комиссии
+// для каждого фонда по его текущему TER (amount, years, return, tickers)
+func (h *Handlers) HandleFeeImpact(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	p := feeimpact.Params{Amount: 100000, Years: 10, GrossReturn: 10}
+	var err error
+	if v := params.Get("amount"); v != "" {
+		if p.Amount, err = strconv.ParseFloat(v, 64); err != nil {
+			http.Error(w, "invalid amount", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := params.Get("years"); v != "" {
+		if p.Years, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "invalid years", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := params.Get("return"); v != "" {
+		if p.GrossReturn, err = strconv.ParseFloat(v, 64); err != nil {
+			http.Error(w, "invalid return", http.StatusBadRequest)
+			return
+		}
+	}
+	if err := p.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tickers := normalizeTickers(strings.Split(params.Get("tickers"), ","))
+	if len(tickers) == 0 {
+		http.Error(w, "tickers required", http.StatusBadRequest)
+		return
+	}
+	if len(tickers) > maxToolTickers {
+		http.Error(w, "too many tickers (max "+strconv.Itoa(maxToolTickers)+")", http.StatusBadRequest)
+		return
+	}
+
+	response := models.FeeImpactResponse{
+		Amount:      p.Amount,
+		Years:       p.Years,
+		GrossReturn: p.GrossReturn,
+		Funds:       []models.FeeImpactFund{},
+		Missing:     []models.MissingTicker{},
+	}
+
+	var funds []feeimpact.Fund
+	names := make(map[string]string)
+	seen := make(map[string]bool)
+	for _, ticker := range tickers {
+		if seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+
+		data, err := h.repo.GetLatestETFs(ticker)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		switch {
+		case len(data) == 0:
+			response.Missing = append(response.Missing, models.MissingTicker{Ticker: ticker, Reason: "not found"})
+		case data[0].TERPercent == nil:
+			response.Missing = append(response.Missing, models.MissingTicker{Ticker: ticker, Reason: "TER unknown"})
+		default:
+			names[ticker] = data[0].FundName
+			funds = append(funds, feeimpact.Fund{Ticker: ticker, TERPercent: *data[0].TERPercent})
+		}
+	}
+
+	for _, f := range feeimpact.Compare(p, funds) {
+		response.GrossTerminalValue = f.GrossTerminalValue
+		response.Funds = append(response.Funds, models.FeeImpactFund{
+			Rank:           f.Rank,
+			Ticker:         f.Ticker,
+			FundName:       names[f.Ticker],
+			TERPercent:     f.TERPercent,
+			TerminalValue:  f.TerminalValue,
+			FeesPaid:       f.FeesPaid,
+			FeeDrag:        f.FeeDrag,
+			FeeDragPercent: f.FeeDragPercent,
+			BehindBest:     f.BehindBest,
+		})
+	}
+	if len(funds) == 0 {
+		response.GrossTerminalValue = feeimpact.Project(p, 0).GrossTerminalValue
+	}
+
+	respondJSON(w, response)
+}
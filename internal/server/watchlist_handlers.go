@@ -0,0 +1,305 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"etf-scraper/internal/alerts"
+	"etf-scraper/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// HandleAdminListWatchlists возвращает списки наблюдения с тикерами и правилами
+func (h *Handlers) HandleAdminListWatchlists(w http.ResponseWriter, r *http.Request) {
+	watchlists, err := h.repo.GetWatchlists()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := []models.WatchlistResponse{}
+	for _, wl := range watchlists {
+		response = append(response, toWatchlistResponse(wl))
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleAdminCreateWatchlist создает список наблюдения
+func (h *Handlers) HandleAdminCreateWatchlist(w http.ResponseWriter, r *http.Request) {
+	var req models.WatchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.repo.GetWatchlists()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, wl := range existing {
+		if wl.Name == req.Name {
+			http.Error(w, "Watchlist already exists", http.StatusConflict)
+			return
+		}
+	}
+
+	id, err := h.repo.CreateWatchlist(req.Name, normalizeTickers(req.Tickers))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	created, err := h.repo.GetWatchlist(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("👀 Watchlist #%d created: %s (%d tickers)", created.ID, created.Name, len(created.Tickers))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toWatchlistResponse(*created))
+}
+
+// HandleAdminGetWatchlist возвращает список наблюдения
+func (h *Handlers) HandleAdminGetWatchlist(w http.ResponseWriter, r *http.Request) {
+	wl, ok := h.watchlistFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, toWatchlistResponse(*wl))
+}
+
+// HandleAdminDeleteWatchlist удаляет список вместе с правилами и оповещениями
+func (h *Handlers) HandleAdminDeleteWatchlist(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid watchlist id", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.repo.DeleteWatchlist(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Watchlist not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("🗑️ Watchlist #%d deleted", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleAdminSetWatchlistTickers заменяет тикеры списка
+func (h *Handlers) HandleAdminSetWatchlistTickers(w http.ResponseWriter, r *http.Request) {
+	wl, ok := h.watchlistFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.WatchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.SetWatchlistTickers(wl.ID, normalizeTickers(req.Tickers)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := h.repo.GetWatchlist(wl.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, toWatchlistResponse(*updated))
+}
+
+// HandleAdminCreateAlertRule добавляет правило оповещения в список
+func (h *Handlers) HandleAdminCreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	wl, ok := h.watchlistFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AlertRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !alerts.ValidKind(req.Kind) {
+		http.Error(w, "kind must be one of: "+strings.Join(alerts.Kinds, ", "), http.StatusBadRequest)
+		return
+	}
+	if alerts.NeedsThreshold(req.Kind) {
+		if req.Threshold == nil || *req.Threshold < 0 {
+			http.Error(w, "threshold is required and must be non-negative", http.StatusBadRequest)
+			return
+		}
+	} else {
+		req.Threshold = nil
+	}
+
+	if req.Notifier == "" {
+		req.Notifier = "log"
+	}
+	engine := alerts.NewEngine(h.repo, h.config)
+	if _, ok := engine.Notifier(req.Notifier); !ok {
+		http.Error(w, "notifier must be one of: "+strings.Join(engine.NotifierNames(), ", "), http.StatusBadRequest)
+		return
+	}
+
+	req.Target = strings.TrimSpace(req.Target)
+	switch req.Notifier {
+	case "webhook":
+		target, err := url.Parse(req.Target)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			http.Error(w, "target must be an absolute http(s) URL", http.StatusBadRequest)
+			return
+		}
+	case "email":
+		if req.Target == "" {
+			http.Error(w, "target must list recipient addresses", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rule := models.AlertRule{
+		WatchlistID: wl.ID,
+		Kind:        req.Kind,
+		Threshold:   req.Threshold,
+		Notifier:    req.Notifier,
+		Target:      req.Target,
+	}
+	id, err := h.repo.CreateAlertRule(rule)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := h.repo.GetWatchlist(wl.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("🔔 Alert rule #%d added to watchlist #%d: %s via %s", id, wl.ID, rule.Kind, rule.Notifier)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toWatchlistResponse(*updated))
+}
+
+// HandleAdminDeleteAlertRule удаляет правило из списка
+func (h *Handlers) HandleAdminDeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid watchlist id", http.StatusBadRequest)
+		return
+	}
+	ruleID, err := strconv.ParseInt(vars["ruleId"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid rule id", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.repo.DeleteAlertRule(id, ruleID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Rule not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("🗑️ Alert rule #%d deleted from watchlist #%d", ruleID, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleAdminAlerts возвращает последние сработавшие оповещения
+func (h *Handlers) HandleAdminAlerts(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	list, err := h.repo.GetAlerts(limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := []models.AlertResponse{}
+	for _, a := range list {
+		response = append(response, models.NewAlertResponse(a))
+	}
+
+	respondJSON(w, response)
+}
+
+// watchlistFromRequest загружает список по {id}; при ошибке ответ уже отправлен
+func (h *Handlers) watchlistFromRequest(w http.ResponseWriter, r *http.Request) (*models.Watchlist, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid watchlist id", http.StatusBadRequest)
+		return nil, false
+	}
+
+	wl, err := h.repo.GetWatchlist(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if wl == nil {
+		http.Error(w, "Watchlist not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return wl, true
+}
+
+func toWatchlistResponse(wl models.Watchlist) models.WatchlistResponse {
+	response := models.WatchlistResponse{
+		ID:        wl.ID,
+		Name:      wl.Name,
+		Tickers:   wl.Tickers,
+		Rules:     []models.AlertRuleResponse{},
+		CreatedAt: wl.CreatedAt,
+	}
+	if response.Tickers == nil {
+		response.Tickers = []string{}
+	}
+	for _, rule := range wl.Rules {
+		response.Rules = append(response.Rules, models.AlertRuleResponse{
+			ID:        rule.ID,
+			Kind:      rule.Kind,
+			Threshold: rule.Threshold,
+			Notifier:  rule.Notifier,
+			Target:    rule.Target,
+			CreatedAt: rule.CreatedAt,
+		})
+	}
+	return response
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"etf-scraper/internal/models"
+	"etf-scraper/internal/webhook"
+
+	"github.com/gorilla/mux"
+)
+
+// HandleAdminListWebhooks возвращает подписки на вебхуки (без секретов)
+func (h *Handlers) HandleAdminListWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.repo.GetWebhooks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := []models.WebhookResponse{}
+	for _, wh := range webhooks {
+		response = append(response, toWebhookResponse(wh))
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleAdminCreateWebhook создает подписку; если секрет не задан, он генерируется
+// и возвращается один раз в ответе
+func (h *Handlers) HandleAdminCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		http.Error(w, "url must be an absolute http(s) URL", http.StatusBadRequest)
+		return
+	}
+	for _, e := range req.Events {
+		if !webhook.ValidEventType(e) {
+			http.Error(w, "unknown event type: "+e, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.Secret == "" {
+		if req.Secret, err = webhook.GenerateSecret(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	wh := models.Webhook{
+		URL:        req.URL,
+		EventTypes: req.Events,
+		Tickers:    normalizeTickers(req.Tickers),
+		Secret:     req.Secret,
+		Active:     true,
+	}
+	if wh.ID, err = h.repo.CreateWebhook(wh); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	created, err := h.repo.GetWebhook(wh.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("🔔 Webhook #%d created: %s", created.ID, created.URL)
+
+	response := toWebhookResponse(*created)
+	response.Secret = created.Secret
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleAdminDeleteWebhook удаляет подписку вместе с журналом доставок
+func (h *Handlers) HandleAdminDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid webhook id", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.repo.DeleteWebhook(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("🗑️ Webhook #%d deleted", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleAdminWebhookDeliveries возвращает журнал доставок подписки
+func (h *Handlers) HandleAdminWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	wh, ok := h.webhookFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	deliveries, err := h.repo.GetWebhookDeliveries(wh.ID, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := []models.WebhookDeliveryResponse{}
+	for _, d := range deliveries {
+		response = append(response, toWebhookDeliveryResponse(d))
+	}
+
+	respondJSON(w, response)
+}
+
+// HandleAdminTestWebhook синхронно отправляет подписке тестовое уведомление
+// (одна попытка, таймаут webhook.TestTimeout)
+func (h *Handlers) HandleAdminTestWebhook(w http.ResponseWriter, r *http.Request) {
+	wh, ok := h.webhookFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	delivery := webhook.NewDispatcher(h.repo).Test(*wh)
+	log.Printf("🔔 Webhook #%d test delivery: success=%t status=%d", wh.ID, delivery.Success, delivery.StatusCode)
+
+	respondJSON(w, toWebhookDeliveryResponse(delivery))
+}
+
+// webhookFromRequest загружает подписку по {id}; при ошибке ответ уже отправлен
+func (h *Handlers) webhookFromRequest(w http.ResponseWriter, r *http.Request) (*models.Webhook, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid webhook id", http.StatusBadRequest)
+		return nil, false
+	}
+
+	wh, err := h.repo.GetWebhook(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if wh == nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return wh, true
+}
+
+// normalizeTickers приводит тикеры к верхнему регистру и убирает пустые
+func normalizeTickers(tickers []string) []string {
+	var result []string
+	for _, t := range tickers {
+		if t = strings.ToUpper(strings.TrimSpace(t)); t != "" {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func toWebhookResponse(wh models.Webhook) models.WebhookResponse {
+	response := models.WebhookResponse{
+		ID:        wh.ID,
+		URL:       wh.URL,
+		Events:    wh.EventTypes,
+		Tickers:   wh.Tickers,
+		Active:    wh.Active,
+		CreatedAt: wh.CreatedAt,
+	}
+	if response.Events == nil {
+		response.Events = []string{}
+	}
+	if response.Tickers == nil {
+		response.Tickers = []string{}
+	}
+	return response
+}
+
+func toWebhookDeliveryResponse(d models.WebhookDelivery) models.WebhookDeliveryResponse {
+	return models.WebhookDeliveryResponse{
+		ID:         d.ID,
+		DeliveryID: d.DeliveryID,
+		EventType:  d.EventType,
+		Attempts:   d.Attempts,
+		StatusCode: d.StatusCode,
+		Success:    d.Success,
+		Error:      d.Error,
+		DurationMs: d.DurationMs,
+		CreatedAt:  d.CreatedAt,
+		Payload:    d.Payload,
+	}
+}
@@ -0,0 +1,357 @@
+// Package webhook доставляет уведомления о событиях фондов и сбоях скрейпинга
+// подписчикам: JSON с подписью HMAC-SHA256, повторы с экспоненциальной задержкой
+// и журнал доставок в БД
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+// Заголовки запроса с уведомлением
+const (
+	EventHeader     = "X-ETF-Event"
+	DeliveryHeader  = "X-ETF-Delivery"
+	SignatureHeader = "X-ETF-Signature"
+)
+
+// AllEvents подписывает на все типы уведомлений
+const AllEvents = "*"
+
+// EventTypes перечисляет типы уведомлений, на которые можно подписаться
+var EventTypes = []string{
+	models.EventListed,
+	models.EventDelisted,
+	models.EventSuspended,
+	models.EventResumed,
+	models.EventTERChanged,
+	models.EventRenamed,
+	models.EventNAVThreshold,
+	models.WebhookEventScrapeFailed,
+}
+
+// Payload — тело уведомления
+type Payload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt string      `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// Dispatcher рассылает уведомления активным подпискам
+type Dispatcher struct {
+	repo   *database.Repository
+	client *http.Client
+
+	// pending учитывает рассылки, идущие в фоне (см. Wait)
+	pending sync.WaitGroup
+
+	// MaxAttempts — число попыток доставки одного уведомления
+	MaxAttempts int
+	// Backoff — задержка перед второй попыткой; дальше удваивается
+	Backoff time.Duration
+}
+
+// NewDispatcher создает рассыльщик с настройками по умолчанию
+func NewDispatcher(repo *database.Repository) *Dispatcher {
+	return &Dispatcher{
+		repo:        repo,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts: 3,
+		Backoff:     time.Second,
+	}
+}
+
+// Sign возвращает подпись тела запроса в формате "sha256=<hex>"
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись тела запроса; пригодится получателям на Go
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// GenerateSecret создает случайный секрет для подписки
+func GenerateSecret() (string, error) {
+	return randomHex(32)
+}
+
+// ValidEventType проверяет тип уведомления для подписки
+func ValidEventType(eventType string) bool {
+	if eventType == AllEvents {
+		return true
+	}
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches проверяет, подходит ли уведомление под подписку. Пустые списки типов
+// и тикеров означают «все»; фильтр по тикерам не действует на уведомления без тикера.
+func Matches(wh models.Webhook, eventType, ticker string) bool {
+	if !wh.Active {
+		return false
+	}
+
+	typeOK := len(wh.EventTypes) == 0
+	for _, t := range wh.EventTypes {
+		if t == AllEvents || t == eventType {
+			typeOK = true
+			break
+		}
+	}
+	if !typeOK {
+		return false
+	}
+
+	if ticker == "" || len(wh.Tickers) == 0 {
+		return true
+	}
+	for _, t := range wh.Tickers {
+		if strings.EqualFold(t, ticker) {
+			return true
+		}
+	}
+	return false
+}
+
+// notification — уведомление, ожидающее доставки
+type notification struct {
+	eventType string
+	ticker    string
+	data      interface{}
+}
+
+// NotifyEvents рассылает события фондов в фоне: медленный получатель
+// не задерживает скрейпинг. Дождаться окончания рассылки можно через Wait.
+func (d *Dispatcher) NotifyEvents(events []models.Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	notifications := make([]notification, len(events))
+	for i, e := range events {
+		notifications[i] = notification{e.Kind, e.Ticker, models.NewEventResponse(e)}
+	}
+	d.background(notifications)
+}
+
+// NotifyScrapeFailure рассылает в фоне уведомление о неудачном запуске скрейпера
+func (d *Dispatcher) NotifyScrapeFailure(run models.ScrapeRun) {
+	d.background([]notification{{models.WebhookEventScrapeFailed, "", models.NewScrapeRunResponse(run)}})
+}
+
+// Wait ждет завершения фоновых рассылок; нужен перед выходом из процесса
+func (d *Dispatcher) Wait() {
+	d.pending.Wait()
+}
+
+// background запускает рассылку в отдельной горутине
+func (d *Dispatcher) background(notifications []notification) {
+	d.pending.Add(1)
+	go func() {
+		defer d.pending.Done()
+		d.dispatch(notifications)
+	}()
+}
+
+// TestTimeout ограничивает тестовую доставку: администратор ждет ее результат в ответе,
+// который должен уложиться в таймаут записи админского сервера
+const TestTimeout = 5 * time.Second
+
+// Test отправляет подписке тестовое уведомление ping, не проверяя фильтры.
+// Делается одна попытка с таймаутом TestTimeout, без повторов.
+func (d *Dispatcher) Test(wh models.Webhook) models.WebhookDelivery {
+	single := &Dispatcher{
+		repo:        d.repo,
+		client:      &http.Client{Timeout: TestTimeout},
+		MaxAttempts: 1,
+	}
+	return single.deliver(wh, notification{
+		eventType: models.WebhookEventPing,
+		data: map[string]string{
+			"message": "Тестовое уведомление ETF Scraper",
+		},
+	})
+}
+
+// dispatch доставляет уведомления подходящим подпискам: подписки обслуживаются
+// параллельно, уведомления одной подписки — по порядку. После неудачной доставки
+// остальные уведомления подписки в этой рассылке не отправляются, а только журналируются.
+func (d *Dispatcher) dispatch(notifications []notification) {
+	webhooks, err := d.repo.GetWebhooks()
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Не удалось загрузить вебхуки: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, wh := range webhooks {
+		var matched []notification
+		for _, n := range notifications {
+			if Matches(wh, n.eventType, n.ticker) {
+				matched = append(matched, n)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(wh models.Webhook, matched []notification) {
+			defer wg.Done()
+
+			failed := false
+			for _, n := range matched {
+				if failed {
+					d.skip(wh, n)
+					continue
+				}
+				if delivery := d.deliver(wh, n); !delivery.Success {
+					failed = true
+				}
+			}
+		}(wh, matched)
+	}
+	wg.Wait()
+}
+
+// deliver отправляет уведомление с повторами и записывает результат в журнал
+func (d *Dispatcher) deliver(wh models.Webhook, n notification) models.WebhookDelivery {
+	delivery, body := d.newDelivery(wh, n)
+	if delivery.Error != "" {
+		d.save(&delivery)
+		return delivery
+	}
+
+	start := time.Now()
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(d.Backoff << (attempt - 2))
+		}
+		delivery.Attempts = attempt
+
+		status, err := d.post(wh, delivery, body)
+		delivery.StatusCode = status
+		if err == nil {
+			delivery.Success = true
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = err.Error()
+
+		// Ошибки клиента (кроме 408 и 429) повторять бессмысленно
+		if status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests {
+			break
+		}
+	}
+	delivery.DurationMs = time.Since(start).Milliseconds()
+
+	if !delivery.Success {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Вебхук #%d (%s) не доставлен: %s", wh.ID, n.eventType, delivery.Error)
+	}
+	d.save(&delivery)
+	return delivery
+}
+
+// skip журналирует уведомление, пропущенное из-за недоступности получателя
+func (d *Dispatcher) skip(wh models.Webhook, n notification) {
+	delivery, _ := d.newDelivery(wh, n)
+	if delivery.Error == "" {
+		delivery.Error = "пропущено: предыдущая доставка не удалась"
+	}
+	d.save(&delivery)
+}
+
+// newDelivery формирует тело уведомления и запись журнала
+func (d *Dispatcher) newDelivery(wh models.Webhook, n notification) (models.WebhookDelivery, []byte) {
+	delivery := models.WebhookDelivery{
+		WebhookID: wh.ID,
+		EventType: n.eventType,
+		CreatedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	id, err := randomHex(16)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery, nil
+	}
+	delivery.DeliveryID = id
+
+	body, err := json.Marshal(Payload{
+		ID:        id,
+		Type:      n.eventType,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Data:      n.data,
+	})
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery, nil
+	}
+	delivery.Payload = string(body)
+
+	return delivery, body
+}
+
+// post выполняет одну попытку доставки
+func (d *Dispatcher) post(wh models.Webhook, delivery models.WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "etf-scraper-webhook/1.0")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.DeliveryID)
+	req.Header.Set(SignatureHeader, Sign(wh.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("получатель ответил %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// save записывает доставку в журнал и заполняет ее ID
+func (d *Dispatcher) save(delivery *models.WebhookDelivery) {
+	id, err := d.repo.SaveWebhookDelivery(*delivery)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Не удалось сохранить доставку вебхука: %v", err)
+		return
+	}
+	delivery.ID = id
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,255 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"etf-scraper/internal/database"
+	"etf-scraper/internal/models"
+)
+
+const testSecret = "s3cret"
+
+// testEnv — рассыльщик на временной БД с одной подпиской на receiver
+type testEnv struct {
+	repo       *database.Repository
+	dispatcher *Dispatcher
+	webhookID  int64
+}
+
+func newTestEnv(t *testing.T, receiver http.HandlerFunc) *testEnv {
+	t.Helper()
+
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	db, err := database.NewDatabase(t.TempDir() + "/webhook.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := database.NewRepository(db)
+
+	id, err := repo.CreateWebhook(models.Webhook{URL: server.URL, Secret: testSecret, Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDispatcher(repo)
+	d.Backoff = time.Millisecond
+	return &testEnv{repo: repo, dispatcher: d, webhookID: id}
+}
+
+// deliveries возвращает журнал доставок в порядке записи
+func (e *testEnv) deliveries(t *testing.T) []models.WebhookDelivery {
+	t.Helper()
+	list, err := e.repo.GetWebhookDeliveries(e.webhookID, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+	return list
+}
+
+func testEvents(tickers ...string) []models.Event {
+	var events []models.Event
+	for i, ticker := range tickers {
+		events = append(events, models.Event{
+			ID:          int64(i + 1),
+			Kind:        models.EventTERChanged,
+			Ticker:      ticker,
+			DateScraped: "2024-05-01 00:00:00",
+			Title:       ticker + ": изменился TER",
+		})
+	}
+	return events
+}
+
+func TestSignatureVerifies(t *testing.T) {
+	var mu sync.Mutex
+	var body []byte
+	var header http.Header
+	env := newTestEnv(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+	})
+
+	env.dispatcher.NotifyEvents(testEvents("TMOS"))
+	env.dispatcher.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	signature := header.Get(SignatureHeader)
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Fatalf("%s = %q", SignatureHeader, signature)
+	}
+	if !Verify(testSecret, body, signature) {
+		t.Error("signature does not verify with the subscription secret")
+	}
+	if Verify("other", body, signature) {
+		t.Error("signature verifies with a wrong secret")
+	}
+	if header.Get(EventHeader) != models.EventTERChanged || header.Get(DeliveryHeader) == "" {
+		t.Errorf("headers = %v", header)
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Type != models.EventTERChanged || payload.ID != header.Get(DeliveryHeader) {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	list := env.deliveries(t)
+	if len(list) != 1 || !list[0].Success || list[0].Attempts != 1 || list[0].StatusCode != http.StatusOK {
+		t.Errorf("deliveries = %+v", list)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		status   int
+		attempts int
+	}{
+		{http.StatusInternalServerError, 3},
+		{http.StatusBadGateway, 3},
+		{http.StatusRequestTimeout, 3},
+		{http.StatusTooManyRequests, 3},
+		{http.StatusBadRequest, 1},
+		{http.StatusNotFound, 1},
+		{http.StatusUnauthorized, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			var requests atomic.Int32
+			env := newTestEnv(t, func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+			})
+
+			env.dispatcher.NotifyEvents(testEvents("TMOS"))
+			env.dispatcher.Wait()
+
+			if got := int(requests.Load()); got != tt.attempts {
+				t.Errorf("requests = %d, want %d", got, tt.attempts)
+			}
+			list := env.deliveries(t)
+			if len(list) != 1 {
+				t.Fatalf("deliveries = %d, want 1", len(list))
+			}
+			d := list[0]
+			if d.Success || d.Attempts != tt.attempts || d.StatusCode != tt.status || d.Error == "" {
+				t.Errorf("delivery = %+v", d)
+			}
+		})
+	}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	var requests atomic.Int32
+	env := newTestEnv(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	env.dispatcher.NotifyEvents(testEvents("TMOS"))
+	env.dispatcher.Wait()
+
+	list := env.deliveries(t)
+	if len(list) != 1 || !list[0].Success || list[0].Attempts != 3 || list[0].Error != "" {
+		t.Errorf("deliveries = %+v", list)
+	}
+}
+
+func TestSkipAfterFailure(t *testing.T) {
+	var mu sync.Mutex
+	var tickers []string
+	env := newTestEnv(t, func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Data models.EventResponse `json:"data"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		tickers = append(tickers, payload.Data.Ticker)
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	env.dispatcher.NotifyEvents(testEvents("TMOS", "SBMX", "AKMB"))
+	env.dispatcher.Wait()
+
+	mu.Lock()
+	if strings.Join(tickers, ",") != "TMOS,TMOS,TMOS" {
+		t.Errorf("POSTed tickers = %v, want only TMOS retried", tickers)
+	}
+	mu.Unlock()
+
+	list := env.deliveries(t)
+	if len(list) != 3 {
+		t.Fatalf("deliveries = %d, want 3", len(list))
+	}
+	if list[0].Attempts != 3 || list[0].StatusCode != http.StatusInternalServerError {
+		t.Errorf("failed delivery = %+v", list[0])
+	}
+	for _, d := range list[1:] {
+		if d.Success || d.Attempts != 0 || !strings.HasPrefix(d.Error, "пропущено") || d.Payload == "" {
+			t.Errorf("skipped delivery = %+v", d)
+		}
+	}
+}
+
+func TestNotifyEventsDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	env := newTestEnv(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+
+	start := time.Now()
+	env.dispatcher.NotifyEvents(testEvents("TMOS"))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("NotifyEvents blocked for %s", elapsed)
+	}
+
+	close(release)
+	env.dispatcher.Wait()
+	if list := env.deliveries(t); len(list) != 1 || !list[0].Success {
+		t.Errorf("deliveries after Wait = %+v", list)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	wh := models.Webhook{Active: true, EventTypes: []string{models.EventListed}, Tickers: []string{"tmos"}}
+	tests := []struct {
+		eventType, ticker string
+		want              bool
+	}{
+		{models.EventListed, "TMOS", true},
+		{models.EventListed, "SBMX", false},
+		{models.EventDelisted, "TMOS", false},
+		{models.EventListed, "", true},
+	}
+	for _, tt := range tests {
+		if got := Matches(wh, tt.eventType, tt.ticker); got != tt.want {
+			t.Errorf("Matches(%s, %q) = %v, want %v", tt.eventType, tt.ticker, got, tt.want)
+		}
+	}
+
+	wh.Active = false
+	if Matches(wh, models.EventListed, "TMOS") {
+		t.Error("inactive webhook matches")
+	}
+}
@@ -0,0 +1,119 @@
+// Package feeimpact считает, во что обходятся комиссии фонда (TER) на горизонте
+// инвестирования: итоговую стоимость вложения, сумму удержанных комиссий и
+// недополученный из-за них доход
+package feeimpact
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MaxYears — наибольший горизонт расчета в годах
+const MaxYears = 100
+
+// Params — параметры расчета
+type Params struct {
+	// Amount — сумма первоначального вложения
+	Amount float64
+	// Years — горизонт инвестирования в годах
+	Years int
+	// GrossReturn — предполагаемая доходность до комиссий, % годовых
+	GrossReturn float64
+}
+
+// Validate проверяет параметры расчета
+func (p Params) Validate() error {
+	switch {
+	case p.Amount <= 0 || math.IsInf(p.Amount, 0) || math.IsNaN(p.Amount):
+		return errors.New("сумма должна быть положительным числом")
+	case p.Years < 1 || p.Years > MaxYears:
+		return fmt.Errorf("горизонт должен быть от 1 до %d лет", MaxYears)
+	case p.GrossReturn <= -100 || math.IsNaN(p.GrossReturn) || math.IsInf(p.GrossReturn, 0):
+		return errors.New("доходность должна быть больше -100%")
+	}
+	return nil
+}
+
+// Projection — результат расчета для одного значения TER
+type Projection struct {
+	// TerminalValue — стоимость вложения в конце горизонта после комиссий
+	TerminalValue float64
+	// GrossTerminalValue — стоимость без комиссий
+	GrossTerminalValue float64
+	// FeesPaid — сумма комиссий, удержанных за все годы
+	FeesPaid float64
+	// FeeDrag — потеря итоговой стоимости из-за комиссий (включая упущенный доход на комиссиях)
+	FeeDrag float64
+	// FeeDragPercent — FeeDrag в процентах от GrossTerminalValue
+	FeeDragPercent float64
+}
+
+// Project рассчитывает вложение при ежегодной капитализации: за год стоимость
+// растет на GrossReturn, затем удерживается TER от стоимости на конец года
+func Project(p Params, terPercent float64) Projection {
+	growth := 1 + p.GrossReturn/100
+	fee := terPercent / 100
+
+	value, fees := p.Amount, 0.0
+	for year := 0; year < p.Years; year++ {
+		value *= growth
+		charged := value * fee
+		fees += charged
+		value -= charged
+	}
+
+	gross := p.Amount * math.Pow(growth, float64(p.Years))
+	pr := Projection{
+		TerminalValue:      round(value),
+		GrossTerminalValue: round(gross),
+		FeesPaid:           round(fees),
+		FeeDrag:            round(gross - value),
+	}
+	if gross > 0 {
+		pr.FeeDragPercent = round((gross - value) / gross * 100)
+	}
+	return pr
+}
+
+// Fund — фонд для сравнения
+type Fund struct {
+	Ticker     string
+	TERPercent float64
+}
+
+// Ranked — результат расчета для фонда с местом в сравнении
+type Ranked struct {
+	Fund
+	Projection
+	Rank int
+	// BehindBest — отставание итоговой стоимости от лучшего фонда
+	BehindBest float64
+}
+
+// Compare рассчитывает фонды с одинаковыми параметрами и ранжирует их
+// по итоговой стоимости (при равенстве — по тикеру)
+func Compare(p Params, funds []Fund) []Ranked {
+	result := make([]Ranked, 0, len(funds))
+	for _, f := range funds {
+		result = append(result, Ranked{Fund: f, Projection: Project(p, f.TERPercent)})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].TerminalValue != result[j].TerminalValue {
+			return result[i].TerminalValue > result[j].TerminalValue
+		}
+		return result[i].Ticker < result[j].Ticker
+	})
+	for i := range result {
+		result[i].Rank = i + 1
+		result[i].BehindBest = round(result[0].TerminalValue - result[i].TerminalValue)
+	}
+	return result
+}
+
+// round округляет денежные суммы до копеек
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
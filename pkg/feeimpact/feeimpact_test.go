@@ -0,0 +1,124 @@
+package feeimpact
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProjectHandChecked(t *testing.T) {
+	// 100 000 под 10% на 2 года, TER 1%:
+	// год 1: 100 000 × 1,1 = 110 000, комиссия 1 100 → 108 900
+	// год 2: 108 900 × 1,1 = 119 790, комиссия 1 197,90 → 118 592,10
+	// без комиссий: 100 000 × 1,1² = 121 000; потеря 2 407,90 = 1,99%
+	got := Project(Params{Amount: 100000, Years: 2, GrossReturn: 10}, 1)
+	want := Projection{
+		TerminalValue:      118592.10,
+		GrossTerminalValue: 121000,
+		FeesPaid:           2297.90,
+		FeeDrag:            2407.90,
+		FeeDragPercent:     1.99,
+	}
+	if got != want {
+		t.Errorf("Project() = %+v, want %+v", got, want)
+	}
+}
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+		ter    float64
+		want   Projection
+	}{
+		{"zero TER", Params{Amount: 1000, Years: 3, GrossReturn: 10}, 0,
+			Projection{TerminalValue: 1331, GrossTerminalValue: 1331}},
+		{"zero return", Params{Amount: 1000, Years: 2, GrossReturn: 0}, 1,
+			// 1000 − 10 = 990, 990 − 9,90 = 980,10
+			Projection{TerminalValue: 980.10, GrossTerminalValue: 1000, FeesPaid: 19.90, FeeDrag: 19.90, FeeDragPercent: 1.99}},
+		{"negative return", Params{Amount: 1000, Years: 1, GrossReturn: -20}, 1,
+			// 800 − 8 = 792
+			Projection{TerminalValue: 792, GrossTerminalValue: 800, FeesPaid: 8, FeeDrag: 8, FeeDragPercent: 1}},
+		{"ten years", Params{Amount: 100000, Years: 10, GrossReturn: 7}, 0.5,
+			Projection{TerminalValue: 187097.76, GrossTerminalValue: 196715.14, FeesPaid: 7207.63, FeeDrag: 9617.38, FeeDragPercent: 4.89}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Project(tt.params, tt.ter)
+			if got != tt.want {
+				t.Errorf("Project() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectFeeDragIncludesLostGrowth(t *testing.T) {
+	// Потеря больше суммы комиссий: удержанные деньги тоже не растут
+	p := Project(Params{Amount: 100000, Years: 20, GrossReturn: 8}, 1)
+	if p.FeeDrag <= p.FeesPaid {
+		t.Errorf("fee drag %v should exceed fees paid %v", p.FeeDrag, p.FeesPaid)
+	}
+	if diff := p.GrossTerminalValue - p.TerminalValue - p.FeeDrag; math.Abs(diff) > 0.011 {
+		t.Errorf("gross − terminal − drag = %v", diff)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	p := Params{Amount: 100000, Years: 10, GrossReturn: 7}
+	got := Compare(p, []Fund{
+		{Ticker: "EXPN", TERPercent: 0.95},
+		{Ticker: "TMOS", TERPercent: 0.5},
+		{Ticker: "CHEP", TERPercent: 0.5},
+	})
+
+	want := []struct {
+		ticker     string
+		rank       int
+		terminal   float64
+		behindBest float64
+	}{
+		{"CHEP", 1, 187097.76, 0},
+		{"TMOS", 2, 187097.76, 0},
+		{"EXPN", 3, 178806.20, 8291.56},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Compare() returned %d funds, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Ticker != w.ticker || g.Rank != w.rank || g.TerminalValue != w.terminal || g.BehindBest != w.behindBest {
+			t.Errorf("rank %d = %s #%d %v behind %v, want %s #%d %v behind %v",
+				i+1, g.Ticker, g.Rank, g.TerminalValue, g.BehindBest, w.ticker, w.rank, w.terminal, w.behindBest)
+		}
+	}
+
+	if len(Compare(p, nil)) != 0 {
+		t.Error("Compare() of no funds should be empty")
+	}
+}
+
+func TestParamsValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+		ok     bool
+	}{
+		{"valid", Params{Amount: 1000, Years: 10, GrossReturn: 7}, true},
+		{"max years", Params{Amount: 1000, Years: MaxYears, GrossReturn: 0}, true},
+		{"negative return", Params{Amount: 1000, Years: 1, GrossReturn: -99.9}, true},
+		{"zero amount", Params{Amount: 0, Years: 10, GrossReturn: 7}, false},
+		{"negative amount", Params{Amount: -1, Years: 10, GrossReturn: 7}, false},
+		{"infinite amount", Params{Amount: math.Inf(1), Years: 10, GrossReturn: 7}, false},
+		{"NaN amount", Params{Amount: math.NaN(), Years: 10, GrossReturn: 7}, false},
+		{"zero years", Params{Amount: 1000, Years: 0, GrossReturn: 7}, false},
+		{"too many years", Params{Amount: 1000, Years: MaxYears + 1, GrossReturn: 7}, false},
+		{"total loss", Params{Amount: 1000, Years: 10, GrossReturn: -100}, false},
+		{"NaN return", Params{Amount: 1000, Years: 10, GrossReturn: math.NaN()}, false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.params.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: Validate() = %v, want ok=%v", tt.name, err, tt.ok)
+		}
+	}
+}